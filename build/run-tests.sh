@@ -6,7 +6,7 @@
 
 while read f
 do
-    cd ${f}; GO111MODULE=on go test ./... ; (( exit_status = exit_status || $? ))
-done < <(find $PWD -maxdepth 1 \( -name .git -o -name .idea -o -name build \) -prune -o ! -path $PWD -type d -print )
+    cd ${f}; GO111MODULE=on go test -tags debug_goroutine_id ./... ; (( exit_status = exit_status || $? ))
+done < <(find $PWD \( -name .git -o -name .idea -o -name build \) -prune -o -name go.mod -printf '%h\n' )
 
 exit ${exit_status}
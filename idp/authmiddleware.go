@@ -11,6 +11,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/d-velop/dvelop-sdk-go/idp/jwtvalidator"
 	"github.com/d-velop/dvelop-sdk-go/idp/scim"
 )
 
@@ -18,6 +19,7 @@ type contextKey string
 
 const principalKey = contextKey("Principal")
 const authSessionIdKey = contextKey("AuthSessionId")
+const authSessionIdCookieName = "AuthSessionId"
 
 // Authenticate authenticates the user using the IdentityProvider-App
 //
@@ -37,6 +39,8 @@ const authSessionIdKey = contextKey("AuthSessionId")
 // distinguish external from internal users.
 // If you are unsure, you should set allowExternalValidation to false, as you usually don't want external users to access your app.
 //
+// The behaviour of the middleware can be customized with options like WithCookieOptions or AllowAnonymous.
+//
 // Example:
 //	func main() {
 //		idpClient,err := idpclient.New()
@@ -60,57 +64,79 @@ const authSessionIdKey = contextKey("AuthSessionId")
 //			fmt.Fprintf(w, "Hello %v your authsessionId is %v", principal.DisplayName, authSessionId)
 //		})
 //	}
-func Authenticate(validator Validator, getSystemBaseUriFromCtx, getTenantIdFromCtx func(ctx context.Context) (string, error), allowExternalValidation bool, logError, logInfo func(ctx context.Context, message string)) func(http.Handler) http.Handler {
+func Authenticate(validator Validator, getSystemBaseUriFromCtx, getTenantIdFromCtx func(ctx context.Context) (string, error), allowExternalValidation bool, logError, logInfo func(ctx context.Context, message string), options ...AuthenticateOption) func(http.Handler) http.Handler {
+	c := newAuthConfig(options)
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 			ctx := req.Context()
 			authSessionId, aErr := authSessionIdFromRequest(ctx, req, logInfo)
 			if aErr != nil {
 				logError(ctx, fmt.Sprintf("error reading authSessionId from request because: %v\n", aErr))
+				c.metrics.RecordAuthInternalError()
 				http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 				return
 			}
 			if authSessionId == "" {
-				if isTextHtmlAccepted(req.Header.Get("Accept")) && req.Method == http.MethodGet || req.Method == http.MethodHead {
-					redirectToIdpLogin(rw, req)
-				} else {
-					rw.WriteHeader(http.StatusUnauthorized)
+				principal, kErr := c.principalFromAPIKey(ctx, req)
+				if kErr != nil {
+					logError(ctx, fmt.Sprintf("error validating API key because: %v\n", kErr))
+					c.metrics.RecordAuthUnauthorized()
 					rw.Header().Set("WWW-Authenticate", "Bearer")
+					rw.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				if principal != nil {
+					if principal.IsExternal() && !allowExternalValidation {
+						logInfo(ctx, fmt.Sprintf("external user tries to access a resource and doesn't have sufficient rights."))
+						c.metrics.RecordExternalUserForbidden()
+						http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+						return
+					}
+					c.metrics.RecordAuthSuccess()
+					next.ServeHTTP(rw, req.WithContext(context.WithValue(ctx, principalKey, *principal)))
+					return
 				}
+				if c.allowAnonymous {
+					c.metrics.RecordAuthAnonymous()
+					next.ServeHTTP(rw, req)
+					return
+				}
+				c.rejectUnauthenticated(rw, req)
 				return
 			}
 			systemBaseUri, gSBErr := getSystemBaseUriFromCtx(ctx)
 			if gSBErr != nil {
 				logError(ctx, fmt.Sprintf("error reading SystemBaseUri from context because: %v\n", gSBErr))
+				c.metrics.RecordAuthInternalError()
 				http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 				return
 			}
 			tenantId, gTErr := getTenantIdFromCtx(ctx)
 			if gTErr != nil {
 				logError(ctx, fmt.Sprintf("error reading TenandId from context because: %v\n", gTErr))
+				c.metrics.RecordAuthInternalError()
 				http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 				return
 			}
 			principal, valErr := validator.Validate(ctx, systemBaseUri, tenantId, authSessionId)
 			if valErr != nil {
 				logError(ctx, fmt.Sprintf("error getting principal from Identityprovider because: %v\n", valErr))
+				c.metrics.RecordIdpError(statusCodeOf(valErr))
 				http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 				return
 			}
 			if principal == nil {
-				if isTextHtmlAccepted(req.Header.Get("Accept")) && req.Method == http.MethodGet || req.Method == http.MethodHead {
-					redirectToIdpLogin(rw, req)
-				} else {
-					rw.WriteHeader(http.StatusUnauthorized)
-					rw.Header().Set("WWW-Authenticate", "Bearer")
-				}
+				c.rejectUnauthenticated(rw, req)
 				return
 			}
 			if principal.IsExternal() && !allowExternalValidation {
 				logInfo(ctx, fmt.Sprintf("external user tries to access a resource and doesn't have sufficient rights."))
+				c.metrics.RecordExternalUserForbidden()
 				http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 				return
 			}
+			c.metrics.RecordAuthSuccess()
+			c.setAuthSessionIdCookie(rw, req, authSessionId)
 			ctx = context.WithValue(ctx, authSessionIdKey, authSessionId)
 			ctx = context.WithValue(ctx, principalKey, *principal)
 			next.ServeHTTP(rw, req.WithContext(ctx))
@@ -118,6 +144,33 @@ func Authenticate(validator Validator, getSystemBaseUriFromCtx, getTenantIdFromC
 	}
 }
 
+// AuthenticateWithJWT authenticates the user like Authenticate but validates the bearer token locally as a JWT
+// instead of calling the IdentityProvider-App for every request.
+//
+// The signature of the token is verified with the JSON Web Key Set available at jwksUri and the token must have been
+// issued for audience by the issuer which the function issuer returns for the tenant. Use jwtvalidator.New together
+// with Authenticate if you need to customize the validator.
+//
+// Example:
+//	func main() {
+//		issuer := func(systemBaseUri string, tenantId string) string {
+//			return "https://acme.d-velop.cloud/identityprovider"
+//		}
+//		authenticate, err := idp.AuthenticateWithJWT("https://acme.d-velop.cloud/identityprovider/.well-known/jwks", "myapp", issuer, tenant.SystemBaseUriFromCtx, tenant.IdFromCtx, false, logError, logInfo)
+//		if err != nil {
+//			// error handling
+//		}
+//		mux := http.NewServeMux()
+//		mux.Handle("/hello", authenticate(helloHandler()))
+//	}
+func AuthenticateWithJWT(jwksUri string, audience string, issuer func(systemBaseUri string, tenantId string) string, getSystemBaseUriFromCtx, getTenantIdFromCtx func(ctx context.Context) (string, error), allowExternalValidation bool, logError, logInfo func(ctx context.Context, message string), options ...AuthenticateOption) (func(http.Handler) http.Handler, error) {
+	validator, err := jwtvalidator.New(jwksUri, audience, issuer)
+	if err != nil {
+		return nil, err
+	}
+	return Authenticate(validator, getSystemBaseUriFromCtx, getTenantIdFromCtx, allowExternalValidation, logError, logInfo, options...), nil
+}
+
 // Validator is an interface representing the ability to validate an authSessionId
 type Validator interface {
 	// Validate checks if the authSessionId is valid for the tenant specified by systemBaseUri and tenantId.
@@ -129,6 +182,18 @@ type Validator interface {
 	Validate(ctx context.Context, systemBaseUri string, tenantId string, authSessionId string) (*scim.Principal, error)
 }
 
+// rejectUnauthenticated redirects browsers to the IdentityProvider-App and rejects all other requests with 401 - Unauthorized
+func (c *authConfig) rejectUnauthenticated(rw http.ResponseWriter, req *http.Request) {
+	if isTextHtmlAccepted(req.Header.Get("Accept")) && req.Method == http.MethodGet || req.Method == http.MethodHead {
+		c.metrics.RecordAuthRedirect()
+		redirectToIdpLogin(rw, req)
+	} else {
+		c.metrics.RecordAuthUnauthorized()
+		rw.Header().Set("WWW-Authenticate", "Bearer")
+		rw.WriteHeader(http.StatusUnauthorized)
+	}
+}
+
 func redirectToIdpLogin(rw http.ResponseWriter, req *http.Request) {
 	const redirectionBase = "/identityprovider/login?redirect="
 	rw.Header().Set("Location", redirectionBase+url.QueryEscape(req.URL.String()))
@@ -171,9 +236,8 @@ func authSessionIdFromRequest(ctx context.Context, req *http.Request, logInfo fu
 	if matches != nil {
 		return matches[1], nil
 	}
-	const authSessionId = "AuthSessionId"
 	for _, cookie := range req.Cookies() {
-		if cookie.Name == authSessionId {
+		if cookie.Name == authSessionIdCookieName {
 			// cookie is URL encoded cf. https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Set-Cookie
 			value, err := url.QueryUnescape(cookie.Value)
 			if err != nil {
@@ -26,14 +26,14 @@ const validAuthSessionId = "aXGxJeb0q+/fS8biFi8FE7TovJPPEPyzlDxT6bh5p5pHA/x7CEi1
 const secondValidAuthSessionId = "bYGxJeb0q+/fS8biFi8FE7TovJPPEPyzlDxT6bh5p5pHA/x7CEi1w9egVhEMz8IWhrtvJRFnkSqJnLr61cOKf/i5eWuu7Duh+OTtTjMOt9w=&Bnh4NNU90wH_OVlgbzbdZOEu1aSuPlbUctiCdYTonZ3Ap_Zd3bVL79I-dPdHf4OOgO8NKEdqyLsqc8RhAOreXgJqXuqsreeI"
 
 var principals = map[string]scim.Principal{
-	validAuthSessionId:       {Id: "9bbbf1b6-017a-449a-ad5f-9723d28223e1"},
-	secondValidAuthSessionId: {Id: "1234f1b6-017a-449a-ad5f-9723d2822fff"},
+	validAuthSessionId:       {Schemas: []string{scim.SchemaUser}, Id: "9bbbf1b6-017a-449a-ad5f-9723d28223e1"},
+	secondValidAuthSessionId: {Schemas: []string{scim.SchemaUser}, Id: "1234f1b6-017a-449a-ad5f-9723d2822fff"},
 }
 
 const validExternalAuthSessionId = "1XGxJeb0q+/fS8biFi8FE7TovJPPEPyzlDxT6bh5p5pHA/x7CEi1w9egVhEMz8IWhrtvJRFnkSqJnLr61cOKf/i5eWuu7Duh+OTtTjMOt9w=&Cnh4NNU90wH_OVlgbzbdZOEu1aSuPlbUctiCdYTonZ3Ap_Zd3bVL79I-dPdHf4OOgO8NKEdqyLsqc8RhAOreXgJqXuqsreeI"
 
 var externalPrincipals = map[string]scim.Principal{
-	validExternalAuthSessionId: {Emails: []scim.UserValue{{"info@d-velop.de"}}, Groups: []scim.UserGroup{{Value: "3E093BE5-CCCE-435D-99F8-544656B98681"}}},
+	validExternalAuthSessionId: {Schemas: []string{scim.SchemaUser}, Emails: []scim.UserValue{{Value: "info@d-velop.de"}}, Groups: []scim.UserGroup{{Value: "3E093BE5-CCCE-435D-99F8-544656B98681"}}},
 }
 
 func TestNoAuthSessionId(t *testing.T) {
@@ -94,7 +94,7 @@ func TestNoAuthSessionId(t *testing.T) {
 
 			got := result{
 				StatusCode: responseSpy.Code,
-				Headers:    responseSpy.Header(),
+				Headers:    responseSpy.Result().Header,
 			}
 			if diff := cmp.Diff(tc.want, got); diff != "" {
 				t.Errorf("\nexpected: %v\ngot     : %v", tc.want, got)
@@ -168,7 +168,7 @@ func TestInvalidAuthSessionId(t *testing.T) {
 
 			got := result{
 				StatusCode: responseSpy.Code,
-				Headers:    responseSpy.Header(),
+				Headers:    responseSpy.Result().Header,
 			}
 			if diff := cmp.Diff(tc.want, got); diff != "" {
 				t.Errorf("\nexpected: %v\ngot     : %v", tc.want, got)
@@ -240,6 +240,248 @@ func returnFromCtx(value string) func(ctx context.Context) (string, error) {
 	return func(ctx context.Context) (string, error) { return value, nil }
 }
 
+func TestValidAuthSessionIdAndCookieOptions_Middleware_SetsRefreshedAuthSessionIdCookie(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "/a/b", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.AddCookie(&http.Cookie{Name: "AuthSessionId", Value: url.QueryEscape(validAuthSessionId)})
+	idpStub := test.NewIdpValidateStub(principals, externalPrincipals)
+	defer idpStub.Close()
+	rec := httptest.NewRecorder()
+	cookieOptions := idp.CookieOptions{Secure: true, SameSite: http.SameSiteStrictMode, Domain: "acme.d-velop.cloud", MaxAge: 3600}
+
+	idp.Authenticate(idpClient, returnFromCtx(idpStub.URL), returnFromCtx("1"), false, log, log, idp.WithCookieOptions(cookieOptions))(&handlerSpy{}).ServeHTTP(rec, req)
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("expected exactly one cookie but got %v", rec.Header().Values("Set-Cookie"))
+	}
+	got := cookies[0]
+	if got.Name != "AuthSessionId" || got.Value != url.QueryEscape(validAuthSessionId) {
+		t.Errorf("wrong cookie: got %v=%v want AuthSessionId=%v", got.Name, got.Value, url.QueryEscape(validAuthSessionId))
+	}
+	if !got.Secure || !got.HttpOnly || got.SameSite != http.SameSiteStrictMode || got.Domain != "acme.d-velop.cloud" || got.MaxAge != 3600 || got.Path != "/" {
+		t.Errorf("wrong cookie attributes: got '%v'", rec.Header().Get("Set-Cookie"))
+	}
+}
+
+func TestValidBearerTokenAndCookieOptions_Middleware_SetsNoCookie(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "/a/b", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+validAuthSessionId)
+	idpStub := test.NewIdpValidateStub(principals, externalPrincipals)
+	defer idpStub.Close()
+	rec := httptest.NewRecorder()
+
+	idp.Authenticate(idpClient, returnFromCtx(idpStub.URL), returnFromCtx("1"), false, log, log, idp.WithCookieOptions(idp.CookieOptions{Secure: true}))(&handlerSpy{}).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("wrong status code: got %v want %v", rec.Code, http.StatusOK)
+	}
+	if v := rec.Header().Get("Set-Cookie"); v != "" {
+		t.Errorf("expected no Set-Cookie header but got '%v'", v)
+	}
+}
+
+func TestValidAuthSessionIdAndNoCookieOptions_Middleware_SetsNoCookie(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "/a/b", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+validAuthSessionId)
+	idpStub := test.NewIdpValidateStub(principals, externalPrincipals)
+	defer idpStub.Close()
+	rec := httptest.NewRecorder()
+
+	idp.Authenticate(idpClient, returnFromCtx(idpStub.URL), returnFromCtx("1"), false, log, log)(&handlerSpy{}).ServeHTTP(rec, req)
+
+	if v := rec.Header().Get("Set-Cookie"); v != "" {
+		t.Errorf("expected no Set-Cookie header but got '%v'", v)
+	}
+}
+
+func TestInvalidAuthSessionIdAndCookieOptions_Middleware_SetsNoCookie(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "/a/b", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer invalid")
+	idpStub := test.NewIdpValidateStub(principals, externalPrincipals)
+	defer idpStub.Close()
+	rec := httptest.NewRecorder()
+
+	idp.Authenticate(idpClient, returnFromCtx(idpStub.URL), returnFromCtx("1"), false, log, log, idp.WithCookieOptions(idp.CookieOptions{Secure: true}))(&handlerSpy{}).ServeHTTP(rec, req)
+
+	if v := rec.Header().Get("Set-Cookie"); v != "" {
+		t.Errorf("expected no Set-Cookie header but got '%v'", v)
+	}
+}
+
+func TestNoAuthSessionIdAndAllowAnonymous_Middleware_CallsHandlerWithoutPrincipal(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "/public", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var principalErr error
+	var handlerCalled bool
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlerCalled = true
+		_, principalErr = idp.PrincipalFromCtx(r.Context())
+	})
+	rec := httptest.NewRecorder()
+
+	idp.Authenticate(idpClient, returnFromCtx("https://idp.example.invalid"), returnFromCtx("1"), false, log, log, idp.AllowAnonymous())(handler).ServeHTTP(rec, req)
+
+	if !handlerCalled {
+		t.Fatalf("handler hasn't been called. Middleware returned status %v", rec.Code)
+	}
+	if principalErr == nil {
+		t.Error("expected no principal on context of anonymous request")
+	}
+}
+
+func TestValidAuthSessionIdAndAllowAnonymous_Middleware_PopulatesContextWithPrincipal(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "/public", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+validAuthSessionId)
+	idpStub := test.NewIdpValidateStub(principals, externalPrincipals)
+	defer idpStub.Close()
+	handlerSpy := &handlerSpy{}
+
+	idp.Authenticate(idpClient, returnFromCtx(idpStub.URL), returnFromCtx("1"), false, log, log, idp.AllowAnonymous())(handlerSpy).ServeHTTP(httptest.NewRecorder(), req)
+
+	if err := handlerSpy.assertPrincipalIs(principals[validAuthSessionId]); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestInvalidAuthSessionIdAndAllowAnonymous_Middleware_ReturnsStatus401(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "/public", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer invalid")
+	idpStub := test.NewIdpValidateStub(principals, externalPrincipals)
+	defer idpStub.Close()
+	rec := httptest.NewRecorder()
+
+	idp.Authenticate(idpClient, returnFromCtx(idpStub.URL), returnFromCtx("1"), false, log, log, idp.AllowAnonymous())(&handlerSpy{}).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("wrong status code: got %v want %v", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+const validAPIKey = "5f0d1a3c-api-key"
+
+var apiKeyPrincipal = scim.Principal{Id: "0a7f2e4d-5b1c-4f3e-9a8d-2c6b7e1f0d3a", UserName: "service-account"}
+
+func validateAPIKey(ctx context.Context, apiKey string) (*scim.Principal, error) {
+	if apiKey == validAPIKey {
+		p := apiKeyPrincipal
+		return &p, nil
+	}
+	return nil, nil
+}
+
+func TestNoAuthSessionIdAndValidAPIKeyAndAPIKeyFallback_Middleware_PopulatesContextWithPrincipalOfAPIKey(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/myresource", nil)
+	req.Header.Set("X-Api-Key", validAPIKey)
+	handlerSpy := &handlerSpy{}
+
+	idp.Authenticate(idpClient, returnFromCtx("https://idp.example.invalid"), returnFromCtx("1"), false, log, log, idp.WithAPIKeyFallback(validateAPIKey))(handlerSpy).ServeHTTP(httptest.NewRecorder(), req)
+
+	if err := handlerSpy.assertPrincipalIs(apiKeyPrincipal); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNoAuthSessionIdAndInvalidAPIKeyAndAPIKeyFallback_Middleware_RedirectsToIdp(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/myresource", nil)
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("X-Api-Key", "invalid-api-key")
+	handlerSpy := &handlerSpy{}
+	rec := httptest.NewRecorder()
+
+	idp.Authenticate(idpClient, returnFromCtx("https://idp.example.invalid"), returnFromCtx("1"), false, log, log, idp.WithAPIKeyFallback(validateAPIKey))(handlerSpy).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusFound {
+		t.Errorf("got status %v, want %v", rec.Code, http.StatusFound)
+	}
+	if handlerSpy.hasBeenCalled {
+		t.Error("inner handler should not have been called")
+	}
+}
+
+func TestValidAuthSessionIdAndValidAPIKeyAndAPIKeyFallback_Middleware_PopulatesContextWithPrincipalOfAuthSessionId(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/myresource", nil)
+	req.Header.Set("Authorization", "Bearer "+validAuthSessionId)
+	req.Header.Set("X-Api-Key", validAPIKey)
+	idpStub := test.NewIdpValidateStub(principals, externalPrincipals)
+	defer idpStub.Close()
+	handlerSpy := &handlerSpy{}
+
+	idp.Authenticate(idpClient, returnFromCtx(idpStub.URL), returnFromCtx("1"), false, log, log, idp.WithAPIKeyFallback(validateAPIKey))(handlerSpy).ServeHTTP(httptest.NewRecorder(), req)
+
+	if err := handlerSpy.assertPrincipalIs(principals[validAuthSessionId]); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNoAuthSessionIdAndAPIKeyValidationFails_Middleware_ReturnsStatus401(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/myresource", nil)
+	req.Header.Set("X-Api-Key", validAPIKey)
+	rec := httptest.NewRecorder()
+
+	idp.Authenticate(idpClient, returnFromCtx("https://idp.example.invalid"), returnFromCtx("1"), false, log, log, idp.WithAPIKeyFallback(func(ctx context.Context, apiKey string) (*scim.Principal, error) {
+		return nil, errors.New("key store unavailable")
+	}))(&handlerSpy{}).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("got status %v, want %v", rec.Code, http.StatusUnauthorized)
+	}
+	if rec.Header().Get("WWW-Authenticate") != "Bearer" {
+		t.Errorf("got WWW-Authenticate header '%v', want '%v'", rec.Header().Get("WWW-Authenticate"), "Bearer")
+	}
+}
+
+func validateExternalAPIKey(ctx context.Context, apiKey string) (*scim.Principal, error) {
+	return &scim.Principal{Id: "external-service", Groups: []scim.UserGroup{{Value: "3E093BE5-CCCE-435D-99F8-544656B98681"}}}, nil
+}
+
+func TestNoAuthSessionIdAndAPIKeyOfExternalUserAndNoExternalValidation_Middleware_ReturnsStatus403(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/myresource", nil)
+	req.Header.Set("X-Api-Key", validAPIKey)
+	handlerSpy := &handlerSpy{}
+	rec := httptest.NewRecorder()
+
+	idp.Authenticate(idpClient, returnFromCtx("https://idp.example.invalid"), returnFromCtx("1"), false, log, log, idp.WithAPIKeyFallback(validateExternalAPIKey))(handlerSpy).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("got status %v, want %v", rec.Code, http.StatusForbidden)
+	}
+	if handlerSpy.hasBeenCalled {
+		t.Error("inner handler should not have been called")
+	}
+}
+
+func TestNoAuthSessionIdAndAPIKeyOfExternalUserAndExternalValidation_Middleware_CallsInnerHandler(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/myresource", nil)
+	req.Header.Set("X-Api-Key", validAPIKey)
+	handlerSpy := &handlerSpy{}
+
+	idp.Authenticate(idpClient, returnFromCtx("https://idp.example.invalid"), returnFromCtx("1"), true, log, log, idp.WithAPIKeyFallback(validateExternalAPIKey))(handlerSpy).ServeHTTP(httptest.NewRecorder(), req)
+
+	if !handlerSpy.hasBeenCalled {
+		t.Error("inner handler should have been called")
+	}
+}
+
 func TestRequestWithBadUrlEncodedAuthSessionIdCookie_ReturnsStatus500(t *testing.T) {
 	req, err := http.NewRequest("GET", "/myresource/subresource?query1=abc&query2=123", nil)
 	if err != nil {
@@ -360,7 +602,7 @@ func TestUserIsCachedAndCacheEntryIsNotExpired_ReturnsCachedEntry(t *testing.T)
 		t.Fatal(err)
 	}
 	const authSessionId = "jXGxJeb0q+/fS8biFi8FE7TovJPPEPyzlDxT6bh5p5pHA/x7CEi1w9egVhEMz8IWhrtvJRFnkSqJnLr61cOKf/i5eWuu7Duh+OTtTjMOt9w=&Bnh4NNU90wH_OVlgbzbdZOEu1aSuPlbUctiCdYTonZ3Ap_Zd3bVL79I-dPdHf4OOgO8NKEdqyLsqc8RhAOreXgJqXuqsreeI"
-	principal := scim.Principal{Id: "9bbbf1b6-017a-449a-ad5f-9723d28223e5"}
+	principal := scim.Principal{Schemas: []string{scim.SchemaUser}, Id: "9bbbf1b6-017a-449a-ad5f-9723d28223e5"}
 	req.Header.Set("Authorization", "Bearer "+authSessionId)
 	handlerSpy := handlerSpy{}
 	idpCalled := 0
@@ -391,7 +633,7 @@ func TestUserIsCachedButCacheEntryIsExpired_CallsIdp(t *testing.T) {
 		t.Fatal(err)
 	}
 	const authSessionId = "kXGxJeb0q+/fS8biFi8FE7TovJPPEPyzlDxT6bh5p5pHA/x7CEi1w9egVhEMz8IWhrtvJRFnkSqJnLr61cOKf/i5eWuu7Duh+OTtTjMOt9w=&Bnh4NNU90wH_OVlgbzbdZOEu1aSuPlbUctiCdYTonZ3Ap_Zd3bVL79I-dPdHf4OOgO8NKEdqyLsqc8RhAOreXgJqXuqsreeI"
-	principal := scim.Principal{Id: "9bbbf1b6-017a-449a-ad5f-9723d28223e6"}
+	principal := scim.Principal{Schemas: []string{scim.SchemaUser}, Id: "9bbbf1b6-017a-449a-ad5f-9723d28223e6"}
 	req.Header.Set("Authorization", "Bearer "+authSessionId)
 	handlerSpy := handlerSpy{}
 	idpCalled := 0
@@ -423,7 +665,7 @@ func TestUserIsCachedForDifferentTenant_CallsIdp(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	principalT1 := scim.Principal{Id: "9bbbf1b6-017a-449a-ad5f-9723d28223e7"}
+	principalT1 := scim.Principal{Schemas: []string{scim.SchemaUser}, Id: "9bbbf1b6-017a-449a-ad5f-9723d28223e7"}
 	reqTenant1.Header.Set("Authorization", "Bearer "+authSessionId)
 	idpStub1 := test.NewIdpValidateStub(map[string]scim.Principal{authSessionId: principalT1}, nil)
 	defer idpStub1.Close()
@@ -438,7 +680,7 @@ func TestUserIsCachedForDifferentTenant_CallsIdp(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	principalT2 := scim.Principal{Id: "0bbbf1b6-017a-449a-ad5f-9723d28223e7"}
+	principalT2 := scim.Principal{Schemas: []string{scim.SchemaUser}, Id: "0bbbf1b6-017a-449a-ad5f-9723d28223e7"}
 	reqTenant2.Header.Set("Authorization", "Bearer "+authSessionId)
 	idpStub2 := test.NewIdpValidateStub(map[string]scim.Principal{authSessionId: principalT2}, nil)
 	defer idpStub2.Close()
@@ -458,7 +700,7 @@ func TestRequestAsExternalUserAndExternalUserValidationIsNotAllowed_ReturnsStatu
 	const authSessionId = "hXGxJeb0q+/fS8biFi8FE7TovJPPEPyzlDxT6bh5p5pHA/x7CEi1w9egVhEMz8IWhrtvJRFnkSqJnLr61cOKf/i5eWuu7Duh+OTtTjMOt9w=&Bnh4NNU90wH_OVlgbzbdZOEu1aSuPlbUctiCdYTonZ3Ap_Zd3bVL79I-dPdHf4OOgO8NKEdqyLsqc8RhAOreXgJqXuqsreeI"
 	req.Header.Set("Authorization", "Bearer "+authSessionId)
 	handlerSpy := handlerSpy{}
-	idpStub := test.NewIdpValidateStub(nil, map[string]scim.Principal{authSessionId: {Emails: []scim.UserValue{{"info@d-velop.de"}}, Groups: []scim.UserGroup{{Value: "3E093BE5-CCCE-435D-99F8-544656B98681"}}}})
+	idpStub := test.NewIdpValidateStub(nil, map[string]scim.Principal{authSessionId: {Emails: []scim.UserValue{{Value: "info@d-velop.de"}}, Groups: []scim.UserGroup{{Value: "3E093BE5-CCCE-435D-99F8-544656B98681"}}}})
 	defer idpStub.Close()
 	spy := responseSpy{httptest.NewRecorder()}
 
@@ -477,7 +719,7 @@ func TestRequestAsExternalUserAndExternalValidationIsAllowed_PopulatesContextWit
 		t.Fatal(err)
 	}
 	const authSessionId = "1XGxJeb0q+/fS8biFi8FE7TovJPPEPyzlDxT6bh5p5pHA/x7CEi1w9egVhEMz8IWhrtvJRFnkSqJnLr61cOKf/i5eWuu7Duh+OTtTjMOt9w=&Bnh4NNU90wH_OVlgbzbdZOEu1aSuPlbUctiCdYTonZ3Ap_Zd3bVL79I-dPdHf4OOgO8NKEdqyLsqc8RhAOreXgJqXuqsreeI"
-	principal := scim.Principal{Emails: []scim.UserValue{{"info@d-velop.de"}}, Groups: []scim.UserGroup{{Value: "3E093BE5-CCCE-435D-99F8-544656B98681"}}}
+	principal := scim.Principal{Schemas: []string{scim.SchemaUser}, Emails: []scim.UserValue{{Value: "info@d-velop.de"}}, Groups: []scim.UserGroup{{Value: "3E093BE5-CCCE-435D-99F8-544656B98681"}}}
 	req.Header.Set("Authorization", "Bearer "+authSessionId)
 	handlerSpy := new(handlerSpy)
 	idpStub := test.NewIdpValidateStub(nil, map[string]scim.Principal{authSessionId: principal})
@@ -499,7 +741,7 @@ func TestRequestAsInternalUserAndExternalValidationIsAllowed_PopulatesContextWit
 		t.Fatal(err)
 	}
 	const authSessionId = "2XGxJeb0q+/fS8biFi8FE7TovJPPEPyzlDxT6bh5p5pHA/x7CEi1w9egVhEMz8IWhrtvJRFnkSqJnLr61cOKf/i5eWuu7Duh+OTtTjMOt9w=&Bnh4NNU90wH_OVlgbzbdZOEu1aSuPlbUctiCdYTonZ3Ap_Zd3bVL79I-dPdHf4OOgO8NKEdqyLsqc8RhAOreXgJqXuqsreeI"
-	principal := scim.Principal{Id: "7bbbf1b6-017a-449a-ad5f-9723d28223e1"}
+	principal := scim.Principal{Schemas: []string{scim.SchemaUser}, Id: "7bbbf1b6-017a-449a-ad5f-9723d28223e1"}
 	req.Header.Set("Authorization", "Bearer "+authSessionId)
 	handlerSpy := new(handlerSpy)
 	idpStub := test.NewIdpValidateStub(map[string]scim.Principal{authSessionId: principal}, nil)
@@ -521,7 +763,7 @@ func TestIdpSendsNoCacheHeader_CallsIdp(t *testing.T) {
 		t.Fatal(err)
 	}
 	const authSessionId = "mXGxJeb0q+/fS8biFi8FE7TovJPPEPyzlDxT6bh5p5pHA/x7CEi1w9egVhEMz8IWhrtvJRFnkSqJnLr61cOKf/i5eWuu7Duh+OTtTjMOt9w=&Bnh4NNU90wH_OVlgbzbdZOEu1aSuPlbUctiCdYTonZ3Ap_Zd3bVL79I-dPdHf4OOgO8NKEdqyLsqc8RhAOreXgJqXuqsreeI"
-	principal := scim.Principal{Id: "9bbbf1b6-017a-449a-ad5f-9723d28223e7"}
+	principal := scim.Principal{Schemas: []string{scim.SchemaUser}, Id: "9bbbf1b6-017a-449a-ad5f-9723d28223e7"}
 	req.Header.Set("Authorization", "Bearer "+authSessionId)
 	handlerSpy := handlerSpy{}
 	idpCalled := 0
@@ -612,6 +854,32 @@ func TestNoAuthSessionIdAndGetRequestAndAcceptHeaderIs(t *testing.T) {
 	}
 }
 
+func TestNoJWTAsBearerToken_AuthenticateWithJWT_ReturnsStatus401(t *testing.T) {
+	req, err := http.NewRequest("GET", "/myresource/subresource", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+validAuthSessionId)
+	handlerSpy := handlerSpy{}
+	responseSpy := responseSpy{httptest.NewRecorder()}
+
+	authenticate, err := idp.AuthenticateWithJWT("http://jwks.invalid", "myapp", func(systemBaseUri string, tenantId string) string {
+		return systemBaseUri + "/identityprovider"
+	}, returnFromCtx("https://acme.d-velop.cloud"), returnFromCtx("1"), false, log, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticate(&handlerSpy).ServeHTTP(responseSpy, req)
+
+	if err := responseSpy.assertStatusCodeIs(http.StatusUnauthorized); err != nil {
+		t.Error(err)
+	}
+	if handlerSpy.hasBeenCalled {
+		t.Error("inner handler should not have been called")
+	}
+}
+
 type handlerSpy struct {
 	authSessionId string
 	principal     scim.Principal
@@ -651,7 +919,7 @@ func (spy *responseSpy) assertStatusCodeIs(expectedStatusCode int) error {
 
 func (spy *responseSpy) assertHeadersAre(expectedHeaders map[string]string) error {
 	for header, expectedValue := range expectedHeaders {
-		if actualValue := spy.Header().Get(header); actualValue != expectedValue {
+		if actualValue := spy.Result().Header.Get(header); actualValue != expectedValue {
 			return fmt.Errorf("handler returned wrong %v header: got %v want %v", header, actualValue, expectedValue)
 		}
 	}
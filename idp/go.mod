@@ -5,4 +5,4 @@ require (
 	github.com/patrickmn/go-cache v2.1.0+incompatible
 )
 
-go 1.13
+go 1.18
@@ -0,0 +1,35 @@
+package idpclient
+
+import (
+	"sync/atomic"
+)
+
+// CacheStats contains statistics about the principal cache of the client.
+type CacheStats struct {
+	// Items is the number of items in the principal cache. It is 0 if the cache doesn't implement ItemCounter.
+	Items int
+	// HitCount is the number of calls to Validate which have found the principal in the cache.
+	HitCount int64
+	// MissCount is the number of calls to Validate which haven't found the principal in the cache.
+	MissCount int64
+}
+
+// ItemCounter is implemented by caches which are able to count their items.
+// CacheStats only reports the number of items if the principal cache implements ItemCounter.
+type ItemCounter interface {
+	// ItemCount returns the number of items in the cache, which may include expired items.
+	ItemCount() int
+}
+
+// CacheStats returns statistics about the principal cache since the client has been created.
+// Use WithMetrics instead if the cache hits and misses should be recorded as they occur.
+func (c *client) CacheStats() CacheStats {
+	stats := CacheStats{
+		HitCount:  atomic.LoadInt64(&c.cacheHits),
+		MissCount: atomic.LoadInt64(&c.cacheMisses),
+	}
+	if counter, ok := c.principalCache.(ItemCounter); ok {
+		stats.Items = counter.ItemCount()
+	}
+	return stats
+}
@@ -3,14 +3,23 @@
 package idpclient
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unicode"
 
 	"github.com/patrickmn/go-cache"
 
@@ -18,8 +27,20 @@ import (
 )
 
 type client struct {
-	httpClient     *http.Client
-	principalCache Cache
+	cacheHits             int64 // accessed atomically, first in struct to ensure 64-bit alignment
+	cacheMisses           int64 // accessed atomically
+	httpClient            *http.Client
+	principalCache        Cache
+	metrics               Metrics
+	tokenExchangeEndpoint string
+	maxConcurrentRequests int
+	proxyURL              *url.URL
+	tlsConfig             *tls.Config
+	pool                  *connectionPool
+	logoutCallback        func(tenantId, authSessionId string)
+	maxResponseSize       int64
+	discoveryCache        Cache // nil if the option WithOIDCDiscovery isn't set
+	customHttp            bool  // true if the http.Client has been set with the HttpClient option
 }
 
 // Cache is an interface representing the ability to cache arbitrary items for
@@ -34,13 +55,136 @@ type Cache interface {
 	Set(key string, item interface{}, cacheDuration time.Duration)
 }
 
+// Deleter is implemented by caches which are able to remove an item before it expires.
+// InvalidatePrincipal only has an effect if the principal cache implements Deleter.
+type Deleter interface {
+	// Delete an item from the cache. Does nothing if the key is not in the cache.
+	Delete(key string)
+}
+
 type Option func(*client) error
 
+// IdpClientError is returned if the IdentityProvider-App responds with an unexpected HTTP status code.
+type IdpClientError struct {
+	// Endpoint is the URL of the IdentityProvider-App which has been called.
+	Endpoint string
+	// StatusCode is the HTTP status code returned by the IdentityProvider-App.
+	StatusCode int
+	// Message is the response body returned by the IdentityProvider-App.
+	Message string
+}
+
+func (e IdpClientError) Error() string {
+	return fmt.Sprintf("unexpected error. Identityprovider '%s' returned HTTP-Statuscode '%d' and message '%s'", e.Endpoint, e.StatusCode, e.Message)
+}
+
+func newIdpClientError(resp *http.Response) IdpClientError {
+	responseMsg, _ := ioutil.ReadAll(resp.Body)
+	return IdpClientError{
+		Endpoint:   resp.Request.URL.String(),
+		StatusCode: resp.StatusCode,
+		Message:    string(responseMsg),
+	}
+}
+
+// IdpValidateError is returned by Validate if the authSessionId couldn't be validated.
+type IdpValidateError struct {
+	// StatusCode is the HTTP status code returned by the IdentityProvider-App.
+	// It is 0 if the IdentityProvider-App couldn't be reached, e.g. due to a timeout.
+	StatusCode int
+	// Endpoint is the endpoint of the IdentityProvider-App which has been called.
+	Endpoint string
+	// Cause is the underlying error. It is an IdpClientError if the IdentityProvider-App responded with an unexpected HTTP status code.
+	Cause error
+}
+
+func (e IdpValidateError) Error() string {
+	switch e.StatusCode {
+	case 0:
+		return fmt.Sprintf("error calling http GET on '%s' because: %v", e.Endpoint, e.Cause)
+	case http.StatusOK:
+		return fmt.Sprintf("response from Identityprovider '%s' is no valid JSON because: %v", e.Endpoint, e.Cause)
+	default:
+		return fmt.Sprintf("error validating authSessionId because: %v", e.Cause)
+	}
+}
+
+func (e IdpValidateError) Unwrap() error {
+	return e.Cause
+}
+
 // HttpClient explicitly sets the http.Client which should be used to make
 // request against the IdentityProvider-App
 func HttpClient(h *http.Client) Option {
 	return func(c *client) error {
 		c.httpClient = h
+		c.customHttp = true
+		return nil
+	}
+}
+
+// WithProxy routes all requests against the IdentityProvider-App through the proxy specified by proxyURL.
+//
+// If a custom http.Client is set with the HttpClient option the proxy is applied to a copy of its transport,
+// which must be an *http.Transport.
+func WithProxy(proxyURL *url.URL) Option {
+	return func(c *client) error {
+		c.proxyURL = proxyURL
+		return nil
+	}
+}
+
+// WithTLSConfig uses a new http.Client whose transport uses tlsConfig to connect to the IdentityProvider-App,
+// e.g. to trust a self-signed certificate or to authenticate with a client certificate.
+//
+// WithTLSConfig can't be combined with the HttpClient option. Configure the transport of the custom
+// http.Client instead.
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(c *client) error {
+		c.tlsConfig = tlsConfig
+		return nil
+	}
+}
+
+type connectionPool struct {
+	maxIdleConns    int
+	maxConnsPerHost int
+	idleConnTimeout time.Duration
+}
+
+// WithTransport uses a new http.Client whose transport has a connection pool with the given size
+// to connect to the IdentityProvider-App. Cf. the equally named fields of http.Transport for the meaning of the values.
+// MaxIdleConnsPerHost is set to maxIdleConns as well, because the client usually connects to a single host.
+//
+// WithTransport can't be combined with the HttpClient option. Configure the transport of the custom
+// http.Client instead.
+func WithTransport(maxIdleConns, maxConnsPerHost int, idleConnTimeout time.Duration) Option {
+	return func(c *client) error {
+		c.pool = &connectionPool{maxIdleConns: maxIdleConns, maxConnsPerHost: maxConnsPerHost, idleConnTimeout: idleConnTimeout}
+		return nil
+	}
+}
+
+// defaultMaxConcurrentRequests is the number of concurrent requests GetPrincipalsByIds makes if not configured otherwise
+const defaultMaxConcurrentRequests = 10
+
+// WithMaxConcurrentRequests sets the maximum number of concurrent requests against the IdentityProvider-App
+// which GetPrincipalsByIds makes.
+func WithMaxConcurrentRequests(n int) Option {
+	return func(c *client) error {
+		if n < 1 {
+			return fmt.Errorf("max concurrent requests must be at least 1 but is %d", n)
+		}
+		c.maxConcurrentRequests = n
+		return nil
+	}
+}
+
+// WithLogoutCallback registers fn which is called by InvalidatePrincipal after the cached principal has been removed,
+// e.g. to propagate the logout to other instances of the App.
+func WithLogoutCallback(fn func(tenantId, authSessionId string)) Option {
+	return func(c *client) error {
+		c.logoutCallback = fn
 		return nil
 	}
 }
@@ -56,12 +200,21 @@ func PrincipalCache(pc Cache) Option {
 //
 //   - HttpClient: http.DefaultClient
 //   - principalCache: An internal implementation is used
+//   - metrics: NopMetrics
+//   - proxy: the proxy configured by the environment (cf. http.ProxyFromEnvironment)
+//   - tokenExchangeEndpoint: /identityprovider/token
+//   - maxConcurrentRequests: 10
+//   - OIDC discovery: disabled, Validate calls /identityprovider/validate
 //
 // If you don't want to use the defaults provide one or more options to this function.
 func New(options ...Option) (*client, error) {
 	c := &client{
-		httpClient:     http.DefaultClient,
-		principalCache: cache.New(cache.DefaultExpiration, 5*time.Minute), // use defaultExpiration to fulfill Set() of Cache interface
+		httpClient:            http.DefaultClient,
+		principalCache:        cache.New(cache.DefaultExpiration, 5*time.Minute), // use defaultExpiration to fulfill Set() of Cache interface
+		metrics:               NopMetrics{},
+		tokenExchangeEndpoint: defaultTokenExchangeEndpoint,
+		maxConcurrentRequests: defaultMaxConcurrentRequests,
+		maxResponseSize:       defaultMaxResponseSize,
 	}
 
 	for _, option := range options {
@@ -71,11 +224,69 @@ func New(options ...Option) (*client, error) {
 		}
 	}
 
+	if c.customHttp && c.tlsConfig != nil {
+		return nil, errors.New("the options HttpClient and WithTLSConfig can't be combined")
+	}
+	if c.customHttp && c.pool != nil {
+		return nil, errors.New("the options HttpClient and WithTransport can't be combined")
+	}
+	if c.tlsConfig != nil || c.pool != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if c.tlsConfig != nil {
+			transport.TLSClientConfig = c.tlsConfig
+		}
+		if c.pool != nil {
+			transport.MaxIdleConns = c.pool.maxIdleConns
+			transport.MaxIdleConnsPerHost = c.pool.maxIdleConns
+			transport.MaxConnsPerHost = c.pool.maxConnsPerHost
+			transport.IdleConnTimeout = c.pool.idleConnTimeout
+		}
+		c.httpClient = &http.Client{Transport: transport}
+	}
+
+	if c.proxyURL != nil {
+		if err := c.applyProxy(); err != nil {
+			return nil, err
+		}
+	}
+
 	return c, nil
 }
 
+// applyProxy sets the proxy on a copy of the http client and its transport so that shared clients
+// like http.DefaultClient remain unchanged
+func (c *client) applyProxy() error {
+	var transport *http.Transport
+	switch t := c.httpClient.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		return fmt.Errorf("can't apply proxy '%s' because transport of http client is no *http.Transport but %T", c.proxyURL, t)
+	}
+	transport.Proxy = http.ProxyURL(c.proxyURL)
+	httpClient := *c.httpClient
+	httpClient.Transport = transport
+	c.httpClient = &httpClient
+	return nil
+}
+
 var maxAgeRegex = regexp.MustCompile(`(?i)max-age=([^,\s]*)`) // cf. https://regex101.com/
 
+// maxAge returns the max-age of the Cache-Control header or 0 if it has none
+func maxAge(cacheControlHeader string) time.Duration {
+	matches := maxAgeRegex.FindStringSubmatch(cacheControlHeader)
+	if matches == nil {
+		return 0
+	}
+	d, err := time.ParseDuration(matches[1] + "s")
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
 /*
 Validate checks if the authSessionId is valid for the tenant specified by systemBaseUri and tenantId.
 
@@ -83,7 +294,9 @@ If the authSessionId is valid, that is it belongs to a principal and has not exp
 Otherwise the returned *scim.Principal is nil.
 
 An error is returned if something unexpected occurred.
-The returned error will be of type *url.Error if the remote call to the IdentityProvider-App failed due to a network
+Errors are returned as IdpValidateError. Its StatusCode is 0 if the IdentityProvider-App couldn't be reached
+and the HTTP status code of the response otherwise.
+The wrapped error will be of type *url.Error if the remote call to the IdentityProvider-App failed due to a network
 connectivity problem or a timout. In case of a timeout the error values Timeout() method will report true.
 
 Use a context with timeout to set a timeout for validate like:
@@ -117,36 +330,34 @@ Inspect the scim.Principal after a successful validation to distinguish external
 (cf. documentation of scim.Principal for further information).
 */
 func (c *client) Validate(ctx context.Context, systemBaseUri string, tenantId string, authSessionId string) (*scim.Principal, error) {
-	cacheKey := fmt.Sprintf("%s/%s", tenantId, authSessionId)
+	cacheKey := principalCacheKey(tenantId, authSessionId)
 	co, found := c.principalCache.Get(cacheKey)
 	if found {
+		atomic.AddInt64(&c.cacheHits, 1)
+		c.metrics.RecordCacheHit()
 		p := co.(scim.Principal)
 		return &p, nil
 	}
+	atomic.AddInt64(&c.cacheMisses, 1)
+	c.metrics.RecordCacheMiss()
 
-	endpoint := "/identityprovider/validate?allowExternalValidation=true"
+	endpoint, eErr := c.validateEndpoint(ctx, systemBaseUri)
+	if eErr != nil {
+		return nil, eErr
+	}
 	resp, doErr := c.httpGet(ctx, systemBaseUri, authSessionId, endpoint)
 	if doErr != nil {
-		return nil, fmt.Errorf("error calling http GET on '%s' because: %w", endpoint, doErr)
+		return nil, IdpValidateError{Endpoint: endpoint, Cause: doErr}
 	}
 	defer resp.Body.Close()
 
 	switch resp.StatusCode {
 	case http.StatusOK:
-		var p scim.Principal
-		if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
-			return nil, fmt.Errorf("response from Identityprovider '%s' is no valid JSON because: %v", endpoint, err)
-		}
-		var validFor time.Duration = 0
-		cacheControlHeader := resp.Header.Get("Cache-Control")
-		matches := maxAgeRegex.FindStringSubmatch(cacheControlHeader)
-		if matches != nil {
-			d, err := time.ParseDuration(matches[1] + "s")
-			if err == nil {
-				validFor = d
-			}
+		p, err := c.decodePrincipal(resp.Body)
+		if err != nil {
+			return nil, IdpValidateError{StatusCode: resp.StatusCode, Endpoint: endpoint, Cause: err}
 		}
-		if validFor > 0 {
+		if validFor := maxAge(resp.Header.Get("Cache-Control")); validFor > 0 {
 			c.principalCache.Set(cacheKey, p, validFor)
 		}
 		return &p, nil
@@ -154,12 +365,39 @@ func (c *client) Validate(ctx context.Context, systemBaseUri string, tenantId st
 		_, _ = ioutil.ReadAll(resp.Body) // client must read to EOF and close body cf. https://godoc.org/net/http#Client
 		return nil, nil
 	default:
-		responseMsg, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("unexpected error. Identityprovider '%s' returned HTTP-Statuscode '%d' and message '%s'",
-			resp.Request.URL, resp.StatusCode, responseMsg)
+		return nil, IdpValidateError{StatusCode: resp.StatusCode, Endpoint: endpoint, Cause: newIdpClientError(resp)}
 	}
 }
 
+/*
+InvalidatePrincipal removes the principal cached by Validate for the authSessionId of the tenant specified by tenantId,
+so that the next call of Validate asks the IdentityProvider-App again. Use it if a user logs out, because otherwise
+the cached principal stays valid until it expires.
+
+The default in-memory cache supports the removal of principals. Custom caches must implement Deleter,
+otherwise the cached principal remains unchanged.
+The callback registered with WithLogoutCallback is called in any case.
+
+Example:
+
+	mux.HandleFunc("/myapp/logout", func(w http.ResponseWriter, r *http.Request) {
+		// ...
+		client.InvalidatePrincipal(tenantId, authSessionId)
+	})
+*/
+func (c *client) InvalidatePrincipal(tenantId string, authSessionId string) {
+	if d, ok := c.principalCache.(Deleter); ok {
+		d.Delete(principalCacheKey(tenantId, authSessionId))
+	}
+	if c.logoutCallback != nil {
+		c.logoutCallback(tenantId, authSessionId)
+	}
+}
+
+func principalCacheKey(tenantId string, authSessionId string) string {
+	return fmt.Sprintf("%s/%s", tenantId, authSessionId)
+}
+
 /*
 GetPrincipalById gets the principal specified by principalId for the tenant specified by systemBaseUri and tenantId.
 The authSessionId is used to authorize the request.
@@ -197,7 +435,281 @@ func (c *client) GetPrincipalById(ctx context.Context, systemBaseUri string, ten
 	}
 }
 
+/*
+PrincipalExists checks if the principal specified by principalId exists for the tenant specified by systemBaseUri and tenantId.
+The authSessionId is used to authorize the request.
+
+The check is done by a HEAD request, so the principal isn't transferred.
+If the IdentityProvider-App responds neither with 200 nor with 404 the error is an IdpClientError.
+*/
+func (c *client) PrincipalExists(ctx context.Context, systemBaseUri string, tenantId string, authSessionId string, principalId string) (bool, error) {
+	endpoint := "/identityprovider/scim/users/" + url.PathEscape(principalId)
+	resp, doErr := c.httpDo(ctx, http.MethodHead, systemBaseUri, authSessionId, endpoint, nil, "")
+	if doErr != nil {
+		return false, fmt.Errorf("error calling http HEAD on '%s' because: %w", endpoint, doErr)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, newIdpClientError(resp)
+	}
+}
+
+/*
+GetPrincipalsByIds gets the principals specified by ids for the tenant specified by systemBaseUri and tenantId.
+The authSessionId is used to authorize the requests.
+
+The principals are fetched by concurrent GetPrincipalById calls. The number of concurrent requests is limited
+by the option WithMaxConcurrentRequests.
+
+The returned map contains an entry for each id. The value is nil if the principal doesn't exist.
+If one of the calls fails the first error is returned.
+*/
+func (c *client) GetPrincipalsByIds(ctx context.Context, systemBaseUri string, tenantId string, authSessionId string, ids []string) (map[string]*scim.Principal, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+	)
+	principals := make(map[string]*scim.Principal, len(ids))
+	seen := make(map[string]bool, len(ids))
+	sem := make(chan struct{}, c.maxConcurrentRequests)
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(id string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			p, err := c.GetPrincipalById(ctx, systemBaseUri, tenantId, authSessionId, id)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				return
+			}
+			principals[id] = p
+		}(id)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return principals, nil
+}
+
+// defaultPrincipalsPageSize is the number of principals ListPrincipals fetches if no count is given
+const defaultPrincipalsPageSize = 50
+
+/*
+ListPrincipals gets at most count principals of the tenant specified by systemBaseUri and tenantId
+beginning with the principal at the 1-based startIndex. If count is 0 a page size of 50 is used.
+The authSessionId is used to authorize the request.
+
+Use the TotalResults of the returned *scim.ListResponse to determine if there are further pages.
+If the IdentityProvider-App responds with an unexpected HTTP status code the error is an IdpClientError,
+e.g. with StatusCode 403 if the user is not allowed to read the principals.
+*/
+func (c *client) ListPrincipals(ctx context.Context, systemBaseUri string, tenantId string, authSessionId string, startIndex int, count int) (*scim.ListResponse[scim.Principal], error) {
+	if count == 0 {
+		count = defaultPrincipalsPageSize
+	}
+	endpoint := "/identityprovider/scim/users?startIndex=" + strconv.Itoa(startIndex) + "&count=" + strconv.Itoa(count)
+	resp, doErr := c.httpGet(ctx, systemBaseUri, authSessionId, endpoint)
+	if doErr != nil {
+		return nil, fmt.Errorf("error calling http GET on '%s' because: %w", endpoint, doErr)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var l scim.ListResponse[scim.Principal]
+		if err := json.NewDecoder(resp.Body).Decode(&l); err != nil {
+			return nil, fmt.Errorf("response from Identityprovider '%s' is no valid JSON because: %v", endpoint, err)
+		}
+		return &l, nil
+	default:
+		return nil, newIdpClientError(resp)
+	}
+}
+
+/*
+SearchPrincipals gets the principals of the tenant specified by systemBaseUri and tenantId which match the SCIM filter,
+e.g. 'displayName co "John"' (cf. https://www.rfc-editor.org/rfc/rfc7644#section-3.4.2.2).
+The authSessionId is used to authorize the request.
+
+The filter is URL-encoded. Filters which contain control characters are rejected with an error.
+If the IdentityProvider-App responds with an unexpected HTTP status code the error is an IdpClientError,
+e.g. with StatusCode 400 if the filter is invalid.
+*/
+func (c *client) SearchPrincipals(ctx context.Context, systemBaseUri string, tenantId string, authSessionId string, filter string) ([]scim.Principal, error) {
+	if filter == "" {
+		return nil, errors.New("filter must not be empty")
+	}
+	if strings.IndexFunc(filter, unicode.IsControl) >= 0 {
+		return nil, fmt.Errorf("filter %q contains control characters", filter)
+	}
+	endpoint := "/identityprovider/scim/users?" + url.Values{"filter": {filter}}.Encode()
+	resp, doErr := c.httpGet(ctx, systemBaseUri, authSessionId, endpoint)
+	if doErr != nil {
+		return nil, fmt.Errorf("error calling http GET on '%s' because: %w", endpoint, doErr)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var l scim.ListResponse[scim.Principal]
+		if err := json.NewDecoder(resp.Body).Decode(&l); err != nil {
+			return nil, fmt.Errorf("response from Identityprovider '%s' is no valid JSON because: %v", endpoint, err)
+		}
+		return l.Resources, nil
+	default:
+		return nil, newIdpClientError(resp)
+	}
+}
+
+/*
+GetGroupById gets the group specified by groupId for the tenant specified by systemBaseUri and tenantId.
+The authSessionId is used to authorize the request.
+
+If the group exists, a none nil *scim.Group is returned.
+If the group doesn't exist the returned *scim.Group and the error are nil.
+Otherwise the error is an IdpClientError, e.g. with StatusCode 403 if the user is not allowed to read the group.
+*/
+func (c *client) GetGroupById(ctx context.Context, systemBaseUri string, tenantId string, authSessionId string, groupId string) (*scim.Group, error) {
+	endpoint := "/identityprovider/scim/groups/" + url.PathEscape(groupId)
+	resp, doErr := c.httpGet(ctx, systemBaseUri, authSessionId, endpoint)
+	if doErr != nil {
+		return nil, fmt.Errorf("error calling http GET on '%s' because: %w", endpoint, doErr)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var g scim.Group
+		if err := json.NewDecoder(resp.Body).Decode(&g); err != nil {
+			return nil, fmt.Errorf("response from Identityprovider '%s' is no valid JSON because: %v", endpoint, err)
+		}
+		return &g, nil
+	case http.StatusNotFound:
+		_, _ = ioutil.ReadAll(resp.Body)
+		return nil, nil
+	default:
+		return nil, newIdpClientError(resp)
+	}
+}
+
+// groupsPageSize is the number of groups GetGroups fetches with each request
+const groupsPageSize = 100
+
+/*
+GetGroups gets all groups of the tenant specified by systemBaseUri and tenantId.
+The authSessionId is used to authorize the request.
+
+The groups are fetched page by page until all groups have been read.
+If the IdentityProvider-App responds with an unexpected HTTP status code the error is an IdpClientError,
+e.g. with StatusCode 403 if the user is not allowed to read the groups.
+*/
+func (c *client) GetGroups(ctx context.Context, systemBaseUri string, tenantId string, authSessionId string) ([]scim.Group, error) {
+	groups := []scim.Group{}
+	for startIndex := 1; ; startIndex += groupsPageSize {
+		page, err := c.getGroupsPage(ctx, systemBaseUri, authSessionId, startIndex, groupsPageSize)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, page.Resources...)
+		if len(page.Resources) < groupsPageSize || len(groups) >= page.TotalResults {
+			return groups, nil
+		}
+	}
+}
+
+/*
+GetGroupsPaged gets at most count groups of the tenant specified by systemBaseUri and tenantId
+beginning with the group at the 1-based startIndex.
+The authSessionId is used to authorize the request.
+
+If the IdentityProvider-App responds with an unexpected HTTP status code the error is an IdpClientError.
+*/
+func (c *client) GetGroupsPaged(ctx context.Context, systemBaseUri string, tenantId string, authSessionId string, startIndex int, count int) ([]scim.Group, error) {
+	page, err := c.getGroupsPage(ctx, systemBaseUri, authSessionId, startIndex, count)
+	if err != nil {
+		return nil, err
+	}
+	return page.Resources, nil
+}
+
+func (c *client) getGroupsPage(ctx context.Context, systemBaseUri string, authSessionId string, startIndex int, count int) (*scim.ListResponse[scim.Group], error) {
+	endpoint := "/identityprovider/scim/groups?startIndex=" + strconv.Itoa(startIndex) + "&count=" + strconv.Itoa(count)
+	resp, doErr := c.httpGet(ctx, systemBaseUri, authSessionId, endpoint)
+	if doErr != nil {
+		return nil, fmt.Errorf("error calling http GET on '%s' because: %w", endpoint, doErr)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var l scim.ListResponse[scim.Group]
+		if err := json.NewDecoder(resp.Body).Decode(&l); err != nil {
+			return nil, fmt.Errorf("response from Identityprovider '%s' is no valid JSON because: %v", endpoint, err)
+		}
+		return &l, nil
+	default:
+		return nil, newIdpClientError(resp)
+	}
+}
+
+/*
+UpdatePrincipal partially updates the principal specified by principalId for the tenant specified by systemBaseUri and tenantId
+by applying the operations of the patch request.
+The authSessionId is used to authorize the request.
+
+If the principal has been updated the updated *scim.Principal is returned.
+If the principal doesn't exist the returned *scim.Principal is nil and the error is an IdpClientError with StatusCode 404.
+*/
+func (c *client) UpdatePrincipal(ctx context.Context, systemBaseUri string, tenantId string, authSessionId string, principalId string, patch scim.PatchRequest) (*scim.Principal, error) {
+	body, mErr := json.Marshal(patch)
+	if mErr != nil {
+		return nil, fmt.Errorf("can't marshal patch request because: %v", mErr)
+	}
+	endpoint := "/identityprovider/scim/users/" + url.PathEscape(principalId)
+	resp, doErr := c.httpDo(ctx, http.MethodPatch, systemBaseUri, authSessionId, endpoint, bytes.NewReader(body), "application/json")
+	if doErr != nil {
+		return nil, fmt.Errorf("error calling http PATCH on '%s' because: %w", endpoint, doErr)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var p scim.Principal
+		if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+			return nil, fmt.Errorf("response from Identityprovider '%s' is no valid JSON because: %v", endpoint, err)
+		}
+		return &p, nil
+	default:
+		return nil, newIdpClientError(resp)
+	}
+}
+
 func (c *client) httpGet(ctx context.Context, systemBaseUri string, authSessionId string, absolutePath string) (*http.Response, error) {
+	return c.httpDo(ctx, http.MethodGet, systemBaseUri, authSessionId, absolutePath, nil, "")
+}
+
+func (c *client) httpDo(ctx context.Context, method string, systemBaseUri string, authSessionId string, absolutePath string, body io.Reader, contentType string) (*http.Response, error) {
 	baseUri, baseParseErr := url.Parse(systemBaseUri)
 	if baseParseErr != nil {
 		return nil, baseParseErr
@@ -205,11 +717,26 @@ func (c *client) httpGet(ctx context.Context, systemBaseUri string, authSessionI
 	resourcePath, _ := url.Parse(absolutePath)
 	resourceEndpoint := baseUri.ResolveReference(resourcePath)
 
-	req, nRErr := http.NewRequestWithContext(ctx, http.MethodGet, resourceEndpoint.String(), nil)
+	req, nRErr := http.NewRequestWithContext(ctx, method, resourceEndpoint.String(), body)
 	if nRErr != nil {
 		return nil, fmt.Errorf("can't create http request for '%s' because: %v", resourceEndpoint, nRErr)
 	}
-	req.Header.Set("Authorization", "Bearer "+authSessionId)
+	if authSessionId != "" {
+		req.Header.Set("Authorization", "Bearer "+authSessionId)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
 
-	return c.httpClient.Do(req)
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	statusCode := 0
+	if resp != nil {
+		statusCode = resp.StatusCode
+	}
+	c.metrics.RecordIdpCallDuration(time.Since(start), metricsEndpoint(resourceEndpoint.Path), statusCode)
+	if err == nil {
+		resp.Body = newLimitedBody(resp.Body, c.maxResponseSize)
+	}
+	return resp, err
 }
@@ -3,6 +3,8 @@ package idpclient_test
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,6 +13,9 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -39,13 +44,13 @@ var defaultClient, _ = idpclient.New()
 const validAuthSessionId = "aXGxJeb0q+/fS8biFi8FE7TovJPPEPyzlDxT6bh5p5pHA/x7CEi1w9egVhEMz8IWhrtvJRFnkSqJnLr61cOKf/i5eWuu7Duh+OTtTjMOt9w=&Bnh4NNU90wH_OVlgbzbdZOEu1aSuPlbUctiCdYTonZ3Ap_Zd3bVL79I-dPdHf4OOgO8NKEdqyLsqc8RhAOreXgJqXuqsreeI"
 
 var principals = map[string]scim.Principal{
-	validAuthSessionId: {Id: "9bbbf1b6-017a-449a-ad5f-9723d28223e1"},
+	validAuthSessionId: {Schemas: []string{scim.SchemaUser}, Id: "9bbbf1b6-017a-449a-ad5f-9723d28223e1"},
 }
 
 const validExternalAuthSessionId = "1XGxJeb0q+/fS8biFi8FE7TovJPPEPyzlDxT6bh5p5pHA/x7CEi1w9egVhEMz8IWhrtvJRFnkSqJnLr61cOKf/i5eWuu7Duh+OTtTjMOt9w=&Cnh4NNU90wH_OVlgbzbdZOEu1aSuPlbUctiCdYTonZ3Ap_Zd3bVL79I-dPdHf4OOgO8NKEdqyLsqc8RhAOreXgJqXuqsreeI"
 
 var externalPrincipals = map[string]scim.Principal{
-	validExternalAuthSessionId: {Emails: []scim.UserValue{{"info@d-velop.de"}}, Groups: []scim.UserGroup{{Value: "3E093BE5-CCCE-435D-99F8-544656B98681"}}},
+	validExternalAuthSessionId: {Schemas: []string{scim.SchemaUser}, Emails: []scim.UserValue{{Value: "info@d-velop.de"}}, Groups: []scim.UserGroup{{Value: "3E093BE5-CCCE-435D-99F8-544656B98681"}}},
 }
 
 const invalidAuthSessionId = "2XGxJeb0q+/fS8biFi8FE7TovJPPEPyzlDxT6bh5p5pHA/x7CEi1w9egVhEMz8IWhrtvJRFnkSqJnLr61cOKf/i5eWuu7Duh+OTtTjMOt9w=&Dnh4NNU90wH_OVlgbzbdZOEu1aSuPlbUctiCdYTonZ3Ap_Zd3bVL79I-dPdHf4OOgO8NKEdqyLsqc8RhAOreXgJqXuqsreeI"
@@ -109,8 +114,19 @@ func TestIdpReturnsStatus500_Validate_ReturnsErrorAndNilPrincipal(t *testing.T)
 	if p != nil {
 		t.Errorf("Expected validate to return nil principal but got:\n %v", p)
 	}
-	if err == nil {
-		t.Error("Expected validate to return an error but error was nil")
+	var validateErr idpclient.IdpValidateError
+	if !errors.As(err, &validateErr) {
+		t.Fatalf("Expected validate to return an IdpValidateError but got %v", err)
+	}
+	if validateErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("got StatusCode %v, want %v", validateErr.StatusCode, http.StatusInternalServerError)
+	}
+	if validateErr.Endpoint != "/identityprovider/validate?allowExternalValidation=true" {
+		t.Errorf("got Endpoint '%v', want '%v'", validateErr.Endpoint, "/identityprovider/validate?allowExternalValidation=true")
+	}
+	var clientErr idpclient.IdpClientError
+	if !errors.As(err, &clientErr) || clientErr.Message != "a fatal error occurred\n" {
+		t.Errorf("Expected validate to wrap an IdpClientError with the response message but got %v", err)
 	}
 }
 
@@ -127,13 +143,20 @@ func TestIdpReturnsMalformedJson_Validate_ReturnsErrorAndNilPrincipal(t *testing
 	if p != nil {
 		t.Errorf("Expected validate to return nil principal but got:\n %v", p)
 	}
-	if err == nil {
-		t.Error("Expected validate to return an error but error was nil")
+	var validateErr idpclient.IdpValidateError
+	if !errors.As(err, &validateErr) {
+		t.Fatalf("Expected validate to return an IdpValidateError but got %v", err)
+	}
+	if validateErr.StatusCode != http.StatusOK {
+		t.Errorf("got StatusCode %v, want %v", validateErr.StatusCode, http.StatusOK)
+	}
+	if validateErr.Cause == nil {
+		t.Error("Expected the json decoding error as Cause but Cause was nil")
 	}
 }
 
 func TestPrincipalIsCachedAndCacheEntryIsNotExpired_Validate_ReturnsCachedEntry(t *testing.T) {
-	principal := scim.Principal{Id: "9bbbf1b6-017a-449a-ad5f-9723d28223e5"}
+	principal := scim.Principal{Schemas: []string{scim.SchemaUser}, Id: "9bbbf1b6-017a-449a-ad5f-9723d28223e5"}
 	const authSessionId = "11GxJeb0q+/fS8biFi8FE7TovJPPEPyzlDxT6bh5p5pHA/x7CEi1w9egVhEMz8IWhrtvJRFnkSqJnLr61cOKf/i5eWuu7Duh+OTtTjMOt9w=&Bnh4NNU90wH_OVlgbzbdZOEu1aSuPlbUctiCdYTonZ3Ap_Zd3bVL79I-dPdHf4OOgO8NKEdqyLsqc8RhAOreXgJqXuqsreeI"
 	idpCalled := 0
 	idpStub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -163,7 +186,7 @@ func TestPrincipalIsCachedAndCacheEntryIsNotExpired_Validate_ReturnsCachedEntry(
 }
 
 func TestPrincipalIsCachedButCacheEntryIsExpired_Validate_CallsIdp(t *testing.T) {
-	principal := scim.Principal{Id: "fffff1b6-017a-449a-ad5f-9723d28223e5"}
+	principal := scim.Principal{Schemas: []string{scim.SchemaUser}, Id: "fffff1b6-017a-449a-ad5f-9723d28223e5"}
 	const authSessionId = "22GxJeb0q+/fS8biFi8FE7TovJPPEPyzlDxT6bh5p5pHA/x7CEi1w9egVhEMz8IWhrtvJRFnkSqJnLr61cOKf/i5eWuu7Duh+OTtTjMOt9w=&Bnh4NNU90wH_OVlgbzbdZOEu1aSuPlbUctiCdYTonZ3Ap_Zd3bVL79I-dPdHf4OOgO8NKEdqyLsqc8RhAOreXgJqXuqsreeI"
 	idpCalled := 0
 	idpStub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -193,7 +216,7 @@ func TestPrincipalIsCachedButCacheEntryIsExpired_Validate_CallsIdp(t *testing.T)
 }
 
 func TestPrincipalIsCachedForDifferentTenant_Validate_CallsIdp(t *testing.T) {
-	principal := scim.Principal{Id: "9bbbf1b6-017a-449a-ad5f-9723d28223e5"}
+	principal := scim.Principal{Schemas: []string{scim.SchemaUser}, Id: "9bbbf1b6-017a-449a-ad5f-9723d28223e5"}
 	const authSessionId = "33GxJeb0q+/fS8biFi8FE7TovJPPEPyzlDxT6bh5p5pHA/x7CEi1w9egVhEMz8IWhrtvJRFnkSqJnLr61cOKf/i5eWuu7Duh+OTtTjMOt9w=&Bnh4NNU90wH_OVlgbzbdZOEu1aSuPlbUctiCdYTonZ3Ap_Zd3bVL79I-dPdHf4OOgO8NKEdqyLsqc8RhAOreXgJqXuqsreeI"
 	idpCalled := 0
 	idpStub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -223,7 +246,7 @@ func TestPrincipalIsCachedForDifferentTenant_Validate_CallsIdp(t *testing.T) {
 }
 
 func TestIdpSentsNoCacheHeader_Validate_CallsIdp(t *testing.T) {
-	principal := scim.Principal{Id: "fffff1b6-017a-449a-ad5f-9723d28223e5"}
+	principal := scim.Principal{Schemas: []string{scim.SchemaUser}, Id: "fffff1b6-017a-449a-ad5f-9723d28223e5"}
 	const authSessionId = "44GxJeb0q+/fS8biFi8FE7TovJPPEPyzlDxT6bh5p5pHA/x7CEi1w9egVhEMz8IWhrtvJRFnkSqJnLr61cOKf/i5eWuu7Duh+OTtTjMOt9w=&Bnh4NNU90wH_OVlgbzbdZOEu1aSuPlbUctiCdYTonZ3Ap_Zd3bVL79I-dPdHf4OOgO8NKEdqyLsqc8RhAOreXgJqXuqsreeI"
 	idpCalled := 0
 	idpStub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -251,7 +274,7 @@ func TestIdpSentsNoCacheHeader_Validate_CallsIdp(t *testing.T) {
 }
 
 func TestIdpSentsMaxAgeZero_Validate_CallsIdp(t *testing.T) {
-	principal := scim.Principal{Id: "fffff1b6-017a-449a-ad5f-9723d28223e5"}
+	principal := scim.Principal{Schemas: []string{scim.SchemaUser}, Id: "fffff1b6-017a-449a-ad5f-9723d28223e5"}
 	const authSessionId = "55GxJeb0q+/fS8biFi8FE7TovJPPEPyzlDxT6bh5p5pHA/x7CEi1w9egVhEMz8IWhrtvJRFnkSqJnLr61cOKf/i5eWuu7Duh+OTtTjMOt9w=&Bnh4NNU90wH_OVlgbzbdZOEu1aSuPlbUctiCdYTonZ3Ap_Zd3bVL79I-dPdHf4OOgO8NKEdqyLsqc8RhAOreXgJqXuqsreeI"
 	idpCalled := 0
 	idpStub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -280,7 +303,7 @@ func TestIdpSentsMaxAgeZero_Validate_CallsIdp(t *testing.T) {
 }
 
 func TestContextWithTimeoutAndRequestTimedOut_Validate_ReturnsTimeout(t *testing.T) {
-	principal := scim.Principal{Id: "fffff1b6-017a-449a-ad5f-9723d28223e5"}
+	principal := scim.Principal{Schemas: []string{scim.SchemaUser}, Id: "fffff1b6-017a-449a-ad5f-9723d28223e5"}
 	const authSessionId = "88GxJeb0q+/fS8biFi8FE7TovJPPEPyzlDxT6bh5p5pHA/x7CEi1w9egVhEMz8IWhrtvJRFnkSqJnLr61cOKf/i5eWuu7Duh+OTtTjMOt9w=&Bnh4NNU90wH_OVlgbzbdZOEu1aSuPlbUctiCdYTonZ3Ap_Zd3bVL79I-dPdHf4OOgO8NKEdqyLsqc8RhAOreXgJqXuqsreeI"
 	idpStub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		time.Sleep(20 * time.Millisecond)
@@ -294,10 +317,13 @@ func TestContextWithTimeoutAndRequestTimedOut_Validate_ReturnsTimeout(t *testing
 	defer cancel()
 
 	var urlError *url.Error
+	var validateErr idpclient.IdpValidateError
 	if _, err := defaultClient.Validate(ctxWithTimeout, idpStub.URL, "1", authSessionId); err == nil {
 		t.Error("Expected validate to return an *url.Error because of a timeout but validate didn't return an error")
 	} else if !(errors.As(err, &urlError) && urlError.Timeout()) {
 		t.Errorf("Expected validate to to return an *url.Error because of a timeout but validate returned another error %v", err)
+	} else if !errors.As(err, &validateErr) || validateErr.StatusCode != 0 {
+		t.Errorf("Expected validate to return an IdpValidateError with StatusCode 0 but got %v", err)
 	}
 }
 
@@ -382,9 +408,81 @@ func TestCustomPrincipalCacheSpecified_New_UsesCustomPrincipalCache(t *testing.T
 	}
 }
 
+func TestPrincipalIsCached_InvalidatePrincipal_ValidateCallsIdentityProviderAgain(t *testing.T) {
+	var calls int32
+	idpStub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Cache-Control", "max-age=1800, private")
+		_, _ = fmt.Fprint(w, `{"id":"9bbbf1b6-017a-449a-ad5f-9723d28223e1"}`)
+	}))
+	defer idpStub.Close()
+	client, err := idpclient.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = client.Validate(context.Background(), idpStub.URL, "1", validAuthSessionId)
+	_, _ = client.Validate(context.Background(), idpStub.URL, "1", validAuthSessionId)
+
+	client.InvalidatePrincipal("1", validAuthSessionId)
+	_, _ = client.Validate(context.Background(), idpStub.URL, "1", validAuthSessionId)
+
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("wrong number of calls to identityprovider: got %v want %v", got, 2)
+	}
+}
+
+func TestPrincipalIsValidatedTwice_CacheStats_CountsHitAndMiss(t *testing.T) {
+	idpStub := test.NewIdpValidateStub(principals, externalPrincipals)
+	defer idpStub.Close()
+	client, err := idpclient.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _ = client.Validate(context.Background(), idpStub.URL, "1", validAuthSessionId)
+	_, _ = client.Validate(context.Background(), idpStub.URL, "1", validAuthSessionId)
+
+	want := idpclient.CacheStats{Items: 1, HitCount: 1, MissCount: 1}
+	if got := client.CacheStats(); got != want {
+		t.Errorf("\ngot   :%+v\nwanted:%+v", got, want)
+	}
+}
+
+func TestCustomCacheWithoutItemCount_CacheStats_ReportsZeroItems(t *testing.T) {
+	idpStub := test.NewIdpValidateStub(principals, externalPrincipals)
+	defer idpStub.Close()
+	client, err := idpclient.New(idpclient.PrincipalCache(&PrincipalCacheSpy{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _ = client.Validate(context.Background(), idpStub.URL, "1", validAuthSessionId)
+
+	want := idpclient.CacheStats{Items: 0, HitCount: 0, MissCount: 1}
+	if got := client.CacheStats(); got != want {
+		t.Errorf("\ngot   :%+v\nwanted:%+v", got, want)
+	}
+}
+
+func TestLogoutCallbackSpecified_InvalidatePrincipal_CallsCallback(t *testing.T) {
+	var gotTenantId, gotAuthSessionId string
+	client, err := idpclient.New(idpclient.WithLogoutCallback(func(tenantId, authSessionId string) {
+		gotTenantId, gotAuthSessionId = tenantId, authSessionId
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client.InvalidatePrincipal("1", validAuthSessionId)
+
+	if gotTenantId != "1" || gotAuthSessionId != validAuthSessionId {
+		t.Errorf("callback called with wrong values: got '%v', '%v'", gotTenantId, gotAuthSessionId)
+	}
+}
+
 func TestCallerIsAuthorizedAndPrincipalExists_GetPrincipalById_ReturnsPrincipal(t *testing.T) {
 	const authSessionIdFromAuthorizedCaller = validAuthSessionId
-	existingPrincipal := scim.Principal{Id: "719052ec-0c46-4db4-9cc4-f57e6492d25d"}
+	existingPrincipal := scim.Principal{Schemas: []string{scim.SchemaUser}, Id: "719052ec-0c46-4db4-9cc4-f57e6492d25d"}
 	idpStub := test.NewIdpUsersStub(authSessionIdFromAuthorizedCaller, existingPrincipal)
 
 	got, err := defaultClient.GetPrincipalById(context.Background(), idpStub.URL, "1", authSessionIdFromAuthorizedCaller, existingPrincipal.Id)
@@ -473,3 +571,785 @@ func TestNonExplicitHandledReturnCodeWithEmptyResponseMsg_Validate_ReturnsError(
 		t.Error("expects an error of the idp")
 	}
 }
+
+func newGroups(n int) []scim.Group {
+	groups := make([]scim.Group, 0, n)
+	for i := 0; i < n; i++ {
+		groups = append(groups, scim.Group{
+			Id:          fmt.Sprintf("group-%d", i),
+			DisplayName: fmt.Sprintf("Group %d", i),
+			Members:     []scim.GroupMember{{Value: "719052ec-0c46-4db4-9cc4-f57e6492d25d", Display: "Hans Mustermann"}},
+		})
+	}
+	return groups
+}
+
+func TestCallerIsAuthorizedAndMoreGroupsThanPageSizeExist_GetGroups_ReturnsAllGroups(t *testing.T) {
+	const authSessionIdFromAuthorizedCaller = validAuthSessionId
+	existingGroups := newGroups(250)
+	idpStub := test.NewIdpGroupsStub(authSessionIdFromAuthorizedCaller, existingGroups)
+	defer idpStub.Close()
+
+	got, err := defaultClient.GetGroups(context.Background(), idpStub.URL, "1", authSessionIdFromAuthorizedCaller)
+
+	if err != nil {
+		t.Error(err)
+	}
+	if diff := cmp.Diff(existingGroups, got); diff != "" {
+		t.Errorf("\nexpected: %v\ngot     : %v", existingGroups, got)
+	}
+}
+
+func TestCallerIsAuthorizedAndNoGroupsExist_GetGroups_ReturnsEmptySlice(t *testing.T) {
+	const authSessionIdFromAuthorizedCaller = validAuthSessionId
+	idpStub := test.NewIdpGroupsStub(authSessionIdFromAuthorizedCaller, []scim.Group{})
+	defer idpStub.Close()
+
+	got, err := defaultClient.GetGroups(context.Background(), idpStub.URL, "1", authSessionIdFromAuthorizedCaller)
+
+	if err != nil {
+		t.Error(err)
+	}
+	if got == nil || len(got) != 0 {
+		t.Errorf("expected empty slice, got %v ", got)
+	}
+}
+
+func TestCallerIsAuthorized_GetGroupsPaged_ReturnsRequestedPage(t *testing.T) {
+	const authSessionIdFromAuthorizedCaller = validAuthSessionId
+	existingGroups := newGroups(10)
+	idpStub := test.NewIdpGroupsStub(authSessionIdFromAuthorizedCaller, existingGroups)
+	defer idpStub.Close()
+
+	got, err := defaultClient.GetGroupsPaged(context.Background(), idpStub.URL, "1", authSessionIdFromAuthorizedCaller, 3, 4)
+
+	if err != nil {
+		t.Error(err)
+	}
+	if diff := cmp.Diff(existingGroups[2:6], got); diff != "" {
+		t.Errorf("\nexpected: %v\ngot     : %v", existingGroups[2:6], got)
+	}
+}
+
+func TestCallerNotAuthorized_GetGroups_ReturnsError(t *testing.T) {
+	const authSessionIdFromUnauthorizedCaller = invalidAuthSessionId
+	const authSessionIdFromAuthorizedCaller = validAuthSessionId
+	idpStub := test.NewIdpGroupsStub(authSessionIdFromAuthorizedCaller, newGroups(1))
+	defer idpStub.Close()
+
+	got, err := defaultClient.GetGroups(context.Background(), idpStub.URL, "1", authSessionIdFromUnauthorizedCaller)
+
+	if err == nil || got != nil {
+		t.Error("expected an error because caller is not authorized to call Idp but got no error")
+	}
+}
+
+func TestIdpReturnsErrorStatusCode_GetGroupsPaged_ReturnsIdpClientError(t *testing.T) {
+	for _, statusCode := range []int{http.StatusForbidden, http.StatusInternalServerError} {
+		t.Run(strconv.Itoa(statusCode), func(t *testing.T) {
+			idpStub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, `{"msg":"error"}`, statusCode)
+			}))
+			defer idpStub.Close()
+
+			got, err := defaultClient.GetGroupsPaged(context.Background(), idpStub.URL, "1", validAuthSessionId, 1, 10)
+
+			var idpClientError idpclient.IdpClientError
+			if !errors.As(err, &idpClientError) || idpClientError.StatusCode != statusCode {
+				t.Errorf("expected IdpClientError with status code %v but got %v", statusCode, err)
+			}
+			if got != nil {
+				t.Errorf("expected nil but got %v", got)
+			}
+		})
+	}
+}
+
+func TestCallerIsAuthorized_ListPrincipals_SendsPaginationQueryAndReturnsListResponse(t *testing.T) {
+	var gotQuery url.Values
+	idpStub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/identityprovider/scim/users" {
+			http.Error(w, "", http.StatusNotFound)
+			return
+		}
+		gotQuery = r.URL.Query()
+		_, _ = fmt.Fprint(w, `{"totalResults":120,"startIndex":51,"itemsPerPage":2,"Resources":[{"id":"4711","displayName":"Hans Mustermann"},{"id":"0815","displayName":"Erika Musterfrau"}]}`)
+	}))
+	defer idpStub.Close()
+
+	got, err := defaultClient.ListPrincipals(context.Background(), idpStub.URL, "1", validAuthSessionId, 51, 2)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotQuery.Get("startIndex") != "51" || gotQuery.Get("count") != "2" {
+		t.Errorf("wrong query parameters: got %v", gotQuery)
+	}
+	want := &scim.ListResponse[scim.Principal]{
+		TotalResults: 120,
+		StartIndex:   51,
+		ItemsPerPage: 2,
+		Resources:    []scim.Principal{{Id: "4711", DisplayName: "Hans Mustermann"}, {Id: "0815", DisplayName: "Erika Musterfrau"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("\nexpected: %v\ngot     : %v", want, got)
+	}
+}
+
+func TestCountIsZero_ListPrincipals_UsesDefaultPageSize(t *testing.T) {
+	var gotCount string
+	idpStub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotCount = r.URL.Query().Get("count")
+		_, _ = fmt.Fprint(w, `{"totalResults":0,"startIndex":1,"itemsPerPage":0,"Resources":[]}`)
+	}))
+	defer idpStub.Close()
+
+	_, err := defaultClient.ListPrincipals(context.Background(), idpStub.URL, "1", validAuthSessionId, 1, 0)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotCount != "50" {
+		t.Errorf("wrong count: got %v want %v", gotCount, "50")
+	}
+}
+
+func TestCallerIsAuthorized_SearchPrincipals_SendsEncodedFilterAndReturnsResources(t *testing.T) {
+	var gotRawQuery, gotFilter string
+	idpStub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/identityprovider/scim/users" {
+			http.Error(w, "", http.StatusNotFound)
+			return
+		}
+		gotRawQuery = r.URL.RawQuery
+		gotFilter = r.URL.Query().Get("filter")
+		_, _ = fmt.Fprint(w, `{"totalResults":1,"startIndex":1,"itemsPerPage":1,"Resources":[{"id":"4711","displayName":"John Doe"}]}`)
+	}))
+	defer idpStub.Close()
+
+	got, err := defaultClient.SearchPrincipals(context.Background(), idpStub.URL, "1", validAuthSessionId, `displayName co "John&count=1000"`)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotRawQuery != "filter=displayName+co+%22John%26count%3D1000%22" {
+		t.Errorf("wrong query: got '%v'", gotRawQuery)
+	}
+	if gotFilter != `displayName co "John&count=1000"` {
+		t.Errorf("wrong filter: got '%v'", gotFilter)
+	}
+	want := []scim.Principal{{Id: "4711", DisplayName: "John Doe"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("\nexpected: %v\ngot     : %v", want, got)
+	}
+}
+
+func TestFilterContainsControlCharacters_SearchPrincipals_ReturnsError(t *testing.T) {
+	for _, filter := range []string{"", "userName eq \"john\"\r\nX-Injected: true", "userName eq \"john\x00\""} {
+		got, err := defaultClient.SearchPrincipals(context.Background(), "http://idp.example.invalid", "1", validAuthSessionId, filter)
+
+		if err == nil || got != nil {
+			t.Errorf("expected an error for filter %q but got %v", filter, got)
+		}
+	}
+}
+
+func TestIdpReturnsErrorStatusCode_SearchPrincipals_ReturnsIdpClientError(t *testing.T) {
+	for _, statusCode := range []int{http.StatusBadRequest, http.StatusForbidden, http.StatusInternalServerError} {
+		t.Run(strconv.Itoa(statusCode), func(t *testing.T) {
+			idpStub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, `{"detail":"error"}`, statusCode)
+			}))
+			defer idpStub.Close()
+
+			got, err := defaultClient.SearchPrincipals(context.Background(), idpStub.URL, "1", validAuthSessionId, `displayName co "John"`)
+
+			var idpClientError idpclient.IdpClientError
+			if !errors.As(err, &idpClientError) || idpClientError.StatusCode != statusCode {
+				t.Errorf("expected IdpClientError with status code %v but got %v", statusCode, err)
+			}
+			if got != nil {
+				t.Errorf("expected nil but got %v", got)
+			}
+		})
+	}
+}
+
+func TestIdpReturnsErrorStatusCode_ListPrincipals_ReturnsIdpClientError(t *testing.T) {
+	for _, statusCode := range []int{http.StatusForbidden, http.StatusInternalServerError} {
+		t.Run(strconv.Itoa(statusCode), func(t *testing.T) {
+			idpStub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "a fatal error occurred", statusCode)
+			}))
+			defer idpStub.Close()
+
+			got, err := defaultClient.ListPrincipals(context.Background(), idpStub.URL, "1", validAuthSessionId, 1, 10)
+
+			var idpClientError idpclient.IdpClientError
+			if !errors.As(err, &idpClientError) || idpClientError.StatusCode != statusCode {
+				t.Errorf("expected IdpClientError with status code %v but got %v", statusCode, err)
+			}
+			if got != nil {
+				t.Errorf("expected nil but got %v", got)
+			}
+		})
+	}
+}
+
+func TestPrincipalExists_UpdatePrincipal_SendsPatchRequestAndReturnsUpdatedPrincipal(t *testing.T) {
+	updatedPrincipal := scim.Principal{Schemas: []string{scim.SchemaUser, scim.SchemaEnterpriseUser}, Id: "719052ec-0c46-4db4-9cc4-f57e6492d25d", Enterprise: &scim.EnterpriseExtension{Department: "Tour Operations"}}
+	patch := scim.NewPatchRequest(scim.PatchOperation{Op: scim.PatchOpReplace, Path: "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department", Value: "Tour Operations"})
+	var method, path, authorization string
+	var body []byte
+	idpStub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path, authorization = r.Method, r.URL.Path, r.Header.Get("Authorization")
+		body, _ = ioutil.ReadAll(r.Body)
+		_ = json.NewEncoder(w).Encode(updatedPrincipal)
+	}))
+	defer idpStub.Close()
+
+	got, err := defaultClient.UpdatePrincipal(context.Background(), idpStub.URL, "1", validAuthSessionId, updatedPrincipal.Id, patch)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if method != http.MethodPatch {
+		t.Errorf("\nexpected: %v\ngot     : %v", http.MethodPatch, method)
+	}
+	if path != "/identityprovider/scim/users/"+updatedPrincipal.Id {
+		t.Errorf("\nexpected: %v\ngot     : %v", "/identityprovider/scim/users/"+updatedPrincipal.Id, path)
+	}
+	if authorization != "Bearer "+validAuthSessionId {
+		t.Errorf("\nexpected: %v\ngot     : %v", "Bearer "+validAuthSessionId, authorization)
+	}
+	expectedBody, _ := json.Marshal(patch)
+	if !bytes.Equal(bytes.TrimSpace(body), expectedBody) {
+		t.Errorf("\nexpected: %s\ngot     : %s", expectedBody, body)
+	}
+	if diff := cmp.Diff(&updatedPrincipal, got); diff != "" {
+		t.Errorf("\nexpected: %v\ngot     : %v", &updatedPrincipal, got)
+	}
+}
+
+func TestPrincipalIdWithReservedCharacters_UpdatePrincipal_EscapesPrincipalId(t *testing.T) {
+	var path string
+	idpStub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		_, _ = fmt.Fprint(w, `{"id":"4711"}`)
+	}))
+	defer idpStub.Close()
+
+	_, err := defaultClient.UpdatePrincipal(context.Background(), idpStub.URL, "1", validAuthSessionId, "../groups/4711?x=1", scim.NewPatchRequest())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/identityprovider/scim/users/..%2Fgroups%2F4711%3Fx=1" {
+		t.Errorf("\nexpected: %v\ngot     : %v", "/identityprovider/scim/users/..%2Fgroups%2F4711%3Fx=1", path)
+	}
+}
+
+func TestPrincipalDoesntExist_UpdatePrincipal_ReturnsNilAndError(t *testing.T) {
+	idpStub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "", http.StatusNotFound)
+	}))
+	defer idpStub.Close()
+
+	got, err := defaultClient.UpdatePrincipal(context.Background(), idpStub.URL, "1", validAuthSessionId, "83db85b2-89d3-4586-b455-ad041ff38195", scim.NewPatchRequest())
+
+	var idpErr idpclient.IdpClientError
+	if !errors.As(err, &idpErr) || idpErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected IdpClientError with status code 404 but got %v", err)
+	}
+	if got != nil {
+		t.Errorf("expected principal value nil, got %v ", got)
+	}
+}
+
+func TestIdpReturnsUnexpectedStatusCode_UpdatePrincipal_ReturnsIdpClientError(t *testing.T) {
+	idpStub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "error", http.StatusConflict)
+	}))
+	defer idpStub.Close()
+
+	got, err := defaultClient.UpdatePrincipal(context.Background(), idpStub.URL, "1", validAuthSessionId, "719052ec-0c46-4db4-9cc4-f57e6492d25d", scim.NewPatchRequest())
+
+	var idpErr idpclient.IdpClientError
+	if !errors.As(err, &idpErr) || idpErr.StatusCode != http.StatusConflict {
+		t.Errorf("expected IdpClientError with status code 409 but got %v", err)
+	}
+	if got != nil {
+		t.Errorf("expected principal value nil, got %v ", got)
+	}
+}
+
+type MetricsSpy struct {
+	CacheHits   int
+	CacheMisses int
+	IdpCalls    []string
+}
+
+func (m *MetricsSpy) RecordCacheHit() {
+	m.CacheHits++
+}
+
+func (m *MetricsSpy) RecordCacheMiss() {
+	m.CacheMisses++
+}
+
+func (m *MetricsSpy) RecordIdpCallDuration(duration time.Duration, endpoint string, statusCode int) {
+	m.IdpCalls = append(m.IdpCalls, fmt.Sprintf("%s %d", endpoint, statusCode))
+}
+
+func TestMetricsSpecifiedAndPrincipalIsCachedAfterFirstCall_ValidateTwice_RecordsOneMissOneHitAndOneIdpCall(t *testing.T) {
+	idpStub := test.NewIdpValidateStub(principals, externalPrincipals)
+	defer idpStub.Close()
+	spy := &MetricsSpy{}
+	client, _ := idpclient.New(idpclient.WithMetrics(spy))
+
+	_, _ = client.Validate(context.Background(), idpStub.URL, "1", validAuthSessionId)
+	_, _ = client.Validate(context.Background(), idpStub.URL, "1", validAuthSessionId)
+
+	if spy.CacheMisses != 1 {
+		t.Errorf("wrong number of cache misses: got %v want %v", spy.CacheMisses, 1)
+	}
+	if spy.CacheHits != 1 {
+		t.Errorf("wrong number of cache hits: got %v want %v", spy.CacheHits, 1)
+	}
+	if !reflect.DeepEqual(spy.IdpCalls, []string{"/identityprovider/validate 200"}) {
+		t.Errorf("wrong idp calls recorded: got %v want %v", spy.IdpCalls, []string{"/identityprovider/validate 200"})
+	}
+}
+
+func TestMetricsSpecifiedAndIdpReturnsStatus500_GetPrincipalById_RecordsIdpCallWithStatusCode(t *testing.T) {
+	idpStub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "a fatal error occurred", http.StatusInternalServerError)
+	}))
+	defer idpStub.Close()
+	spy := &MetricsSpy{}
+	client, _ := idpclient.New(idpclient.WithMetrics(spy))
+
+	_, _ = client.GetPrincipalById(context.Background(), idpStub.URL, "1", validAuthSessionId, "4711")
+
+	if !reflect.DeepEqual(spy.IdpCalls, []string{"/identityprovider/scim/users/{id} 500"}) {
+		t.Errorf("wrong idp calls recorded: got %v want %v", spy.IdpCalls, []string{"/identityprovider/scim/users/{id} 500"})
+	}
+}
+
+func TestMetricsSpecified_GetGroupById_RecordsIdpCallWithoutGroupId(t *testing.T) {
+	idpStub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "group not found", http.StatusNotFound)
+	}))
+	defer idpStub.Close()
+	spy := &MetricsSpy{}
+	client, _ := idpclient.New(idpclient.WithMetrics(spy))
+
+	_, _ = client.GetGroupById(context.Background(), idpStub.URL, "1", validAuthSessionId, "3E093BE5-CCCE-435D-99F8-544656B98681")
+
+	if !reflect.DeepEqual(spy.IdpCalls, []string{"/identityprovider/scim/groups/{id} 404"}) {
+		t.Errorf("wrong idp calls recorded: got %v want %v", spy.IdpCalls, []string{"/identityprovider/scim/groups/{id} 404"})
+	}
+}
+
+func TestPrintMetrics_RecordIdpCallDuration_PrintsToWriter(t *testing.T) {
+	var buf bytes.Buffer
+	m := idpclient.PrintMetrics{Writer: &buf}
+
+	m.RecordCacheHit()
+	m.RecordCacheMiss()
+	m.RecordIdpCallDuration(20*time.Millisecond, "/identityprovider/validate", http.StatusOK)
+
+	expected := "idpclient cache hit\nidpclient cache miss\nidpclient call endpoint='/identityprovider/validate' statusCode='200' duration='20ms'\n"
+	if buf.String() != expected {
+		t.Errorf("\ngot   :'%v'\nwanted:'%v'", buf.String(), expected)
+	}
+}
+
+func TestProxySpecified_Validate_RoutesRequestThroughProxy(t *testing.T) {
+	var requestedHost string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedHost = r.URL.Host
+		_, _ = fmt.Fprint(w, `{"id":"9bbbf1b6-017a-449a-ad5f-9723d28223e1"}`)
+	}))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+	client, err := idpclient.New(idpclient.WithProxy(proxyURL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := client.Validate(context.Background(), "http://idp.example.invalid", "1", validAuthSessionId)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requestedHost != "idp.example.invalid" {
+		t.Errorf("request hasn't been routed through proxy: got host '%v' want '%v'", requestedHost, "idp.example.invalid")
+	}
+	if p == nil || p.Id != "9bbbf1b6-017a-449a-ad5f-9723d28223e1" {
+		t.Errorf("wrong principal %v", p)
+	}
+}
+
+func TestProxyAndCustomHttpClientSpecified_New_AppliesProxyWithoutChangingCustomHttpClient(t *testing.T) {
+	var proxyCalled bool
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxyCalled = true
+		_, _ = fmt.Fprint(w, `{"id":"9bbbf1b6-017a-449a-ad5f-9723d28223e1"}`)
+	}))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+	transport := &http.Transport{}
+	client, err := idpclient.New(idpclient.HttpClient(&http.Client{Transport: transport}), idpclient.WithProxy(proxyURL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _ = client.Validate(context.Background(), "http://idp.example.invalid", "1", validAuthSessionId)
+
+	if !proxyCalled {
+		t.Error("request hasn't been routed through proxy")
+	}
+	if transport.Proxy != nil {
+		t.Error("transport of custom http client has been changed")
+	}
+}
+
+func TestProxyAndHttpClientWithCustomRoundTripper_New_ReturnsError(t *testing.T) {
+	proxyURL, _ := url.Parse("http://proxy.example.invalid")
+
+	_, err := idpclient.New(idpclient.HttpClient(&http.Client{Transport: &RoundTripperSpy{}}), idpclient.WithProxy(proxyURL))
+
+	if err == nil {
+		t.Error("expected an error but got nil")
+	}
+}
+
+func TestTLSConfigTrustsServerCertificate_Validate_ReturnsPrincipal(t *testing.T) {
+	idpStub := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"id":"9bbbf1b6-017a-449a-ad5f-9723d28223e1"}`)
+	}))
+	defer idpStub.Close()
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(idpStub.Certificate())
+	client, err := idpclient.New(idpclient.WithTLSConfig(&tls.Config{RootCAs: rootCAs}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := client.Validate(context.Background(), idpStub.URL, "1", validAuthSessionId)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p == nil || p.Id != "9bbbf1b6-017a-449a-ad5f-9723d28223e1" {
+		t.Errorf("wrong principal %v", p)
+	}
+}
+
+func TestNoTLSConfigAndSelfSignedServerCertificate_Validate_ReturnsError(t *testing.T) {
+	idpStub := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"id":"9bbbf1b6-017a-449a-ad5f-9723d28223e1"}`)
+	}))
+	defer idpStub.Close()
+
+	client, _ := idpclient.New()
+
+	_, err := client.Validate(context.Background(), idpStub.URL, "1", validAuthSessionId)
+
+	if err == nil {
+		t.Error("expected an error because the certificate is not trusted but got nil")
+	}
+}
+
+func TestTLSConfigAndCustomHttpClient_New_ReturnsError(t *testing.T) {
+	_, err := idpclient.New(idpclient.HttpClient(&http.Client{}), idpclient.WithTLSConfig(&tls.Config{}))
+
+	if err == nil {
+		t.Error("expected an error but got nil")
+	}
+}
+
+func newIdpGroupStub(t *testing.T, statusCode int, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/identityprovider/scim/groups/759eaed7" {
+			t.Errorf("wrong path '%v'", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer "+validAuthSessionId {
+			t.Errorf("wrong Authorization header '%v'", got)
+		}
+		w.WriteHeader(statusCode)
+		_, _ = fmt.Fprint(w, body)
+	}))
+}
+
+func TestGroupExists_GetGroupById_ReturnsGroup(t *testing.T) {
+	idpStub := newIdpGroupStub(t, http.StatusOK, `{"id":"759eaed7","displayName":"Scrum People","members":[{"value":"146bc69e","display":"Donald Duck"}]}`)
+	defer idpStub.Close()
+
+	got, err := defaultClient.GetGroupById(context.Background(), idpStub.URL, "1", validAuthSessionId, "759eaed7")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &scim.Group{Id: "759eaed7", DisplayName: "Scrum People", Members: []scim.GroupMember{{Value: "146bc69e", Display: "Donald Duck"}}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("\nexpected: %v\ngot     : %v", want, got)
+	}
+}
+
+func TestGroupDoesntExist_GetGroupById_ReturnsNil(t *testing.T) {
+	idpStub := newIdpGroupStub(t, http.StatusNotFound, "")
+	defer idpStub.Close()
+
+	got, err := defaultClient.GetGroupById(context.Background(), idpStub.URL, "1", validAuthSessionId, "759eaed7")
+
+	if err != nil || got != nil {
+		t.Errorf("expected nil, nil but got %v, %v", got, err)
+	}
+}
+
+func TestGroupIdWithReservedCharacters_GetGroupById_EscapesGroupId(t *testing.T) {
+	var path string
+	idpStub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		http.Error(w, "", http.StatusNotFound)
+	}))
+	defer idpStub.Close()
+
+	_, err := defaultClient.GetGroupById(context.Background(), idpStub.URL, "1", validAuthSessionId, "../users/4711?x=1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/identityprovider/scim/groups/..%2Fusers%2F4711%3Fx=1" {
+		t.Errorf("\nexpected: %v\ngot     : %v", "/identityprovider/scim/groups/..%2Fusers%2F4711%3Fx=1", path)
+	}
+}
+
+func TestIdpReturnsErrorStatusCode_GetGroupById_ReturnsIdpClientError(t *testing.T) {
+	for _, statusCode := range []int{http.StatusForbidden, http.StatusInternalServerError} {
+		t.Run(strconv.Itoa(statusCode), func(t *testing.T) {
+			idpStub := newIdpGroupStub(t, statusCode, `{"msg":"error"}`)
+			defer idpStub.Close()
+
+			got, err := defaultClient.GetGroupById(context.Background(), idpStub.URL, "1", validAuthSessionId, "759eaed7")
+
+			var idpClientError idpclient.IdpClientError
+			if !errors.As(err, &idpClientError) || idpClientError.StatusCode != statusCode {
+				t.Errorf("expected IdpClientError with status code %v but got %v", statusCode, err)
+			}
+			if got != nil {
+				t.Errorf("expected nil but got %v", got)
+			}
+		})
+	}
+}
+
+func newIdpPrincipalHeadStub(t *testing.T, statusCode int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("wrong method '%v'", r.Method)
+		}
+		if r.URL.Path != "/identityprovider/scim/users/146bc69e" {
+			t.Errorf("wrong path '%v'", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer "+validAuthSessionId {
+			t.Errorf("wrong Authorization header '%v'", got)
+		}
+		w.WriteHeader(statusCode)
+	}))
+}
+
+func TestPrincipalExists_PrincipalExists_ReturnsTrue(t *testing.T) {
+	idpStub := newIdpPrincipalHeadStub(t, http.StatusOK)
+	defer idpStub.Close()
+
+	got, err := defaultClient.PrincipalExists(context.Background(), idpStub.URL, "1", validAuthSessionId, "146bc69e")
+
+	if err != nil || !got {
+		t.Errorf("expected true, nil but got %v, %v", got, err)
+	}
+}
+
+func TestPrincipalDoesntExist_PrincipalExists_ReturnsFalse(t *testing.T) {
+	idpStub := newIdpPrincipalHeadStub(t, http.StatusNotFound)
+	defer idpStub.Close()
+
+	got, err := defaultClient.PrincipalExists(context.Background(), idpStub.URL, "1", validAuthSessionId, "146bc69e")
+
+	if err != nil || got {
+		t.Errorf("expected false, nil but got %v, %v", got, err)
+	}
+}
+
+func TestPrincipalIdWithReservedCharacters_PrincipalExists_EscapesPrincipalId(t *testing.T) {
+	var path string
+	idpStub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer idpStub.Close()
+
+	_, err := defaultClient.PrincipalExists(context.Background(), idpStub.URL, "1", validAuthSessionId, "../groups/4711?x=1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/identityprovider/scim/users/..%2Fgroups%2F4711%3Fx=1" {
+		t.Errorf("\nexpected: %v\ngot     : %v", "/identityprovider/scim/users/..%2Fgroups%2F4711%3Fx=1", path)
+	}
+}
+
+func TestIdpReturnsErrorStatusCode_PrincipalExists_ReturnsIdpClientError(t *testing.T) {
+	for _, statusCode := range []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusInternalServerError} {
+		t.Run(strconv.Itoa(statusCode), func(t *testing.T) {
+			idpStub := newIdpPrincipalHeadStub(t, statusCode)
+			defer idpStub.Close()
+
+			got, err := defaultClient.PrincipalExists(context.Background(), idpStub.URL, "1", validAuthSessionId, "146bc69e")
+
+			var idpClientError idpclient.IdpClientError
+			if !errors.As(err, &idpClientError) || idpClientError.StatusCode != statusCode {
+				t.Errorf("expected IdpClientError with status code %v but got %v", statusCode, err)
+			}
+			if got {
+				t.Error("expected false but got true")
+			}
+		})
+	}
+}
+
+func TestWithTransport_New_UsesTransportWithConnectionPoolSettings(t *testing.T) {
+	client, err := idpclient.New(idpclient.WithTransport(100, 20, 30*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transport, ok := idpclient.HttpClientOf(client).Transport.(*http.Transport)
+
+	if !ok {
+		t.Fatalf("got transport %T, want *http.Transport", idpclient.HttpClientOf(client).Transport)
+	}
+	if transport.MaxIdleConns != 100 || transport.MaxIdleConnsPerHost != 100 {
+		t.Errorf("got MaxIdleConns %v and MaxIdleConnsPerHost %v, want 100", transport.MaxIdleConns, transport.MaxIdleConnsPerHost)
+	}
+	if transport.MaxConnsPerHost != 20 {
+		t.Errorf("got MaxConnsPerHost %v, want 20", transport.MaxConnsPerHost)
+	}
+	if transport.IdleConnTimeout != 30*time.Second {
+		t.Errorf("got IdleConnTimeout %v, want %v", transport.IdleConnTimeout, 30*time.Second)
+	}
+	if transport == http.DefaultTransport {
+		t.Error("http.DefaultTransport should not have been modified")
+	}
+}
+
+func TestWithTransportAndCustomHttpClient_New_ReturnsError(t *testing.T) {
+	_, err := idpclient.New(idpclient.HttpClient(&http.Client{}), idpclient.WithTransport(100, 20, 30*time.Second))
+
+	if err == nil {
+		t.Error("expected an error but got nil")
+	}
+}
+
+func newIdpPrincipalsStub(existingIds ...string) *httptest.Server {
+	existing := map[string]bool{}
+	for _, id := range existingIds {
+		existing[id] = true
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/identityprovider/scim/users/")
+		if !existing[id] {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(scim.Principal{Id: id})
+	}))
+}
+
+func TestAllPrincipalsExist_GetPrincipalsByIds_ReturnsAllPrincipals(t *testing.T) {
+	ids := []string{"719052ec", "83db85b2", "9bbbf1b6"}
+	idpStub := newIdpPrincipalsStub(ids...)
+	defer idpStub.Close()
+
+	got, err := defaultClient.GetPrincipalsByIds(context.Background(), idpStub.URL, "1", validAuthSessionId, ids)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(ids) {
+		t.Errorf("got %v principals, want %v", len(got), len(ids))
+	}
+	for _, id := range ids {
+		if p := got[id]; p == nil || p.Id != id {
+			t.Errorf("got principal %v for id '%v'", p, id)
+		}
+	}
+}
+
+func TestSomePrincipalsDontExist_GetPrincipalsByIds_ReturnsNilForMissingPrincipals(t *testing.T) {
+	idpStub := newIdpPrincipalsStub("719052ec")
+	defer idpStub.Close()
+
+	got, err := defaultClient.GetPrincipalsByIds(context.Background(), idpStub.URL, "1", validAuthSessionId, []string{"719052ec", "83db85b2", "719052ec"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Errorf("got %v entries, want 2", len(got))
+	}
+	if p := got["719052ec"]; p == nil || p.Id != "719052ec" {
+		t.Errorf("got principal %v for existing id", p)
+	}
+	if p, ok := got["83db85b2"]; !ok || p != nil {
+		t.Errorf("expected nil entry for missing principal but got %v (present: %v)", p, ok)
+	}
+}
+
+func TestIdpReturnsStatus500_GetPrincipalsByIds_ReturnsError(t *testing.T) {
+	idpStub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "a fatal error occurred", http.StatusInternalServerError)
+	}))
+	defer idpStub.Close()
+
+	got, err := defaultClient.GetPrincipalsByIds(context.Background(), idpStub.URL, "1", validAuthSessionId, []string{"719052ec", "83db85b2"})
+
+	if err == nil {
+		t.Error("expected an error but got nil")
+	}
+	if got != nil {
+		t.Errorf("expected nil map but got %v", got)
+	}
+}
+
+func TestMaxConcurrentRequests_GetPrincipalsByIds_LimitsConcurrentRequests(t *testing.T) {
+	var inFlight, maxInFlight int32
+	idpStub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer idpStub.Close()
+	client, err := idpclient.New(idpclient.WithMaxConcurrentRequests(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.GetPrincipalsByIds(context.Background(), idpStub.URL, "1", validAuthSessionId, []string{"a", "b", "c", "d", "e", "f"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if maxInFlight > 2 {
+		t.Errorf("got %v concurrent requests, want at most 2", maxInFlight)
+	}
+}
@@ -0,0 +1,8 @@
+package idpclient
+
+import "net/http"
+
+// HttpClientOf returns the http.Client which is used by c.
+func HttpClientOf(c *client) *http.Client {
+	return c.httpClient
+}
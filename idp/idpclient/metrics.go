@@ -0,0 +1,74 @@
+package idpclient
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Metrics is an interface representing the ability to record metrics about
+// the principal cache and the calls to the IdentityProvider-App
+type Metrics interface {
+	// RecordCacheHit is called if a principal has been found in the principal cache.
+	RecordCacheHit()
+
+	// RecordCacheMiss is called if a principal has not been found in the principal cache.
+	RecordCacheMiss()
+
+	// RecordIdpCallDuration is called after each http call to the IdentityProvider-App.
+	// The endpoint is the path of the called resource without query parameters and with ids
+	// replaced by {id}, e.g. /identityprovider/scim/users/{id}.
+	// The statusCode is 0 if the call failed without a response e.g. due to a timeout.
+	RecordIdpCallDuration(duration time.Duration, endpoint string, statusCode int)
+}
+
+// WithMetrics sets the Metrics which should be used to record cache hits, cache misses
+// and the duration of calls to the IdentityProvider-App
+func WithMetrics(m Metrics) Option {
+	return func(c *client) error {
+		c.metrics = m
+		return nil
+	}
+}
+
+// resourcesWithId are the paths of the resources of the IdentityProvider-App which are called with an id
+var resourcesWithId = []string{"/identityprovider/scim/users/", "/identityprovider/scim/groups/"}
+
+// metricsEndpoint returns path with the id of the resource replaced by {id}, so that the number
+// of endpoints recorded by RecordIdpCallDuration doesn't grow with the number of principals and groups.
+func metricsEndpoint(path string) string {
+	for _, r := range resourcesWithId {
+		if strings.HasPrefix(path, r) && len(path) > len(r) {
+			return r + "{id}"
+		}
+	}
+	return path
+}
+
+// NopMetrics is a Metrics implementation which discards all recordings.
+type NopMetrics struct{}
+
+func (NopMetrics) RecordCacheHit() {}
+
+func (NopMetrics) RecordCacheMiss() {}
+
+func (NopMetrics) RecordIdpCallDuration(duration time.Duration, endpoint string, statusCode int) {}
+
+// PrintMetrics is a Metrics implementation which prints all recordings to Writer.
+// It is meant for debugging purposes.
+type PrintMetrics struct {
+	Writer io.Writer
+}
+
+func (m PrintMetrics) RecordCacheHit() {
+	_, _ = fmt.Fprintln(m.Writer, "idpclient cache hit")
+}
+
+func (m PrintMetrics) RecordCacheMiss() {
+	_, _ = fmt.Fprintln(m.Writer, "idpclient cache miss")
+}
+
+func (m PrintMetrics) RecordIdpCallDuration(duration time.Duration, endpoint string, statusCode int) {
+	_, _ = fmt.Fprintf(m.Writer, "idpclient call endpoint='%s' statusCode='%d' duration='%v'\n", endpoint, statusCode, duration)
+}
@@ -0,0 +1,151 @@
+package idpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+
+	"github.com/d-velop/dvelop-sdk-go/idp/scim"
+)
+
+const (
+	defaultValidateEndpoint = "/identityprovider/validate?allowExternalValidation=true"
+	oidcDiscoveryEndpoint   = "/.well-known/openid-configuration"
+	// defaultDiscoveryCacheDuration is the duration the discovery document is cached if its response has no max-age
+	defaultDiscoveryCacheDuration = 5 * time.Minute
+)
+
+// WithOIDCDiscovery lets Validate call the userinfo_endpoint of the OpenID Connect discovery document
+// of the IdentityProvider-App instead of /identityprovider/validate.
+//
+// The discovery document is fetched from /.well-known/openid-configuration of the systemBaseUri before
+// the first validation for this systemBaseUri. It's cached as long as the max-age of its Cache-Control header
+// specifies, or 5 minutes if there is no max-age, and fetched again afterwards. Like the default endpoint
+// the userinfo_endpoint is called with the query parameter allowExternalValidation=true.
+//
+// The authSessionId is sent to the userinfo_endpoint, so Validate returns an error if the userinfo_endpoint
+// has another scheme or host than the systemBaseUri.
+//
+// The userinfo_endpoint returns OpenID Connect claims instead of a SCIM user. The claims sub, name, given_name,
+// family_name, preferred_username, email and groups are mapped to the Id, DisplayName, Name, UserName, Emails
+// and Groups of the returned scim.Principal. A response without sub claim is an error.
+func WithOIDCDiscovery() Option {
+	return func(c *client) error {
+		c.discoveryCache = cache.New(cache.DefaultExpiration, 5*time.Minute)
+		return nil
+	}
+}
+
+type oidcDiscoveryDocument struct {
+	UserinfoEndpoint string `json:"userinfo_endpoint"`
+}
+
+// userinfoClaims are the claims returned by the userinfo_endpoint
+// cf. https://openid.net/specs/openid-connect-core-1_0.html#StandardClaims
+type userinfoClaims struct {
+	Sub               string   `json:"sub"`
+	Name              string   `json:"name"`
+	GivenName         string   `json:"given_name"`
+	FamilyName        string   `json:"family_name"`
+	PreferredUsername string   `json:"preferred_username"`
+	Email             string   `json:"email"`
+	Groups            []string `json:"groups"`
+}
+
+func (u userinfoClaims) principal() scim.Principal {
+	p := scim.Principal{
+		Id:          u.Sub,
+		UserName:    u.PreferredUsername,
+		DisplayName: u.Name,
+		Name:        scim.UserName{GivenName: u.GivenName, FamilyName: u.FamilyName},
+	}
+	if u.Email != "" {
+		p.Emails = []scim.UserValue{{Value: u.Email}}
+	}
+	for _, g := range u.Groups {
+		p.Groups = append(p.Groups, scim.UserGroup{Value: g})
+	}
+	return p
+}
+
+// decodePrincipal decodes the response of the endpoint returned by validateEndpoint.
+// The default endpoint returns a SCIM user, the userinfo_endpoint returns the claims of the user.
+func (c *client) decodePrincipal(r io.Reader) (scim.Principal, error) {
+	if c.discoveryCache == nil {
+		var p scim.Principal
+		err := json.NewDecoder(r).Decode(&p)
+		return p, err
+	}
+	var u userinfoClaims
+	if err := json.NewDecoder(r).Decode(&u); err != nil {
+		return scim.Principal{}, err
+	}
+	if u.Sub == "" {
+		return scim.Principal{}, errors.New("sub claim is missing")
+	}
+	return u.principal(), nil
+}
+
+// validateEndpoint returns the endpoint which is called by Validate for the systemBaseUri.
+// Errors are returned as IdpValidateError.
+func (c *client) validateEndpoint(ctx context.Context, systemBaseUri string) (string, error) {
+	if c.discoveryCache == nil {
+		return defaultValidateEndpoint, nil
+	}
+	if e, found := c.discoveryCache.Get(systemBaseUri); found {
+		return e.(string), nil
+	}
+
+	endpoint := oidcDiscoveryEndpoint
+	resp, doErr := c.httpGet(ctx, systemBaseUri, "", endpoint)
+	if doErr != nil {
+		return "", IdpValidateError{Endpoint: endpoint, Cause: doErr}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", IdpValidateError{StatusCode: resp.StatusCode, Endpoint: endpoint, Cause: newIdpClientError(resp)}
+	}
+	var d oidcDiscoveryDocument
+	if err := json.NewDecoder(resp.Body).Decode(&d); err != nil {
+		return "", IdpValidateError{StatusCode: resp.StatusCode, Endpoint: endpoint, Cause: err}
+	}
+	_, _ = ioutil.ReadAll(resp.Body)
+	if d.UserinfoEndpoint == "" {
+		return "", IdpValidateError{StatusCode: resp.StatusCode, Endpoint: endpoint, Cause: errors.New("userinfo_endpoint is missing")}
+	}
+	u, err := url.Parse(d.UserinfoEndpoint)
+	if err != nil {
+		return "", IdpValidateError{StatusCode: resp.StatusCode, Endpoint: endpoint, Cause: fmt.Errorf("userinfo_endpoint is no valid URL: %w", err)}
+	}
+	base, err := url.Parse(systemBaseUri)
+	if err != nil {
+		return "", IdpValidateError{Endpoint: endpoint, Cause: err}
+	}
+	u = base.ResolveReference(u)
+	if !strings.EqualFold(u.Scheme, base.Scheme) || !strings.EqualFold(u.Host, base.Host) {
+		return "", IdpValidateError{StatusCode: resp.StatusCode, Endpoint: endpoint, Cause: fmt.Errorf("userinfo_endpoint '%v' doesn't belong to the systemBaseUri '%v'", d.UserinfoEndpoint, systemBaseUri)}
+	}
+	q := u.Query()
+	q.Set("allowExternalValidation", "true")
+	u.RawQuery = q.Encode()
+
+	userinfoEndpoint := u.String()
+	validFor := defaultDiscoveryCacheDuration
+	if cacheControl := resp.Header.Get("Cache-Control"); maxAgeRegex.MatchString(cacheControl) {
+		validFor = maxAge(cacheControl)
+	}
+	if validFor > 0 {
+		c.discoveryCache.Set(systemBaseUri, userinfoEndpoint, validFor)
+	}
+	return userinfoEndpoint, nil
+}
@@ -0,0 +1,241 @@
+package idpclient_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/d-velop/dvelop-sdk-go/idp/idpclient"
+	"github.com/d-velop/dvelop-sdk-go/idp/scim"
+)
+
+type oidcStub struct {
+	*httptest.Server
+	discoveryCalls int32
+}
+
+func newOIDCStub(t *testing.T, discoveryStatusCode int, discoveryCacheControl string, discoveryBody func(serverURL string) string) *oidcStub {
+	s := &oidcStub{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			atomic.AddInt32(&s.discoveryCalls, 1)
+			if got := r.Header.Get("Authorization"); got != "" {
+				t.Errorf("discovery document should be fetched without Authorization header but got '%v'", got)
+			}
+			if discoveryCacheControl != "" {
+				w.Header().Set("Cache-Control", discoveryCacheControl)
+			}
+			w.WriteHeader(discoveryStatusCode)
+			_, _ = fmt.Fprint(w, discoveryBody(s.URL))
+		case "/identityprovider/userinfo":
+			if got := r.URL.Query().Get("allowExternalValidation"); got != "true" {
+				t.Errorf("wrong query parameter allowExternalValidation '%v'", got)
+			}
+			if got := r.Header.Get("Authorization"); got != "Bearer "+validAuthSessionId {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprint(w, userinfoResponse)
+		default:
+			t.Errorf("unexpected call of '%v'", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return s
+}
+
+const userinfoResponse = `{"sub":"9bbbf1b6-017a-449a-ad5f-9723d28223e1","name":"Hans Mustermann","given_name":"Hans","family_name":"Mustermann","preferred_username":"hmustermann","email":"hans.mustermann@d-velop.de","groups":["4711"]}`
+
+var userinfoPrincipal = scim.Principal{
+	Id:          "9bbbf1b6-017a-449a-ad5f-9723d28223e1",
+	UserName:    "hmustermann",
+	DisplayName: "Hans Mustermann",
+	Name:        scim.UserName{GivenName: "Hans", FamilyName: "Mustermann"},
+	Emails:      []scim.UserValue{{Value: "hans.mustermann@d-velop.de"}},
+	Groups:      []scim.UserGroup{{Value: "4711"}},
+}
+
+func userinfoDiscoveryDocument(serverURL string) string {
+	return fmt.Sprintf(`{"issuer":"%[1]s/identityprovider","userinfo_endpoint":"%[1]s/identityprovider/userinfo"}`, serverURL)
+}
+
+func TestWithOIDCDiscovery_Validate_CallsUserinfoEndpointOfDiscoveryDocument(t *testing.T) {
+	idpStub := newOIDCStub(t, http.StatusOK, "max-age=300", userinfoDiscoveryDocument)
+	defer idpStub.Close()
+	client, _ := idpclient.New(idpclient.WithOIDCDiscovery())
+
+	p, err := client.Validate(context.Background(), idpStub.URL, "1", validAuthSessionId)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p == nil || !reflect.DeepEqual(*p, userinfoPrincipal) {
+		t.Errorf("validate returned wrong principal: got \n %v want\n %v", p, userinfoPrincipal)
+	}
+}
+
+func TestWithOIDCDiscoveryAndInvalidAuthSessionId_Validate_ReturnsNil(t *testing.T) {
+	idpStub := newOIDCStub(t, http.StatusOK, "max-age=300", userinfoDiscoveryDocument)
+	defer idpStub.Close()
+	client, _ := idpclient.New(idpclient.WithOIDCDiscovery())
+
+	p, err := client.Validate(context.Background(), idpStub.URL, "1", invalidAuthSessionId)
+
+	if err != nil || p != nil {
+		t.Errorf("expected nil, nil but got %v, %v", p, err)
+	}
+}
+
+func TestWithOIDCDiscoveryAndDiscoveryDocumentWithMaxAge_ValidateTwice_FetchesDiscoveryDocumentOnce(t *testing.T) {
+	idpStub := newOIDCStub(t, http.StatusOK, "max-age=300", userinfoDiscoveryDocument)
+	defer idpStub.Close()
+	client, _ := idpclient.New(idpclient.WithOIDCDiscovery())
+
+	_, _ = client.Validate(context.Background(), idpStub.URL, "1", validAuthSessionId)
+	_, _ = client.Validate(context.Background(), idpStub.URL, "1", invalidAuthSessionId)
+
+	if calls := atomic.LoadInt32(&idpStub.discoveryCalls); calls != 1 {
+		t.Errorf("discovery document should be fetched once but was fetched %v times", calls)
+	}
+}
+
+func TestWithOIDCDiscoveryAndDiscoveryDocumentWithoutMaxAge_ValidateTwice_FetchesDiscoveryDocumentOnce(t *testing.T) {
+	idpStub := newOIDCStub(t, http.StatusOK, "", userinfoDiscoveryDocument)
+	defer idpStub.Close()
+	client, _ := idpclient.New(idpclient.WithOIDCDiscovery())
+
+	_, _ = client.Validate(context.Background(), idpStub.URL, "1", validAuthSessionId)
+	_, _ = client.Validate(context.Background(), idpStub.URL, "1", invalidAuthSessionId)
+
+	if calls := atomic.LoadInt32(&idpStub.discoveryCalls); calls != 1 {
+		t.Errorf("discovery document should be fetched once but was fetched %v times", calls)
+	}
+}
+
+func TestWithOIDCDiscoveryAndDiscoveryDocumentWithMaxAgeZero_ValidateTwice_FetchesDiscoveryDocumentTwice(t *testing.T) {
+	idpStub := newOIDCStub(t, http.StatusOK, "max-age=0", userinfoDiscoveryDocument)
+	defer idpStub.Close()
+	client, _ := idpclient.New(idpclient.WithOIDCDiscovery())
+
+	_, _ = client.Validate(context.Background(), idpStub.URL, "1", validAuthSessionId)
+	_, _ = client.Validate(context.Background(), idpStub.URL, "1", invalidAuthSessionId)
+
+	if calls := atomic.LoadInt32(&idpStub.discoveryCalls); calls != 2 {
+		t.Errorf("discovery document should be fetched twice but was fetched %v times", calls)
+	}
+}
+
+func TestWithOIDCDiscoveryAndRelativeUserinfoEndpoint_Validate_CallsUserinfoEndpointOfSystemBaseUri(t *testing.T) {
+	idpStub := newOIDCStub(t, http.StatusOK, "max-age=300", func(string) string { return `{"userinfo_endpoint":"/identityprovider/userinfo"}` })
+	defer idpStub.Close()
+	client, _ := idpclient.New(idpclient.WithOIDCDiscovery())
+
+	p, err := client.Validate(context.Background(), idpStub.URL, "1", validAuthSessionId)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p == nil || !reflect.DeepEqual(*p, userinfoPrincipal) {
+		t.Errorf("validate returned wrong principal: got \n %v want\n %v", p, userinfoPrincipal)
+	}
+}
+
+func TestWithOIDCDiscoveryAndUserinfoEndpointOfOtherHost_Validate_ReturnsIdpValidateErrorWithoutCallingIt(t *testing.T) {
+	var otherHostCalled int32
+	otherHost := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&otherHostCalled, 1)
+	}))
+	defer otherHost.Close()
+	idpStub := newOIDCStub(t, http.StatusOK, "max-age=300", func(string) string {
+		return `{"userinfo_endpoint":"` + otherHost.URL + `/identityprovider/userinfo"}`
+	})
+	defer idpStub.Close()
+	client, _ := idpclient.New(idpclient.WithOIDCDiscovery())
+
+	p, err := client.Validate(context.Background(), idpStub.URL, "1", validAuthSessionId)
+
+	var validateErr idpclient.IdpValidateError
+	if !errors.As(err, &validateErr) {
+		t.Errorf("expected IdpValidateError but got %v", err)
+	}
+	if p != nil {
+		t.Errorf("expected nil but got %v", p)
+	}
+	if atomic.LoadInt32(&otherHostCalled) != 0 {
+		t.Error("userinfo_endpoint of other host should not have been called")
+	}
+}
+
+func TestWithOIDCDiscoveryAndUserinfoEndpointWithOtherScheme_Validate_ReturnsIdpValidateError(t *testing.T) {
+	idpStub := newOIDCStub(t, http.StatusOK, "max-age=300", func(serverURL string) string {
+		return `{"userinfo_endpoint":"` + strings.Replace(serverURL, "http://", "https://", 1) + `/identityprovider/userinfo"}`
+	})
+	defer idpStub.Close()
+	client, _ := idpclient.New(idpclient.WithOIDCDiscovery())
+
+	_, err := client.Validate(context.Background(), idpStub.URL, "1", validAuthSessionId)
+
+	var validateErr idpclient.IdpValidateError
+	if !errors.As(err, &validateErr) {
+		t.Errorf("expected IdpValidateError but got %v", err)
+	}
+}
+
+func TestWithOIDCDiscoveryAndDiscoveryEndpointReturnsError_Validate_ReturnsIdpValidateError(t *testing.T) {
+	idpStub := newOIDCStub(t, http.StatusInternalServerError, "", func(string) string { return "error" })
+	defer idpStub.Close()
+	client, _ := idpclient.New(idpclient.WithOIDCDiscovery())
+
+	p, err := client.Validate(context.Background(), idpStub.URL, "1", validAuthSessionId)
+
+	var validateErr idpclient.IdpValidateError
+	if !errors.As(err, &validateErr) || validateErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected IdpValidateError with status code %v but got %v", http.StatusInternalServerError, err)
+	}
+	if p != nil {
+		t.Errorf("expected nil but got %v", p)
+	}
+}
+
+func TestWithOIDCDiscoveryAndDiscoveryDocumentWithoutUserinfoEndpoint_Validate_ReturnsIdpValidateError(t *testing.T) {
+	idpStub := newOIDCStub(t, http.StatusOK, "max-age=300", func(serverURL string) string { return `{"issuer":"` + serverURL + `"}` })
+	defer idpStub.Close()
+	client, _ := idpclient.New(idpclient.WithOIDCDiscovery())
+
+	_, err := client.Validate(context.Background(), idpStub.URL, "1", validAuthSessionId)
+
+	var validateErr idpclient.IdpValidateError
+	if !errors.As(err, &validateErr) {
+		t.Errorf("expected IdpValidateError but got %v", err)
+	}
+}
+
+func TestWithOIDCDiscoveryAndUserinfoWithoutSub_Validate_ReturnsIdpValidateError(t *testing.T) {
+	idpStub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/.well-known/openid-configuration" {
+			_, _ = fmt.Fprint(w, `{"userinfo_endpoint":"/identityprovider/userinfo"}`)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"name":"Hans Mustermann"}`)
+	}))
+	defer idpStub.Close()
+	client, _ := idpclient.New(idpclient.WithOIDCDiscovery())
+
+	p, err := client.Validate(context.Background(), idpStub.URL, "1", validAuthSessionId)
+
+	var validateErr idpclient.IdpValidateError
+	if !errors.As(err, &validateErr) {
+		t.Errorf("expected IdpValidateError but got %v", err)
+	}
+	if p != nil {
+		t.Errorf("expected nil but got %v", p)
+	}
+}
@@ -0,0 +1,106 @@
+// Package rediscache provides a Redis-backed principal cache for the idpclient.
+//
+// In contrast to the default in-memory cache a Redis-backed cache is shared between
+// multiple instances of an App.
+//
+// Example:
+//
+//	c, _ := idpclient.New(idpclient.PrincipalCache(rediscache.NewRedisCache(redisClient, "myapp:principals:")))
+package rediscache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/d-velop/dvelop-sdk-go/idp/idpclient"
+	"github.com/d-velop/dvelop-sdk-go/idp/scim"
+)
+
+// RedisClient is an interface representing the ability to get and set string values
+// in Redis. A thin adapter around e.g. github.com/redis/go-redis/v9 satisfies this interface.
+type RedisClient interface {
+	// Get the value of key. An error is returned if the key doesn't exist.
+	Get(ctx context.Context, key string) (string, error)
+
+	// Set the value of key which expires after ttl. If ttl is 0 the key never expires.
+	Set(ctx context.Context, key string, value string, ttl time.Duration) error
+}
+
+// RedisDeleter is an optional interface of a RedisClient which is able to delete keys.
+// If the RedisClient implements RedisDeleter the cache supports idpclient's InvalidatePrincipal.
+type RedisDeleter interface {
+	// Delete the key. Deleting a key which doesn't exist is no error.
+	Delete(ctx context.Context, key string) error
+}
+
+// RedisSizer is an optional interface of a RedisClient which is able to return the number of keys
+// in the database, cf. the Redis command DBSIZE.
+// If the RedisClient implements RedisSizer the cache supports idpclient's CacheStats.
+type RedisSizer interface {
+	// DBSize returns the number of keys in the database.
+	DBSize(ctx context.Context) (int64, error)
+}
+
+type redisCache struct {
+	client    RedisClient
+	keyPrefix string
+}
+
+// NewRedisCache creates a idpclient.Cache which stores principals serialized as JSON in Redis.
+// The keyPrefix is prepended to each key.
+func NewRedisCache(client RedisClient, keyPrefix string) idpclient.Cache {
+	return &redisCache{
+		client:    client,
+		keyPrefix: keyPrefix,
+	}
+}
+
+// Get returns the principal stored for key. Errors of the RedisClient and values which
+// can't be deserialized are treated like a missing key.
+func (c *redisCache) Get(key string) (item interface{}, found bool) {
+	value, err := c.client.Get(context.Background(), c.keyPrefix+key)
+	if err != nil {
+		return nil, false
+	}
+	var p scim.Principal
+	if err := json.Unmarshal([]byte(value), &p); err != nil {
+		return nil, false
+	}
+	return p, true
+}
+
+// Set stores the item serialized as JSON. Items which can't be serialized are not stored.
+func (c *redisCache) Set(key string, item interface{}, cacheDuration time.Duration) {
+	value, err := json.Marshal(item)
+	if err != nil {
+		return
+	}
+	if cacheDuration < 0 {
+		cacheDuration = 0
+	}
+	_ = c.client.Set(context.Background(), c.keyPrefix+key, string(value), cacheDuration)
+}
+
+// Delete removes the principal stored for key if the RedisClient implements RedisDeleter.
+// Errors of the RedisClient are ignored, because the principal expires anyway.
+func (c *redisCache) Delete(key string) {
+	if d, ok := c.client.(RedisDeleter); ok {
+		_ = d.Delete(context.Background(), c.keyPrefix+key)
+	}
+}
+
+// ItemCount returns the number of keys in the Redis database if the RedisClient implements RedisSizer.
+// Keys of other applications which share the database are counted as well.
+// ItemCount returns 0 if the number of keys is unknown.
+func (c *redisCache) ItemCount() int {
+	s, ok := c.client.(RedisSizer)
+	if !ok {
+		return 0
+	}
+	n, err := s.DBSize(context.Background())
+	if err != nil {
+		return 0
+	}
+	return int(n)
+}
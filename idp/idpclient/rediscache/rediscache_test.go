@@ -0,0 +1,152 @@
+package rediscache_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/d-velop/dvelop-sdk-go/idp/idpclient"
+	"github.com/d-velop/dvelop-sdk-go/idp/idpclient/rediscache"
+	"github.com/d-velop/dvelop-sdk-go/idp/scim"
+)
+
+type redisClientMock struct {
+	values map[string]string
+	ttls   map[string]time.Duration
+	err    error
+}
+
+func newRedisClientMock() *redisClientMock {
+	return &redisClientMock{values: map[string]string{}, ttls: map[string]time.Duration{}}
+}
+
+func (m *redisClientMock) Get(ctx context.Context, key string) (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+	v, ok := m.values[key]
+	if !ok {
+		return "", errors.New("redis: nil")
+	}
+	return v, nil
+}
+
+func (m *redisClientMock) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.values[key] = value
+	m.ttls[key] = ttl
+	return nil
+}
+
+func (m *redisClientMock) Delete(ctx context.Context, key string) error {
+	if m.err != nil {
+		return m.err
+	}
+	delete(m.values, key)
+	delete(m.ttls, key)
+	return nil
+}
+
+func (m *redisClientMock) DBSize(ctx context.Context) (int64, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+	return int64(len(m.values)), nil
+}
+
+func TestPrincipal_SetAndGet_ReturnsPrincipal(t *testing.T) {
+	mock := newRedisClientMock()
+	c := rediscache.NewRedisCache(mock, "app:")
+	p := scim.Principal{Schemas: []string{scim.SchemaUser}, Id: "9bbbf1b6-017a-449a-ad5f-9723d28223e1", DisplayName: "Jon Doe"}
+
+	c.Set("1/session", p, 30*time.Minute)
+	item, found := c.Get("1/session")
+
+	if !found {
+		t.Fatal("expected item to be found")
+	}
+	if !reflect.DeepEqual(item, p) {
+		t.Errorf("got %v want %v", item, p)
+	}
+}
+
+func TestPrincipal_Set_StoresJSONWithKeyPrefixAndTTL(t *testing.T) {
+	mock := newRedisClientMock()
+	c := rediscache.NewRedisCache(mock, "app:")
+
+	c.Set("1/session", scim.Principal{Id: "4711"}, 30*time.Minute)
+
+	var stored scim.Principal
+	if err := json.Unmarshal([]byte(mock.values["app:1/session"]), &stored); err != nil {
+		t.Fatalf("stored value is no valid JSON: %v", err)
+	}
+	if stored.Id != "4711" {
+		t.Errorf("wrong principal stored: got %v want %v", stored.Id, "4711")
+	}
+	if ttl := mock.ttls["app:1/session"]; ttl != 30*time.Minute {
+		t.Errorf("wrong ttl: got %v want %v", ttl, 30*time.Minute)
+	}
+}
+
+func TestKeyDoesntExist_Get_ReturnsNotFound(t *testing.T) {
+	c := rediscache.NewRedisCache(newRedisClientMock(), "app:")
+
+	item, found := c.Get("1/session")
+
+	if found || item != nil {
+		t.Errorf("expected no item but got %v", item)
+	}
+}
+
+func TestRedisClientReturnsError_Get_ReturnsNotFound(t *testing.T) {
+	mock := newRedisClientMock()
+	mock.values["app:1/session"] = `{"id":"4711"}`
+	mock.err = errors.New("connection refused")
+	c := rediscache.NewRedisCache(mock, "app:")
+
+	_, found := c.Get("1/session")
+
+	if found {
+		t.Error("expected item not to be found")
+	}
+}
+
+func TestValueIsNoValidJSON_Get_ReturnsNotFound(t *testing.T) {
+	mock := newRedisClientMock()
+	mock.values["app:1/session"] = `{"id":`
+	c := rediscache.NewRedisCache(mock, "app:")
+
+	_, found := c.Get("1/session")
+
+	if found {
+		t.Error("expected item not to be found")
+	}
+}
+
+func TestPrincipalIsStored_Delete_RemovesKeyWithPrefix(t *testing.T) {
+	mock := newRedisClientMock()
+	mock.values["app:1/session"] = `{"id":"4711"}`
+	c := rediscache.NewRedisCache(mock, "app:")
+
+	c.(idpclient.Deleter).Delete("1/session")
+
+	if _, ok := mock.values["app:1/session"]; ok {
+		t.Error("expected key to be deleted")
+	}
+}
+
+func TestPrincipalsAreStored_ItemCount_ReturnsNumberOfKeys(t *testing.T) {
+	mock := newRedisClientMock()
+	mock.values["app:1/session"] = `{"id":"4711"}`
+	mock.values["app:2/session"] = `{"id":"0815"}`
+	c := rediscache.NewRedisCache(mock, "app:")
+
+	if n := c.(idpclient.ItemCounter).ItemCount(); n != 2 {
+		t.Errorf("wrong item count: got %v want %v", n, 2)
+	}
+}
@@ -0,0 +1,54 @@
+package idpclient
+
+import (
+	"fmt"
+	"io"
+)
+
+// defaultMaxResponseSize is the maximum size of a response body of the IdentityProvider-App if not configured otherwise
+const defaultMaxResponseSize int64 = 1 << 20 // 1MB
+
+// WithMaxResponseSize limits the size of the response bodies which are read from the IdentityProvider-App to
+// protect the App against huge responses of a misconfigured IdentityProvider-App. Reading a body which is larger
+// than maxBytes fails with an error. The default is 1MB.
+func WithMaxResponseSize(maxBytes int64) Option {
+	return func(c *client) error {
+		if maxBytes < 1 {
+			return fmt.Errorf("max response size must be at least 1 but is %d", maxBytes)
+		}
+		c.maxResponseSize = maxBytes
+		return nil
+	}
+}
+
+// limitedBody reads at most max bytes from the response body and returns an error if the body is larger
+type limitedBody struct {
+	body io.ReadCloser
+	r    *io.LimitedReader
+	max  int64
+}
+
+func newLimitedBody(body io.ReadCloser, max int64) *limitedBody {
+	// read one additional byte to detect bodies which exceed max
+	return &limitedBody{body: body, r: &io.LimitedReader{R: body, N: max + 1}, max: max}
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.r.N <= 0 {
+		return 0, b.sizeExceededError()
+	}
+	n, err := b.r.Read(p)
+	if b.r.N <= 0 {
+		// drop the additional byte
+		return n - 1, b.sizeExceededError()
+	}
+	return n, err
+}
+
+func (b *limitedBody) sizeExceededError() error {
+	return fmt.Errorf("idp response exceeded maximum size of %d bytes", b.max)
+}
+
+func (b *limitedBody) Close() error {
+	return b.body.Close()
+}
@@ -0,0 +1,63 @@
+package idpclient_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/d-velop/dvelop-sdk-go/idp/idpclient"
+)
+
+func newIdpValidateStubWithBody(body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, body)
+	}))
+}
+
+func TestResponseExceedsMaxResponseSize_Validate_ReturnsSizeError(t *testing.T) {
+	idpStub := newIdpValidateStubWithBody(`{"id":"9bbbf1b6-017a-449a-ad5f-9723d28223e1","displayName":"` + strings.Repeat("x", 100) + `"}`)
+	defer idpStub.Close()
+	client, err := idpclient.New(idpclient.WithMaxResponseSize(64))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := client.Validate(context.Background(), idpStub.URL, "1", validAuthSessionId)
+
+	if p != nil {
+		t.Errorf("expected no principal but got %v", p)
+	}
+	if err == nil || !strings.Contains(err.Error(), "idp response exceeded maximum size of 64 bytes") {
+		t.Errorf("expected size error but got '%v'", err)
+	}
+}
+
+func TestResponseEqualsMaxResponseSize_Validate_ReturnsPrincipal(t *testing.T) {
+	body := `{"id":"9bbbf1b6-017a-449a-ad5f-9723d28223e1"}`
+	idpStub := newIdpValidateStubWithBody(body)
+	defer idpStub.Close()
+	client, err := idpclient.New(idpclient.WithMaxResponseSize(int64(len(body))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := client.Validate(context.Background(), idpStub.URL, "1", validAuthSessionId)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p == nil || p.Id != "9bbbf1b6-017a-449a-ad5f-9723d28223e1" {
+		t.Errorf("wrong principal %v", p)
+	}
+}
+
+func TestMaxResponseSizeLessThanOne_New_ReturnsError(t *testing.T) {
+	_, err := idpclient.New(idpclient.WithMaxResponseSize(0))
+
+	if err == nil {
+		t.Error("expected an error but got nil")
+	}
+}
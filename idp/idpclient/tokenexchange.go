@@ -0,0 +1,69 @@
+package idpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	defaultTokenExchangeEndpoint = "/identityprovider/token"
+	tokenExchangeGrantType       = "urn:ietf:params:oauth:grant-type:token-exchange"
+	accessTokenType              = "urn:ietf:params:oauth:token-type:access_token"
+)
+
+// WithTokenExchangeEndpoint sets the path of the token exchange endpoint of the IdentityProvider-App
+// which is called by ExchangeToken. The default is /identityprovider/token.
+func WithTokenExchangeEndpoint(absolutePath string) Option {
+	return func(c *client) error {
+		if absolutePath == "" {
+			return errors.New("token exchange endpoint must not be empty")
+		}
+		c.tokenExchangeEndpoint = absolutePath
+		return nil
+	}
+}
+
+type tokenExchangeResponse struct {
+	AccessToken string `json:"access_token"`
+}
+
+/*
+ExchangeToken exchanges the subjectAuthSessionId of a user for a token which can be used to call the service specified by
+targetServiceId on behalf of the user. The tenant is specified by systemBaseUri and tenantId.
+
+The IdentityProvider-App is called according to OAuth 2.0 Token Exchange (cf. https://tools.ietf.org/html/rfc8693).
+If the IdentityProvider-App refuses the exchange the error is an IdpClientError, e.g. with StatusCode 400.
+*/
+func (c *client) ExchangeToken(ctx context.Context, systemBaseUri string, tenantId string, subjectAuthSessionId string, targetServiceId string) (string, error) {
+	form := url.Values{
+		"grant_type":         {tokenExchangeGrantType},
+		"subject_token":      {subjectAuthSessionId},
+		"subject_token_type": {accessTokenType},
+		"audience":           {targetServiceId},
+	}
+	endpoint := c.tokenExchangeEndpoint
+	resp, doErr := c.httpDo(ctx, http.MethodPost, systemBaseUri, subjectAuthSessionId, endpoint, strings.NewReader(form.Encode()), "application/x-www-form-urlencoded")
+	if doErr != nil {
+		return "", fmt.Errorf("error calling http POST on '%s' because: %w", endpoint, doErr)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var t tokenExchangeResponse
+		if err := json.NewDecoder(resp.Body).Decode(&t); err != nil {
+			return "", fmt.Errorf("response from Identityprovider '%s' is no valid JSON because: %v", endpoint, err)
+		}
+		if t.AccessToken == "" {
+			return "", fmt.Errorf("response from Identityprovider '%s' contains no access_token", endpoint)
+		}
+		return t.AccessToken, nil
+	default:
+		return "", newIdpClientError(resp)
+	}
+}
@@ -0,0 +1,97 @@
+package idpclient_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/d-velop/dvelop-sdk-go/idp/idpclient"
+)
+
+func newIdpTokenExchangeStub(t *testing.T, path string, statusCode int, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("wrong method '%v'", r.Method)
+		}
+		if r.URL.Path != path {
+			t.Errorf("wrong path '%v'", r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]string{
+			"grant_type":         "urn:ietf:params:oauth:grant-type:token-exchange",
+			"subject_token":      validAuthSessionId,
+			"subject_token_type": "urn:ietf:params:oauth:token-type:access_token",
+			"audience":           "targetservice",
+		}
+		for k, v := range want {
+			if got := r.PostForm.Get(k); got != v {
+				t.Errorf("wrong form parameter %v: got '%v', want '%v'", k, got, v)
+			}
+		}
+		w.WriteHeader(statusCode)
+		_, _ = fmt.Fprint(w, body)
+	}))
+}
+
+func TestIdpExchangesToken_ExchangeToken_ReturnsToken(t *testing.T) {
+	idpStub := newIdpTokenExchangeStub(t, "/identityprovider/token", http.StatusOK, `{"access_token":"exchanged-token","issued_token_type":"urn:ietf:params:oauth:token-type:access_token","token_type":"Bearer"}`)
+	defer idpStub.Close()
+
+	got, err := defaultClient.ExchangeToken(context.Background(), idpStub.URL, "1", validAuthSessionId, "targetservice")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "exchanged-token" {
+		t.Errorf("got token '%v', want '%v'", got, "exchanged-token")
+	}
+}
+
+func TestTokenExchangeEndpoint_ExchangeToken_CallsEndpoint(t *testing.T) {
+	idpStub := newIdpTokenExchangeStub(t, "/identityprovider/oauth/exchange", http.StatusOK, `{"access_token":"exchanged-token"}`)
+	defer idpStub.Close()
+	client, err := idpclient.New(idpclient.WithTokenExchangeEndpoint("/identityprovider/oauth/exchange"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := client.ExchangeToken(context.Background(), idpStub.URL, "1", validAuthSessionId, "targetservice")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "exchanged-token" {
+		t.Errorf("got token '%v', want '%v'", got, "exchanged-token")
+	}
+}
+
+func TestIdpRefusesExchange_ExchangeToken_ReturnsIdpClientError(t *testing.T) {
+	idpStub := newIdpTokenExchangeStub(t, "/identityprovider/token", http.StatusBadRequest, `{"error":"invalid_target"}`)
+	defer idpStub.Close()
+
+	got, err := defaultClient.ExchangeToken(context.Background(), idpStub.URL, "1", validAuthSessionId, "targetservice")
+
+	var clientErr idpclient.IdpClientError
+	if !errors.As(err, &clientErr) || clientErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected an IdpClientError with StatusCode 400 but got %v", err)
+	}
+	if got != "" {
+		t.Errorf("got token '%v', want no token", got)
+	}
+}
+
+func TestIdpReturnsNoAccessToken_ExchangeToken_ReturnsError(t *testing.T) {
+	idpStub := newIdpTokenExchangeStub(t, "/identityprovider/token", http.StatusOK, `{}`)
+	defer idpStub.Close()
+
+	_, err := defaultClient.ExchangeToken(context.Background(), idpStub.URL, "1", validAuthSessionId, "targetservice")
+
+	if err == nil {
+		t.Error("expected an error but got nil")
+	}
+}
@@ -0,0 +1,21 @@
+/*
+Package jwtvalidator provides a validator which validates JSON Web Tokens (JWT) issued by the IdentityProvider-App
+locally without a round-trip to the IdentityProvider-App.
+
+The signature of the token is verified with the public keys from a JSON Web Key Set (JWKS). The key set
+is fetched from the given jwksUri and cached. It is refreshed if a token references an unknown key id
+or if the refresh interval has elapsed, but at most once per min refetch interval.
+The issuer of the token must be the issuer of the tenant which is returned by the issuer function passed to New.
+
+A validator with sensible defaults can be created as follows:
+
+	v, _ := jwtvalidator.New("https://acme.d-velop.cloud/identityprovider/.well-known/jwks", "myapp", func(systemBaseUri string, tenantId string) string {
+		// return the issuer of the tokens of the tenant as configured for your app
+		return "https://acme.d-velop.cloud/identityprovider"
+	})
+
+The validator implements the idp.Validator interface and can be used with idp.Authenticate:
+
+	authenticate := idp.Authenticate(v, tenant.SystemBaseUriFromCtx, tenant.IdFromCtx, false, logError, logInfo)
+*/
+package jwtvalidator
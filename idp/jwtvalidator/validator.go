@@ -0,0 +1,316 @@
+package jwtvalidator
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	_ "crypto/sha256" // register hash functions used by RS256
+	_ "crypto/sha512" // register hash functions used by RS384 and RS512
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/d-velop/dvelop-sdk-go/idp/scim"
+)
+
+// Validator validates JWTs locally with the keys of a JSON Web Key Set. Use New to create a Validator.
+type Validator struct {
+	jwksUri            string
+	audience           string
+	issuer             func(systemBaseUri string, tenantId string) string
+	httpClient         *http.Client
+	refreshInterval    time.Duration
+	minRefetchInterval time.Duration
+	now                func() time.Time
+
+	mu          sync.Mutex
+	keys        map[string]*rsa.PublicKey
+	fetchedAt   time.Time
+	lastFetchAt time.Time
+}
+
+type Option func(*Validator) error
+
+// HttpClient explicitly sets the http.Client which should be used to fetch the JSON Web Key Set
+func HttpClient(h *http.Client) Option {
+	return func(v *Validator) error {
+		v.httpClient = h
+		return nil
+	}
+}
+
+// RefreshInterval sets the interval after which the cached JSON Web Key Set is fetched again
+func RefreshInterval(d time.Duration) Option {
+	return func(v *Validator) error {
+		if d <= 0 {
+			return fmt.Errorf("refresh interval must be greater than 0 but was %v", d)
+		}
+		v.refreshInterval = d
+		return nil
+	}
+}
+
+// MinRefetchInterval sets the minimum interval between two fetches of the JSON Web Key Set.
+// Tokens which reference an unknown key id don't trigger a fetch within this interval,
+// so they can't be used to flood the jwksUri with requests. 0 disables the limit.
+func MinRefetchInterval(d time.Duration) Option {
+	return func(v *Validator) error {
+		if d < 0 {
+			return fmt.Errorf("min refetch interval must not be negative but was %v", d)
+		}
+		v.minRefetchInterval = d
+		return nil
+	}
+}
+
+// New creates a new validator for JWTs which have been signed with a key of the JSON Web Key Set
+// available at jwksUri and which have been issued for audience. The function issuer returns the
+// expected issuer (iss claim) of the tokens for the tenant specified by systemBaseUri and tenantId.
+// The following defaults are used:
+//
+//   - HttpClient: http.DefaultClient
+//   - RefreshInterval: 1 hour
+//   - MinRefetchInterval: 1 minute
+//
+// If you don't want to use the defaults provide one or more options to this function.
+func New(jwksUri string, audience string, issuer func(systemBaseUri string, tenantId string) string, options ...Option) (*Validator, error) {
+	if issuer == nil {
+		return nil, fmt.Errorf("issuer must not be nil")
+	}
+	v := &Validator{
+		jwksUri:            jwksUri,
+		audience:           audience,
+		issuer:             issuer,
+		httpClient:         http.DefaultClient,
+		refreshInterval:    time.Hour,
+		minRefetchInterval: time.Minute,
+		now:                time.Now,
+	}
+
+	for _, option := range options {
+		err := option(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return v, nil
+}
+
+var hashes = map[string]crypto.Hash{
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type claims struct {
+	Sub    string   `json:"sub"`
+	Name   string   `json:"name"`
+	Email  string   `json:"email"`
+	Groups []string `json:"groups"`
+	Exp    *int64   `json:"exp"`
+	Nbf    *int64   `json:"nbf"`
+	Aud    audience `json:"aud"`
+	Iss    string   `json:"iss"`
+}
+
+// audience is either a single string or an array of strings cf. https://tools.ietf.org/html/rfc7519#section-4.1.3
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(b, &multiple); err != nil {
+		return err
+	}
+	*a = multiple
+	return nil
+}
+
+func (a audience) contains(aud string) bool {
+	for _, s := range a {
+		if s == aud {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Validate checks if the authSessionId is a valid JWT.
+
+The token is valid if its signature has been created by a key of the JSON Web Key Set, it has not expired,
+it has been issued for the audience of the validator and it has been issued by the issuer of the tenant
+specified by systemBaseUri and tenantId (cf. New). In this case a none nil *scim.Principal which
+is created from the claims of the token is returned. Otherwise the returned *scim.Principal is nil.
+
+An error is returned if something unexpected occurred, e.g. the JSON Web Key Set couldn't be fetched.
+*/
+func (v *Validator) Validate(ctx context.Context, systemBaseUri string, tenantId string, authSessionId string) (*scim.Principal, error) {
+	parts := strings.Split(authSessionId, ".")
+	if len(parts) != 3 {
+		return nil, nil
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, nil
+	}
+	hash, ok := hashes[h.Alg]
+	if !ok {
+		return nil, nil
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, nil
+	}
+
+	key, err := v.key(ctx, h.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, nil
+	}
+
+	hasher := hash.New()
+	hasher.Write([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, hash, hasher.Sum(nil), signature); err != nil {
+		return nil, nil
+	}
+
+	var c claims
+	if err := decodeSegment(parts[1], &c); err != nil {
+		return nil, nil
+	}
+	now := v.now().Unix()
+	if c.Exp == nil || now >= *c.Exp {
+		return nil, nil
+	}
+	if c.Nbf != nil && now < *c.Nbf {
+		return nil, nil
+	}
+	if !c.Aud.contains(v.audience) {
+		return nil, nil
+	}
+	if c.Iss == "" || c.Iss != v.issuer(systemBaseUri, tenantId) {
+		return nil, nil
+	}
+
+	p := &scim.Principal{
+		Id:          c.Sub,
+		DisplayName: c.Name,
+	}
+	if c.Email != "" {
+		p.Emails = []scim.UserValue{{Value: c.Email}}
+	}
+	for _, g := range c.Groups {
+		p.Groups = append(p.Groups, scim.UserGroup{Value: g})
+	}
+	return p, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// key returns the public key with the given key id. The JSON Web Key Set is fetched again
+// if the key id is unknown or the refresh interval has elapsed. Apart from the first fetch the key set
+// is fetched at most once per min refetch interval. The key set is fetched without holding the lock,
+// so a slow jwksUri doesn't block the validation of tokens with known key ids.
+func (v *Validator) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	v.mu.Lock()
+	now := v.now()
+	k, found := v.keys[kid]
+	expired := v.keys == nil || now.Sub(v.fetchedAt) >= v.refreshInterval
+	if found && !expired {
+		v.mu.Unlock()
+		return k, nil
+	}
+	if v.keys != nil && now.Sub(v.lastFetchAt) < v.minRefetchInterval {
+		// the key set has been fetched recently or is being fetched, so the cached key set is used
+		v.mu.Unlock()
+		return k, nil
+	}
+	v.lastFetchAt = now
+	v.mu.Unlock()
+
+	keys, err := v.fetchKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.keys = keys
+	v.fetchedAt = v.now()
+	return keys[kid], nil
+}
+
+type jwks struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+func (v *Validator) fetchKeys(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	req, nRErr := http.NewRequestWithContext(ctx, http.MethodGet, v.jwksUri, nil)
+	if nRErr != nil {
+		return nil, fmt.Errorf("can't create http request for '%s' because: %v", v.jwksUri, nRErr)
+	}
+	resp, doErr := v.httpClient.Do(req)
+	if doErr != nil {
+		return nil, fmt.Errorf("error calling http GET on '%s' because: %w", v.jwksUri, doErr)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		responseMsg, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected error. '%s' returned HTTP-Statuscode '%d' and message '%s'",
+			v.jwksUri, resp.StatusCode, responseMsg)
+	}
+
+	var set jwks
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("response from '%s' is no valid JSON because: %v", v.jwksUri, err)
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, nErr := base64.RawURLEncoding.DecodeString(k.N)
+		e, eErr := base64.RawURLEncoding.DecodeString(k.E)
+		if nErr != nil || eErr != nil {
+			return nil, fmt.Errorf("key '%s' from '%s' is no valid RSA key", k.Kid, v.jwksUri)
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	return keys, nil
+}
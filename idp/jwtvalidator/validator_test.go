@@ -0,0 +1,282 @@
+package jwtvalidator_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/d-velop/dvelop-sdk-go/idp/jwtvalidator"
+	"github.com/d-velop/dvelop-sdk-go/idp/scim"
+)
+
+var signingKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+var otherKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+
+type jwksStub struct {
+	*httptest.Server
+	calls int32
+	keys  map[string]*rsa.PublicKey
+}
+
+func newJwksStub(keys map[string]*rsa.PublicKey) *jwksStub {
+	stub := &jwksStub{keys: keys}
+	stub.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&stub.calls, 1)
+		type jwk struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		}
+		set := struct {
+			Keys []jwk `json:"keys"`
+		}{}
+		for kid, k := range stub.keys {
+			set.Keys = append(set.Keys, jwk{
+				Kty: "RSA",
+				Kid: kid,
+				N:   base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(set)
+	}))
+	return stub
+}
+
+func newToken(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	h, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid})
+	c, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func identityProviderIssuer(systemBaseUri string, tenantId string) string {
+	return systemBaseUri + "/identityprovider"
+}
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub":    "9bbbf1b6-017a-449a-ad5f-9723d28223e1",
+		"name":   "Hans Mustermann",
+		"email":  "hans.mustermann@d-velop.de",
+		"groups": []string{"3E093BE5-CCCE-435D-99F8-544656B98681", "4711"},
+		"aud":    "myapp",
+		"iss":    "https://acme.d-velop.cloud/identityprovider",
+		"exp":    time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestValidToken_Validate_ReturnsPrincipalFromClaims(t *testing.T) {
+	stub := newJwksStub(map[string]*rsa.PublicKey{"key1": &signingKey.PublicKey})
+	defer stub.Close()
+	v, _ := jwtvalidator.New(stub.URL, "myapp", identityProviderIssuer)
+
+	p, err := v.Validate(context.Background(), "https://acme.d-velop.cloud", "1", newToken(t, signingKey, "key1", validClaims()))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &scim.Principal{
+		Id:          "9bbbf1b6-017a-449a-ad5f-9723d28223e1",
+		DisplayName: "Hans Mustermann",
+		Emails:      []scim.UserValue{{Value: "hans.mustermann@d-velop.de"}},
+		Groups:      []scim.UserGroup{{Value: "3E093BE5-CCCE-435D-99F8-544656B98681"}, {Value: "4711"}},
+	}
+	if !cmp.Equal(p, expected) {
+		t.Errorf("\ngot   :'%v'\nwanted:'%v'", p, expected)
+	}
+}
+
+func TestInvalidTokens_Validate_ReturnsNilPrincipal(t *testing.T) {
+	stub := newJwksStub(map[string]*rsa.PublicKey{"key1": &signingKey.PublicKey})
+	defer stub.Close()
+	v, _ := jwtvalidator.New(stub.URL, "myapp", identityProviderIssuer)
+
+	expired := validClaims()
+	expired["exp"] = time.Now().Add(-time.Minute).Unix()
+	withoutExp := validClaims()
+	delete(withoutExp, "exp")
+	otherAudience := validClaims()
+	otherAudience["aud"] = []string{"otherapp"}
+	notYetValid := validClaims()
+	notYetValid["nbf"] = time.Now().Add(time.Hour).Unix()
+	otherIssuer := validClaims()
+	otherIssuer["iss"] = "https://other.d-velop.cloud/identityprovider"
+	withoutIssuer := validClaims()
+	delete(withoutIssuer, "iss")
+
+	testCases := []struct {
+		name  string
+		token string
+	}{
+		{"no jwt", "aXGxJeb0q+/fS8biFi8FE7TovJPPEPyzlDxT6bh5p5pHA"},
+		{"expired", newToken(t, signingKey, "key1", expired)},
+		{"without expiry", newToken(t, signingKey, "key1", withoutExp)},
+		{"other audience", newToken(t, signingKey, "key1", otherAudience)},
+		{"not yet valid", newToken(t, signingKey, "key1", notYetValid)},
+		{"other issuer", newToken(t, signingKey, "key1", otherIssuer)},
+		{"without issuer", newToken(t, signingKey, "key1", withoutIssuer)},
+		{"signed with other key", newToken(t, otherKey, "key1", validClaims())},
+		{"unknown key id", newToken(t, signingKey, "unknown", validClaims())},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := v.Validate(context.Background(), "https://acme.d-velop.cloud", "1", tc.token)
+
+			if err != nil {
+				t.Errorf("should return no error but returned %v", err)
+			}
+			if p != nil {
+				t.Errorf("should return nil principal but returned %v", p)
+			}
+		})
+	}
+}
+
+func TestTokenWithAudienceArray_Validate_ReturnsPrincipal(t *testing.T) {
+	stub := newJwksStub(map[string]*rsa.PublicKey{"key1": &signingKey.PublicKey})
+	defer stub.Close()
+	v, _ := jwtvalidator.New(stub.URL, "myapp", identityProviderIssuer)
+	c := validClaims()
+	c["aud"] = []string{"otherapp", "myapp"}
+
+	p, err := v.Validate(context.Background(), "https://acme.d-velop.cloud", "1", newToken(t, signingKey, "key1", c))
+
+	if err != nil || p == nil {
+		t.Errorf("should return principal but returned principal '%v' and error '%v'", p, err)
+	}
+}
+
+func TestKnownKeyId_Validate_UsesCachedKeySet(t *testing.T) {
+	stub := newJwksStub(map[string]*rsa.PublicKey{"key1": &signingKey.PublicKey})
+	defer stub.Close()
+	v, _ := jwtvalidator.New(stub.URL, "myapp", identityProviderIssuer)
+	token := newToken(t, signingKey, "key1", validClaims())
+
+	_, _ = v.Validate(context.Background(), "https://acme.d-velop.cloud", "1", token)
+	_, _ = v.Validate(context.Background(), "https://acme.d-velop.cloud", "1", token)
+
+	if atomic.LoadInt32(&stub.calls) != 1 {
+		t.Errorf("key set should be fetched once but was fetched %v times", stub.calls)
+	}
+}
+
+func TestUnknownKeyId_Validate_RefreshesKeySet(t *testing.T) {
+	stub := newJwksStub(map[string]*rsa.PublicKey{"key1": &signingKey.PublicKey})
+	defer stub.Close()
+	v, _ := jwtvalidator.New(stub.URL, "myapp", identityProviderIssuer, jwtvalidator.MinRefetchInterval(0))
+	_, _ = v.Validate(context.Background(), "https://acme.d-velop.cloud", "1", newToken(t, signingKey, "key1", validClaims()))
+	stub.keys = map[string]*rsa.PublicKey{"key2": &otherKey.PublicKey}
+
+	p, err := v.Validate(context.Background(), "https://acme.d-velop.cloud", "1", newToken(t, otherKey, "key2", validClaims()))
+
+	if err != nil || p == nil {
+		t.Errorf("should return principal but returned principal '%v' and error '%v'", p, err)
+	}
+	if atomic.LoadInt32(&stub.calls) != 2 {
+		t.Errorf("key set should be fetched twice but was fetched %v times", stub.calls)
+	}
+}
+
+func TestUnknownKeyIdWithinMinRefetchInterval_Validate_DoesNotRefreshKeySet(t *testing.T) {
+	stub := newJwksStub(map[string]*rsa.PublicKey{"key1": &signingKey.PublicKey})
+	defer stub.Close()
+	v, _ := jwtvalidator.New(stub.URL, "myapp", identityProviderIssuer)
+	_, _ = v.Validate(context.Background(), "https://acme.d-velop.cloud", "1", newToken(t, signingKey, "key1", validClaims()))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p, err := v.Validate(context.Background(), "https://acme.d-velop.cloud", "1", newToken(t, signingKey, "unknown", validClaims()))
+			if err != nil || p != nil {
+				t.Errorf("should return nil principal and no error but returned principal '%v' and error '%v'", p, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if atomic.LoadInt32(&stub.calls) != 1 {
+		t.Errorf("key set should be fetched once but was fetched %v times", stub.calls)
+	}
+}
+
+func TestIssuerDependsOnTenant_Validate_ChecksIssuerOfTenant(t *testing.T) {
+	stub := newJwksStub(map[string]*rsa.PublicKey{"key1": &signingKey.PublicKey})
+	defer stub.Close()
+	v, _ := jwtvalidator.New(stub.URL, "myapp", func(systemBaseUri string, tenantId string) string {
+		return "https://idp.example.com/" + tenantId
+	})
+	c := validClaims()
+	c["iss"] = "https://idp.example.com/1"
+	token := newToken(t, signingKey, "key1", c)
+
+	p, err := v.Validate(context.Background(), "https://acme.d-velop.cloud", "1", token)
+	if err != nil || p == nil {
+		t.Errorf("should return principal but returned principal '%v' and error '%v'", p, err)
+	}
+	p, err = v.Validate(context.Background(), "https://acme.d-velop.cloud", "2", token)
+	if err != nil || p != nil {
+		t.Errorf("should return nil principal for other tenant but returned principal '%v' and error '%v'", p, err)
+	}
+}
+
+func TestKeySetEndpointReturnsError_Validate_ReturnsError(t *testing.T) {
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unexpected error", http.StatusInternalServerError)
+	}))
+	defer stub.Close()
+	v, _ := jwtvalidator.New(stub.URL, "myapp", identityProviderIssuer)
+
+	_, err := v.Validate(context.Background(), "https://acme.d-velop.cloud", "1", newToken(t, signingKey, "key1", validClaims()))
+
+	if err == nil {
+		t.Error("should return error")
+	}
+}
+
+func TestInvalidRefreshInterval_New_ReturnsError(t *testing.T) {
+	_, err := jwtvalidator.New("https://acme.d-velop.cloud/jwks", "myapp", identityProviderIssuer, jwtvalidator.RefreshInterval(0))
+
+	if err == nil {
+		t.Error("should return error")
+	}
+}
+
+func TestNegativeMinRefetchInterval_New_ReturnsError(t *testing.T) {
+	_, err := jwtvalidator.New("https://acme.d-velop.cloud/jwks", "myapp", identityProviderIssuer, jwtvalidator.MinRefetchInterval(-time.Second))
+
+	if err == nil {
+		t.Error("should return error")
+	}
+}
+
+func TestNoIssuer_New_ReturnsError(t *testing.T) {
+	_, err := jwtvalidator.New("https://acme.d-velop.cloud/jwks", "myapp", nil)
+
+	if err == nil {
+		t.Error("should return error")
+	}
+}
@@ -0,0 +1,72 @@
+package idp
+
+import (
+	"errors"
+
+	"github.com/d-velop/dvelop-sdk-go/idp/idpclient"
+)
+
+// AuthMetrics is an interface representing the ability to record metrics about
+// the outcomes of the authentication by the Authenticate middleware
+type AuthMetrics interface {
+	// RecordAuthSuccess is called if a request has been authenticated successfully.
+	RecordAuthSuccess()
+
+	// RecordAuthRedirect is called if a request without valid credentials has been redirected to the IdentityProvider-App.
+	RecordAuthRedirect()
+
+	// RecordAuthUnauthorized is called if a request without valid credentials has been rejected with 401 - Unauthorized.
+	RecordAuthUnauthorized()
+
+	// RecordExternalUserForbidden is called if a request of an external user has been rejected with 403 - Forbidden.
+	RecordExternalUserForbidden()
+
+	// RecordIdpError is called if the authSessionId couldn't be validated because of an error.
+	// The statusCode is the HTTP status code returned by the IdentityProvider-App or 0 if it is unknown,
+	// e.g. due to a timeout.
+	RecordIdpError(statusCode int)
+
+	// RecordAuthInternalError is called if a request has been rejected with 500 - Internal Server Error
+	// before the IdentityProvider-App has been called, e.g. because the SystemBaseUri couldn't be read from the context.
+	RecordAuthInternalError()
+
+	// RecordAuthAnonymous is called if a request without credentials has been passed on unauthenticated because of AllowAnonymous.
+	RecordAuthAnonymous()
+}
+
+// WithMetrics sets the AuthMetrics which should be used to record the outcomes of the authentication.
+func WithMetrics(m AuthMetrics) AuthenticateOption {
+	return func(c *authConfig) {
+		c.metrics = m
+	}
+}
+
+// NopMetrics is an AuthMetrics implementation which discards all recordings.
+type NopMetrics struct{}
+
+func (NopMetrics) RecordAuthSuccess() {}
+
+func (NopMetrics) RecordAuthRedirect() {}
+
+func (NopMetrics) RecordAuthUnauthorized() {}
+
+func (NopMetrics) RecordExternalUserForbidden() {}
+
+func (NopMetrics) RecordIdpError(statusCode int) {}
+
+func (NopMetrics) RecordAuthInternalError() {}
+
+func (NopMetrics) RecordAuthAnonymous() {}
+
+// statusCodeOf returns the HTTP status code of an error returned by the idpclient or 0 if it is unknown
+func statusCodeOf(err error) int {
+	var validateErr idpclient.IdpValidateError
+	if errors.As(err, &validateErr) {
+		return validateErr.StatusCode
+	}
+	var clientErr idpclient.IdpClientError
+	if errors.As(err, &clientErr) {
+		return clientErr.StatusCode
+	}
+	return 0
+}
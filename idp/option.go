@@ -0,0 +1,102 @@
+package idp
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/d-velop/dvelop-sdk-go/idp/scim"
+)
+
+// AuthenticateOption configures the Authenticate middleware.
+type AuthenticateOption func(*authConfig)
+
+type authConfig struct {
+	cookieOptions  *CookieOptions
+	allowAnonymous bool
+	validateAPIKey func(ctx context.Context, apiKey string) (*scim.Principal, error)
+	metrics        AuthMetrics
+}
+
+func newAuthConfig(options []AuthenticateOption) *authConfig {
+	c := &authConfig{metrics: NopMetrics{}}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// CookieOptions holds the attributes of the AuthSessionId cookie which is written by the Authenticate middleware.
+type CookieOptions struct {
+	Secure   bool
+	SameSite http.SameSite
+	Domain   string
+	MaxAge   int // MaxAge in seconds. Cf. http.Cookie for the semantics of values <= 0
+}
+
+// WithCookieOptions lets the Authenticate middleware write a refreshed AuthSessionId cookie with the
+// given attributes to the response if the authSessionId of the request is valid and has been taken from the
+// AuthSessionId cookie. This can be used to implement a sliding expiry of the cookie.
+// Requests which are authenticated by a bearer authorization header don't get a cookie.
+func WithCookieOptions(opts CookieOptions) AuthenticateOption {
+	return func(c *authConfig) {
+		c.cookieOptions = &opts
+	}
+}
+
+// AllowAnonymous lets the Authenticate middleware pass requests without an authSessionId to the next handler
+// instead of redirecting them to the IdentityProvider or rejecting them with 401 - Unauthorized.
+// There is no principal on the context of anonymous requests, so PrincipalFromCtx returns an error.
+// Requests with an invalid authSessionId are still rejected.
+func AllowAnonymous() AuthenticateOption {
+	return func(c *authConfig) {
+		c.allowAnonymous = true
+	}
+}
+
+// WithAPIKeyFallback lets the Authenticate middleware authenticate requests without an authSessionId by the value of
+// their X-Api-Key header. The value is passed to validate which returns the principal the API key belongs to.
+// If validate returns a principal it's stored in the context and the request is passed to the next handler.
+// There is no authSessionId on the context of these requests, so AuthSessionIdFromCtx returns an error.
+// If validate returns nil the request is handled like a request without an authSessionId.
+// If validate returns an error the request is rejected with 401 - Unauthorized.
+// Principals of external users are rejected with 403 - Forbidden unless allowExternalValidation is true.
+//
+// Requests with an authSessionId are always authenticated by the authSessionId even if they have an X-Api-Key header.
+func WithAPIKeyFallback(validate func(ctx context.Context, apiKey string) (*scim.Principal, error)) AuthenticateOption {
+	return func(c *authConfig) {
+		c.validateAPIKey = validate
+	}
+}
+
+const apiKeyHeader = "X-Api-Key"
+
+// principalFromAPIKey returns the principal the X-Api-Key header of the request belongs to.
+// The principal is nil if the option WithAPIKeyFallback isn't set or the request has no valid API key.
+func (c *authConfig) principalFromAPIKey(ctx context.Context, req *http.Request) (*scim.Principal, error) {
+	if c.validateAPIKey == nil {
+		return nil, nil
+	}
+	apiKey := req.Header.Get(apiKeyHeader)
+	if apiKey == "" {
+		return nil, nil
+	}
+	return c.validateAPIKey(ctx, apiKey)
+}
+
+// setAuthSessionIdCookie refreshes the AuthSessionId cookie if the authSessionId has been taken from the cookie
+func (c *authConfig) setAuthSessionIdCookie(rw http.ResponseWriter, req *http.Request, authSessionId string) {
+	if c.cookieOptions == nil || bearerTokenRegex.MatchString(req.Header.Get("Authorization")) {
+		return
+	}
+	http.SetCookie(rw, &http.Cookie{
+		Name:     authSessionIdCookieName,
+		Value:    url.QueryEscape(authSessionId), // cookie is URL encoded cf. authSessionIdFromRequest
+		Path:     "/",
+		Domain:   c.cookieOptions.Domain,
+		MaxAge:   c.cookieOptions.MaxAge,
+		Secure:   c.cookieOptions.Secure,
+		HttpOnly: true,
+		SameSite: c.cookieOptions.SameSite,
+	})
+}
@@ -0,0 +1,122 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Comparison operators of a Filter cf. https://tools.ietf.org/html/rfc7644#section-3.4.2.2
+const (
+	FilterOpEqual      = "eq"
+	FilterOpContains   = "co"
+	FilterOpStartsWith = "sw"
+	FilterOpPresent    = "pr"
+)
+
+// Filter is a SCIM filter expression which can be applied to principals in memory.
+//
+// Supported are the operators eq, co, sw and pr and the attribute paths id, userName, displayName,
+// emails.value and groups.value. Attribute paths and operators are case-insensitive as are the values
+// of all attributes except id and groups.value.
+//
+// cf. https://tools.ietf.org/html/rfc7644#section-3.4.2.2
+type Filter struct {
+	attributePath string
+	operator      string
+	value         string
+}
+
+// Parse parses a SCIM filter expression like
+//
+//	emails.value eq "user@example.com"
+func Parse(expression string) (*Filter, error) {
+	parts := strings.SplitN(strings.TrimSpace(expression), " ", 3)
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("invalid filter expression '%s' because attribute path or operator is missing", expression)
+	}
+	f := &Filter{attributePath: strings.ToLower(parts[0]), operator: strings.ToLower(parts[1])}
+	if _, ok := principalAttributes[f.attributePath]; !ok {
+		return nil, fmt.Errorf("invalid filter expression '%s' because attribute path '%s' is not supported", expression, parts[0])
+	}
+	switch f.operator {
+	case FilterOpPresent:
+		if len(parts) == 3 {
+			return nil, fmt.Errorf("invalid filter expression '%s' because operator '%s' doesn't take a value", expression, parts[1])
+		}
+	case FilterOpEqual, FilterOpContains, FilterOpStartsWith:
+		if len(parts) < 3 {
+			return nil, fmt.Errorf("invalid filter expression '%s' because value is missing", expression)
+		}
+		if err := json.Unmarshal([]byte(strings.TrimSpace(parts[2])), &f.value); err != nil {
+			return nil, fmt.Errorf("invalid filter expression '%s' because value is no quoted string: %v", expression, err)
+		}
+	default:
+		return nil, fmt.Errorf("invalid filter expression '%s' because operator '%s' is not supported", expression, parts[1])
+	}
+	return f, nil
+}
+
+// Match reports whether the principal matches the filter.
+// Multi-valued attributes like emails.value match if any of their values matches.
+func (f *Filter) Match(p Principal) bool {
+	attr := principalAttributes[f.attributePath]
+	for _, v := range attr.values(p) {
+		if f.matchValue(v, attr.caseExact) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Filter) matchValue(v string, caseExact bool) bool {
+	if f.operator == FilterOpPresent {
+		return v != ""
+	}
+	want := f.value
+	if !caseExact {
+		v, want = strings.ToLower(v), strings.ToLower(want)
+	}
+	switch f.operator {
+	case FilterOpEqual:
+		return v == want
+	case FilterOpContains:
+		return strings.Contains(v, want)
+	case FilterOpStartsWith:
+		return strings.HasPrefix(v, want)
+	default:
+		return false
+	}
+}
+
+type principalAttribute struct {
+	caseExact bool
+	values    func(p Principal) []string
+}
+
+// principalAttributes contains the supported attribute paths in lower case
+var principalAttributes = map[string]principalAttribute{
+	"id": {caseExact: true, values: func(p Principal) []string {
+		return []string{p.Id}
+	}},
+	"username": {values: func(p Principal) []string {
+		return []string{p.UserName}
+	}},
+	"displayname": {values: func(p Principal) []string {
+		return []string{p.DisplayName}
+	}},
+	"emails.value": {values: func(p Principal) []string {
+		values := make([]string, 0, len(p.Emails))
+		for _, e := range p.Emails {
+			values = append(values, e.Value)
+		}
+		return values
+	}},
+	"groups.value": {caseExact: true, values: func(p Principal) []string {
+		values := make([]string, 0, len(p.Groups))
+		for _, g := range p.Groups {
+			values = append(values, g.Value)
+		}
+		return values
+	}},
+}
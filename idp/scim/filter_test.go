@@ -0,0 +1,83 @@
+package scim_test
+
+import (
+	"testing"
+
+	"github.com/d-velop/dvelop-sdk-go/idp/scim"
+)
+
+var filterPrincipal = scim.Principal{
+	Id:          "9bbbf1b6-017a-449a-ad5f-9723d28223e5",
+	UserName:    "BabsJensen",
+	DisplayName: "Barbara Jensen",
+	Emails:      []scim.UserValue{{Value: "bjensen@example.com"}, {Value: "babs@jensen.org"}},
+	Groups:      []scim.UserGroup{{Value: "3E718ABA-4C99-4C5D-8F2D-D4E8A2D5F7E1", Display: "Administrators"}},
+}
+
+func TestFilter_Match(t *testing.T) {
+	testcases := map[string]struct {
+		expression string
+		want       bool
+	}{
+		"id eq":                                   {`id eq "9bbbf1b6-017a-449a-ad5f-9723d28223e5"`, true},
+		"id eq is case exact":                     {`id eq "9BBBF1B6-017A-449A-AD5F-9723D28223E5"`, false},
+		"userName eq ignores case":                {`userName eq "babsjensen"`, true},
+		"userName eq other":                       {`userName eq "jdoe"`, false},
+		"displayName co":                          {`displayName co "Jens"`, true},
+		"displayName co other":                    {`displayName co "Doe"`, false},
+		"displayName sw":                          {`displayName sw "barb"`, true},
+		"displayName sw not prefix":               {`displayName sw "Jensen"`, false},
+		"emails.value eq second email":            {`emails.value eq "babs@jensen.org"`, true},
+		"emails.value sw":                         {`emails.value sw "bjensen@"`, true},
+		"emails.value eq other":                   {`emails.value eq "jdoe@example.com"`, false},
+		"groups.value eq":                         {`groups.value eq "3E718ABA-4C99-4C5D-8F2D-D4E8A2D5F7E1"`, true},
+		"groups.value co":                         {`groups.value co "4C99"`, true},
+		"groups.value eq other":                   {`groups.value eq "a9f2b2ad-6b91-4d7b-8b1a-1d4c3d6e7f80"`, false},
+		"displayName pr":                          {`displayName pr`, true},
+		"emails.value pr":                         {`emails.value pr`, true},
+		"value with spaces":                       {`displayName eq "Barbara Jensen"`, true},
+		"case-insensitive attribute and operator": {`USERNAME EQ "BabsJensen"`, true},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			f, err := scim.Parse(tc.expression)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := f.Match(filterPrincipal); got != tc.want {
+				t.Errorf("%v: got %v, want %v", tc.expression, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPrincipalWithoutValues_PresentFilter_DoesntMatch(t *testing.T) {
+	for _, expression := range []string{"displayName pr", "emails.value pr", "groups.value pr"} {
+		f, err := scim.Parse(expression)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.Match(scim.Principal{Id: "9bbbf1b6-017a-449a-ad5f-9723d28223e5"}) {
+			t.Errorf("%v: principal without value should not match", expression)
+		}
+	}
+}
+
+func TestInvalidExpression_Parse_ReturnsError(t *testing.T) {
+	testcases := map[string]string{
+		"missing operator":           `userName`,
+		"unsupported attribute":      `title eq "Vice President"`,
+		"unsupported operator":       `userName gt "a"`,
+		"missing value":              `userName eq`,
+		"unquoted value":             `userName eq BabsJensen`,
+		"value for present operator": `userName pr "BabsJensen"`,
+		"empty expression":           ``,
+	}
+	for name, expression := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := scim.Parse(expression); err == nil {
+				t.Errorf("expected an error for filter expression '%v'", expression)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package scim
+
+import (
+	"encoding/json"
+)
+
+// Group represents a group of users.
+//
+// It complies to the SCIM Group Schema.
+// cf. http://www.simplecloud.info/specs/draft-scim-core-schema-00.html#group-resource
+type Group struct {
+	// Id is a unique identifier for the SCIM Resource as defined by the Service Provider.
+	Id string `json:"id"`
+
+	// DisplayName is a human readable name for the Group. REQUIRED.
+	DisplayName string `json:"displayName"`
+
+	// ExternalId is a unique identifier for the Group as defined by the Service Consumer.
+	ExternalId string `json:"externalId,omitempty"`
+
+	// Members is a list of members of the Group.
+	Members []GroupMember `json:"members"`
+}
+
+func (g Group) String() string {
+	b, _ := json.Marshal(g)
+	return string(b)
+}
+
+type GroupMember struct {
+	// Value is the id of the member.
+	Value string `json:"value"`
+	// Display is the human readable name of the member.
+	Display string `json:"display,omitempty"`
+	// Ref is the URI of the member resource.
+	Ref string `json:"$ref,omitempty"`
+	// Type is the type of the member, e.g. User or Group.
+	Type string `json:"type,omitempty"`
+}
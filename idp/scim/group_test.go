@@ -0,0 +1,51 @@
+package scim_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/d-velop/dvelop-sdk-go/idp/scim"
+)
+
+const scrumPeopleJson = `{
+	"id":"759eaed7-4f4e-4fac-a5ef-49f03d0811a1",
+	"displayName":"Scrum People",
+	"externalId":"scrum-people",
+	"members":[
+		{"value":"146bc69e-1edf-40f6-bf68-849906998838","display":"Donald Duck","$ref":"/identityprovider/scim/users/146bc69e-1edf-40f6-bf68-849906998838","type":"User"},
+		{"value":"d84b34da-c60e-495e-9a0d-59507630be3a","display":"Developer","$ref":"/identityprovider/scim/groups/d84b34da-c60e-495e-9a0d-59507630be3a","type":"Group"}
+	]
+}`
+
+var scrumPeople = scim.Group{
+	Id:          "759eaed7-4f4e-4fac-a5ef-49f03d0811a1",
+	DisplayName: "Scrum People",
+	ExternalId:  "scrum-people",
+	Members: []scim.GroupMember{
+		{Value: "146bc69e-1edf-40f6-bf68-849906998838", Display: "Donald Duck", Ref: "/identityprovider/scim/users/146bc69e-1edf-40f6-bf68-849906998838", Type: "User"},
+		{Value: "d84b34da-c60e-495e-9a0d-59507630be3a", Display: "Developer", Ref: "/identityprovider/scim/groups/d84b34da-c60e-495e-9a0d-59507630be3a", Type: "Group"},
+	},
+}
+
+func TestCanDeserializeSCIMGroup(t *testing.T) {
+	var g scim.Group
+	err := json.Unmarshal([]byte(scrumPeopleJson), &g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(g, scrumPeople) {
+		t.Errorf("Unmarshaled Object wrong: got \n %v want\n %v", g, scrumPeople)
+	}
+}
+
+func TestGroupWithOnlyRequiredFields_String_OmitsOptionalFields(t *testing.T) {
+	g := scim.Group{Id: "4711", DisplayName: "Developer", Members: []scim.GroupMember{{Value: "0815"}}}
+
+	actual := g.String()
+
+	expected := `{"id":"4711","displayName":"Developer","members":[{"value":"0815"}]}`
+	if actual != expected {
+		t.Errorf("\ngot   :'%v'\nwanted:'%v'", actual, expected)
+	}
+}
@@ -0,0 +1,18 @@
+package scim
+
+// ListResponse represents one page of a SCIM query result.
+//
+// cf. https://datatracker.ietf.org/doc/html/rfc7644#section-3.4.2
+type ListResponse[T any] struct {
+	// TotalResults is the total number of results matching the query.
+	TotalResults int `json:"totalResults"`
+
+	// StartIndex is the 1-based index of the first result in this page.
+	StartIndex int `json:"startIndex"`
+
+	// ItemsPerPage is the number of results returned in this page.
+	ItemsPerPage int `json:"itemsPerPage"`
+
+	// Resources contains the results of this page.
+	Resources []T `json:"Resources"`
+}
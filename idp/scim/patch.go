@@ -0,0 +1,39 @@
+package scim
+
+// Operations of a PatchOperation cf. https://tools.ietf.org/html/rfc7644#section-3.5.2
+const (
+	PatchOpAdd     = "add"
+	PatchOpReplace = "replace"
+	PatchOpRemove  = "remove"
+)
+
+// PatchRequest represents a request to partially update a SCIM resource.
+//
+// cf. https://tools.ietf.org/html/rfc7644#section-3.5.2
+type PatchRequest struct {
+	// Schemas contains the schema URN of the patch request. Use NewPatchRequest to populate it.
+	Schemas []string `json:"schemas"`
+
+	// Operations contains the operations which are applied to the resource in the given order.
+	Operations []PatchOperation `json:"Operations"`
+}
+
+// PatchOperation is a single modification of a SCIM resource.
+type PatchOperation struct {
+	// Op is the operation to perform. One of PatchOpAdd, PatchOpReplace or PatchOpRemove.
+	Op string `json:"op"`
+
+	// Path is the attribute path of the attribute which is modified. Path is REQUIRED for remove operations.
+	Path string `json:"path,omitempty"`
+
+	// Value is the new value of the attribute. Value is not used for remove operations.
+	Value interface{} `json:"value,omitempty"`
+}
+
+// NewPatchRequest creates a PatchRequest with the given operations and the schema of a SCIM patch request.
+func NewPatchRequest(ops ...PatchOperation) PatchRequest {
+	return PatchRequest{
+		Schemas:    []string{SchemaPatchOp},
+		Operations: ops,
+	}
+}
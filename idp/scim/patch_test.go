@@ -0,0 +1,66 @@
+package scim_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/d-velop/dvelop-sdk-go/idp/scim"
+)
+
+const patchRequestJson = `{
+	"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+	"Operations": [
+		{"op": "replace", "path": "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department", "value": "Tour Operations"},
+		{"op": "add", "path": "emails", "value": [{"value": "babs@jensen.org"}]},
+		{"op": "remove", "path": "title"}
+	]
+}`
+
+func TestPatchRequest_Marshal_ProducesScimPatchPayload(t *testing.T) {
+	req := scim.NewPatchRequest(
+		scim.PatchOperation{Op: scim.PatchOpReplace, Path: "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department", Value: "Tour Operations"},
+		scim.PatchOperation{Op: scim.PatchOpAdd, Path: "emails", Value: []scim.UserValue{{Value: "babs@jensen.org"}}},
+		scim.PatchOperation{Op: scim.PatchOpRemove, Path: "title"},
+	)
+
+	b, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := strings.NewReplacer("\n", "", "\t", "", " ", "").Replace(patchRequestJson)
+	actual := strings.NewReplacer(" ", "").Replace(string(b))
+	if actual != expected {
+		t.Errorf("Marshaled Object wrong: got \n %v want\n %v", actual, expected)
+	}
+}
+
+func TestScimPatchPayload_Unmarshal_ProducesPatchRequest(t *testing.T) {
+	var req scim.PatchRequest
+	err := json.Unmarshal([]byte(patchRequestJson), &req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := scim.PatchRequest{
+		Schemas: []string{scim.SchemaPatchOp},
+		Operations: []scim.PatchOperation{
+			{Op: "replace", Path: "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department", Value: "Tour Operations"},
+			{Op: "add", Path: "emails", Value: []interface{}{map[string]interface{}{"value": "babs@jensen.org"}}},
+			{Op: "remove", Path: "title"},
+		},
+	}
+	if !reflect.DeepEqual(req, expected) {
+		t.Errorf("Unmarshaled Object wrong: got \n %v want\n %v", req, expected)
+	}
+}
+
+func TestNoOperations_NewPatchRequest_PopulatesSchemas(t *testing.T) {
+	req := scim.NewPatchRequest()
+
+	if !reflect.DeepEqual(req.Schemas, []string{"urn:ietf:params:scim:api:messages:2.0:PatchOp"}) {
+		t.Errorf("Schemas wrong: got %v", req.Schemas)
+	}
+}
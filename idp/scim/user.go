@@ -2,6 +2,8 @@ package scim
 
 import (
 	"encoding/json"
+	"errors"
+	"strings"
 )
 
 // Principal represents a user.
@@ -9,6 +11,11 @@ import (
 // It complies to the SCIM User Schema.
 // cf. http://www.simplecloud.info/specs/draft-scim-core-schema-00.html#user-resource
 type Principal struct {
+	// Schemas contains the schema URNs of the SCIM Resource.
+	//
+	// If Schemas is empty SchemaUser and, if Enterprise is set, SchemaEnterpriseUser are used when the principal is marshaled to JSON.
+	Schemas []string `json:"schemas,omitempty"`
+
 	// ID is a unique identifier for the SCIM Resource as defined by the Service Provider.
 	//
 	// Each representation of the Resource MUST include a non-empty id value. This identifier MUST be unique across the Service Provider's entire set of Resources. It MUST be a stable, non-reassignable identifier that does not change when the same Resource is returned in subsequent requests. The value of the id attribute is always issued by the Service Provider and MUST never be specified by the Service Consumer. bulkId: is a reserved keyword and MUST NOT be used in the unique identifier. REQUIRED and READ-ONLY.
@@ -59,6 +66,72 @@ type Principal struct {
 	//
 	// The values are meant to enable expression of common group or role based access control models, although no explicit authorization model is defined. It is intended that the semantics of group membership and any behavior or authorization granted as a result of membership are defined by the Service Provider. The Canonical types "direct" and "indirect" are defined to describe how the group membership was derived. Â Direct group membership indicates the User is directly associated with the group and SHOULD indicate that Consumers may modify membership through the Group Resource. Â Indirect membership indicates User membership is transitive or dynamic and implies that Consumers cannot modify indirect group membership through the Group resource but MAY modify direct group membership through the Group resource which MAY influence indirect memberships. Â If the SCIM Service Provider exposes a Group resource, the value MUST be the "id" attribute of the corresponding Group resources to which the user belongs. Since this attribute is read-only, group membership changes MUST be applied via the Group Resource. READ-ONLY.
 	Groups []UserGroup `json:"groups"`
+
+	// Enterprise contains the attributes of the SCIM Enterprise User extension if the IdentityProvider provides them.
+	//
+	// cf. https://tools.ietf.org/html/rfc7643#section-4.3
+	Enterprise *EnterpriseExtension `json:"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User,omitempty"`
+}
+
+// EnterpriseExtension contains attributes commonly used in representing users that belong to, or act on behalf of a business or enterprise.
+type EnterpriseExtension struct {
+	// EmployeeNumber is a string identifier, typically numeric or alphanumeric, assigned to a person, typically based on order of hire or association with an organization.
+	EmployeeNumber string `json:"employeeNumber,omitempty"`
+	// Organization identifies the name of an organization.
+	Organization string `json:"organization,omitempty"`
+	// Division identifies the name of a division.
+	Division string `json:"division,omitempty"`
+	// Department identifies the name of a department.
+	Department string `json:"department,omitempty"`
+	// Manager is the user's manager.
+	Manager *Manager `json:"manager,omitempty"`
+}
+
+// Manager references the manager of a user.
+type Manager struct {
+	// Value is the id of the SCIM resource representing the user's manager.
+	Value string `json:"value,omitempty"`
+	// Ref is the URI of the SCIM resource representing the user's manager.
+	Ref string `json:"$ref,omitempty"`
+	// DisplayName is the display name of the user's manager.
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+// UnmarshalJSON reads the attributes of the SCIM Enterprise User extension either from the extension namespace
+// or, if the IdentityProvider doesn't use the namespace, from the top level of the user resource.
+func (p *Principal) UnmarshalJSON(b []byte) error {
+	type principal Principal // prevent recursive calls of UnmarshalJSON
+	var pr principal
+	if err := json.Unmarshal(b, &pr); err != nil {
+		return err
+	}
+	if pr.Enterprise == nil {
+		var flat EnterpriseExtension
+		if err := json.Unmarshal(b, &flat); err != nil {
+			return err
+		}
+		if flat != (EnterpriseExtension{}) {
+			pr.Enterprise = &flat
+		}
+	}
+	*p = Principal(pr)
+	return nil
+}
+
+// MarshalJSON adds the default schema URNs to the JSON representation if Schemas is empty.
+func (p Principal) MarshalJSON() ([]byte, error) {
+	type principal Principal // prevent recursive calls of MarshalJSON
+	if len(p.Schemas) == 0 {
+		p.Schemas = defaultSchemas(p.Enterprise != nil)
+	}
+	return json.Marshal(principal(p))
+}
+
+func defaultSchemas(enterprise bool) []string {
+	if enterprise {
+		return []string{SchemaUser, SchemaEnterpriseUser}
+	}
+	return []string{SchemaUser}
 }
 
 func (p Principal) String() string {
@@ -66,6 +139,21 @@ func (p Principal) String() string {
 	return string(b)
 }
 
+// DisplayAs returns the name of the principal suitable for display.
+// That is the first non-empty value of DisplayName, Name.Display(), UserName and Id.
+func (p Principal) DisplayAs() string {
+	if p.DisplayName != "" {
+		return p.DisplayName
+	}
+	if name := p.Name.Display(); name != "" {
+		return name
+	}
+	if p.UserName != "" {
+		return p.UserName
+	}
+	return p.Id
+}
+
 // IsExternal returns true, if the principal is an external user.
 //
 // External users are users which have been authenticated successfully but have not been explicitly added to the list
@@ -83,6 +171,21 @@ func (p *Principal) IsExternal() bool {
 
 const externalGroupId = "3E093BE5-CCCE-435D-99F8-544656B98681"
 
+// Validate checks if the SCIM-required fields of the principal are present.
+//
+// UserName is REQUIRED. Id is optional because it's issued by the Service Provider on creation, but
+// a principal without both Id and UserName can't be identified at all.
+// The returned error describes which field is missing.
+func (p Principal) Validate() error {
+	if p.Id == "" && p.UserName == "" {
+		return errors.New("principal is invalid because both 'id' and 'userName' are missing")
+	}
+	if p.UserName == "" {
+		return errors.New("principal is invalid because required field 'userName' is missing")
+	}
+	return nil
+}
+
 type UserName struct {
 	// Formatted is the full name, including all middle names, titles, and suffixes as appropriate, formatted for display (e.g. Ms. Barbara Jane Jensen, III.).
 	Formatted string `json:"formatted"`
@@ -98,6 +201,21 @@ type UserName struct {
 	HonorificSuffix string `json:"honorificSuffix"`
 }
 
+// Display returns the name of the user suitable for display. That is Formatted if it's present.
+// Otherwise the non-empty components of the name are joined like Ms. Barbara Jane Jensen III.
+func (n UserName) Display() string {
+	if n.Formatted != "" {
+		return n.Formatted
+	}
+	parts := make([]string, 0, 5)
+	for _, part := range []string{n.HonorificPrefix, n.GivenName, n.MiddleName, n.FamilyName, n.HonorificSuffix} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
 type UserValue struct {
 	Value string `json:"value"`
 }
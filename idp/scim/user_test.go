@@ -66,3 +66,235 @@ func TestPrincipalIsAlsoInExternalGroup_IsExternal_IsTrue(t *testing.T) {
 		t.Errorf("Expected true for principal with groups '%v' but got false", p.Groups)
 	}
 }
+
+const enterpriseUserJson = `{
+	"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User", "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"],
+	"id": "2819c223-7f76-453a-919d-413861904646",
+	"userName": "bjensen@example.com",
+	"displayName": "Babs Jensen",
+	"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": {
+		"employeeNumber": "701984",
+		"costCenter": "4130",
+		"organization": "Universal Studios",
+		"division": "Theme Park",
+		"department": "Tour Operations",
+		"manager": {
+			"value": "26118915-6090-4610-87e4-49d8ca9f808d",
+			"$ref": "../Users/26118915-6090-4610-87e4-49d8ca9f808d",
+			"displayName": "John Smith"
+		}
+	}
+}`
+
+var enterpriseExtension = &scim.EnterpriseExtension{
+	EmployeeNumber: "701984",
+	Organization:   "Universal Studios",
+	Division:       "Theme Park",
+	Department:     "Tour Operations",
+	Manager: &scim.Manager{
+		Value:       "26118915-6090-4610-87e4-49d8ca9f808d",
+		Ref:         "../Users/26118915-6090-4610-87e4-49d8ca9f808d",
+		DisplayName: "John Smith",
+	},
+}
+
+func TestUserWithEnterpriseExtension_Unmarshal_PopulatesEnterprise(t *testing.T) {
+	var u scim.Principal
+	err := json.Unmarshal([]byte(enterpriseUserJson), &u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Id != "2819c223-7f76-453a-919d-413861904646" || u.DisplayName != "Babs Jensen" {
+		t.Errorf("Unmarshaled Object wrong: got \n %v", u)
+	}
+	if !reflect.DeepEqual(u.Enterprise, enterpriseExtension) {
+		t.Errorf("Unmarshaled Object wrong: got \n %v want\n %v", u.Enterprise, enterpriseExtension)
+	}
+}
+
+func TestUserWithFlatEnterpriseAttributes_Unmarshal_PopulatesEnterprise(t *testing.T) {
+	const flatJson = `{"id":"2819c223-7f76-453a-919d-413861904646","employeeNumber":"701984","organization":"Universal Studios","division":"Theme Park","department":"Tour Operations","manager":{"value":"26118915-6090-4610-87e4-49d8ca9f808d","$ref":"../Users/26118915-6090-4610-87e4-49d8ca9f808d","displayName":"John Smith"}}`
+	var u scim.Principal
+	err := json.Unmarshal([]byte(flatJson), &u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(u.Enterprise, enterpriseExtension) {
+		t.Errorf("Unmarshaled Object wrong: got \n %v want\n %v", u.Enterprise, enterpriseExtension)
+	}
+}
+
+func TestUserWithoutEnterpriseAttributes_Unmarshal_EnterpriseIsNil(t *testing.T) {
+	var u scim.Principal
+	err := json.Unmarshal([]byte(donaldDuckJson), &u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Enterprise != nil {
+		t.Errorf("Expected nil enterprise extension but got %v", u.Enterprise)
+	}
+}
+
+func TestPrincipalWithEnterpriseExtension_Marshal_UsesSchemaUrnAsKey(t *testing.T) {
+	b, err := json.Marshal(scim.Principal{Enterprise: &scim.EnterpriseExtension{Department: "Tour Operations"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]json.RawMessage
+	_ = json.Unmarshal(b, &m)
+	if string(m[scim.SchemaEnterpriseUser]) != `{"department":"Tour Operations"}` {
+		t.Errorf("Marshaled Object wrong: got \n %s", b)
+	}
+}
+
+func TestPrincipalWithoutSchemas_Marshal_AddsDefaultSchemas(t *testing.T) {
+	testcases := map[string]struct {
+		principal scim.Principal
+		want      string
+	}{
+		"User":                        {scim.Principal{Id: "4711"}, `["urn:ietf:params:scim:schemas:core:2.0:User"]`},
+		"UserWithEnterpriseExtension": {scim.Principal{Id: "4711", Enterprise: &scim.EnterpriseExtension{Department: "Tour Operations"}}, `["urn:ietf:params:scim:schemas:core:2.0:User","urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"]`},
+		"UserWithCustomSchemas":       {scim.Principal{Id: "4711", Schemas: []string{scim.SchemaUser, "urn:example:custom"}}, `["urn:ietf:params:scim:schemas:core:2.0:User","urn:example:custom"]`},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			b, err := json.Marshal(tc.principal)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var m map[string]json.RawMessage
+			_ = json.Unmarshal(b, &m)
+			if string(m["schemas"]) != tc.want {
+				t.Errorf("wrong schemas: got %s want %s", m["schemas"], tc.want)
+			}
+		})
+	}
+}
+
+func TestPrincipal_MarshalAndUnmarshal_ReturnsEqualPrincipal(t *testing.T) {
+	for _, p := range []scim.Principal{
+		{Id: "4711", Schemas: []string{scim.SchemaUser}},
+		{Id: "4711", Schemas: []string{scim.SchemaUser, scim.SchemaEnterpriseUser}, Enterprise: &scim.EnterpriseExtension{Department: "Tour Operations"}},
+		{Id: "4711", Schemas: []string{scim.SchemaUser, "urn:example:custom"}},
+	} {
+		b, _ := json.Marshal(p)
+		var got scim.Principal
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, p) {
+			t.Errorf("\ngot   :%v\nwanted:%v", got, p)
+		}
+	}
+}
+
+func TestUserWithDefaultSchemas_Unmarshal_KeepsSchemas(t *testing.T) {
+	var u scim.Principal
+	err := json.Unmarshal([]byte(`{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"id":"4711"}`), &u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(u.Schemas, []string{scim.SchemaUser}) {
+		t.Errorf("wrong schemas: got %v want %v", u.Schemas, []string{scim.SchemaUser})
+	}
+}
+
+func TestPrincipal_Validate(t *testing.T) {
+	testcases := map[string]struct {
+		principal scim.Principal
+		wantErr   string
+	}{
+		"ValidPrincipal_ReturnsNil": {
+			principal: donaldDuck,
+		},
+		"PrincipalWithoutIdForCreation_ReturnsNil": {
+			principal: scim.Principal{UserName: "d-velop\\donald"},
+		},
+		"PrincipalWithoutUserName_ReturnsError": {
+			principal: scim.Principal{Id: "146bc69e-1edf-40f6-bf68-849906998838"},
+			wantErr:   "principal is invalid because required field 'userName' is missing",
+		},
+		"EmptyPrincipal_ReturnsError": {
+			principal: scim.Principal{},
+			wantErr:   "principal is invalid because both 'id' and 'userName' are missing",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.principal.Validate()
+
+			gotErr := ""
+			if err != nil {
+				gotErr = err.Error()
+			}
+			if gotErr != tc.wantErr {
+				t.Errorf("\ngot   :'%v'\nwanted:'%v'", gotErr, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestUserName_Display(t *testing.T) {
+	testcases := map[string]struct {
+		name scim.UserName
+		want string
+	}{
+		"Formatted_ReturnsFormatted": {
+			name: scim.UserName{Formatted: "Ms. Barbara J Jensen, III", GivenName: "Barbara", FamilyName: "Jensen"},
+			want: "Ms. Barbara J Jensen, III",
+		},
+		"AllComponents_ReturnsJoinedComponents": {
+			name: scim.UserName{HonorificPrefix: "Ms.", GivenName: "Barbara", MiddleName: "Jane", FamilyName: "Jensen", HonorificSuffix: "III"},
+			want: "Ms. Barbara Jane Jensen III",
+		},
+		"SomeComponents_SkipsEmptyComponents": {
+			name: scim.UserName{GivenName: "Barbara", FamilyName: "Jensen"},
+			want: "Barbara Jensen",
+		},
+		"NoComponents_ReturnsEmptyString": {
+			name: scim.UserName{},
+			want: "",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if got := tc.name.Display(); got != tc.want {
+				t.Errorf("\ngot   :'%v'\nwanted:'%v'", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPrincipal_DisplayAs(t *testing.T) {
+	testcases := map[string]struct {
+		principal scim.Principal
+		want      string
+	}{
+		"DisplayName_ReturnsDisplayName": {
+			principal: scim.Principal{Id: "146bc69e", UserName: "d-velop\\donald", DisplayName: "Donald Duck", Name: scim.UserName{Formatted: "Mr. Donald Duck"}},
+			want:      "Donald Duck",
+		},
+		"NoDisplayName_ReturnsName": {
+			principal: scim.Principal{Id: "146bc69e", UserName: "d-velop\\donald", Name: scim.UserName{GivenName: "Donald", FamilyName: "Duck"}},
+			want:      "Donald Duck",
+		},
+		"NoDisplayNameAndNoName_ReturnsUserName": {
+			principal: scim.Principal{Id: "146bc69e", UserName: "d-velop\\donald"},
+			want:      "d-velop\\donald",
+		},
+		"OnlyId_ReturnsId": {
+			principal: scim.Principal{Id: "146bc69e"},
+			want:      "146bc69e",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if got := tc.principal.DisplayAs(); got != tc.want {
+				t.Errorf("\ngot   :'%v'\nwanted:'%v'", got, tc.want)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"regexp"
+	"strconv"
 )
 
 var bearerTokenRegex = regexp.MustCompile("^(?i)bearer (.*)$")
@@ -58,3 +59,41 @@ func NewIdpUsersStub(authSessionIdFromAuthorizedCaller string, existingPrincipal
 		http.Error(w, "", http.StatusNotFound)
 	}))
 }
+
+func NewIdpGroupsStub(authSessionIdFromAuthorizedCaller string, existingGroups []scim.Group) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/identityprovider/scim/groups" {
+			authorizationHeader := r.Header.Get("Authorization")
+			authToken := bearerTokenRegex.FindStringSubmatch(authorizationHeader)[1]
+
+			if authToken != authSessionIdFromAuthorizedCaller {
+				http.Error(w, `{"msg":"user unauthorized"}`, http.StatusForbidden)
+				return
+			}
+			startIndex, err := strconv.Atoi(r.URL.Query().Get("startIndex"))
+			if err != nil || startIndex < 1 {
+				startIndex = 1
+			}
+			count, err := strconv.Atoi(r.URL.Query().Get("count"))
+			if err != nil || count < 0 {
+				count = len(existingGroups)
+			}
+			first := startIndex - 1
+			if first > len(existingGroups) {
+				first = len(existingGroups)
+			}
+			last := first + count
+			if last > len(existingGroups) {
+				last = len(existingGroups)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"totalResults": len(existingGroups),
+				"itemsPerPage": last - first,
+				"startIndex":   startIndex,
+				"Resources":    existingGroups[first:last],
+			})
+			return
+		}
+		http.Error(w, "", http.StatusNotFound)
+	}))
+}
@@ -0,0 +1,179 @@
+package lambda
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+// ServeALB uses a regular http.Handler to serve requests of an AWS Application Load Balancer (ALB)
+//
+// Example:
+//	func main(){
+//		//...
+//		lambda.ServeALB (handler, logerror, loginfo)
+//	}
+func ServeALB(handler http.Handler, logerror, loginfo func(ctx context.Context, logmessage string), options ...Option) {
+	startLambda(ALBAdaptorFunc(handler, logerror, loginfo, options...))
+}
+
+// ALBAdaptorFunc adapts a regular http.Handler to an AWS lambda handler which is the target of an Application Load Balancer (ALB)
+//
+// If the target group has multi value headers enabled the response contains multi value headers as well.
+// Options which refer to API Gateway stage variables have no effect because the ALB has no stage variables.
+func ALBAdaptorFunc(handler http.Handler, logerror, loginfo func(ctx context.Context, logmessage string), options ...Option) func(ctx context.Context, request events.ALBTargetGroupRequest) (events.ALBTargetGroupResponse, error) {
+	cfg := &adaptorConfig{}
+	for _, o := range options {
+		o(cfg)
+	}
+	fn := func(ctx context.Context, request events.ALBTargetGroupRequest) (events.ALBTargetGroupResponse, error) {
+		if lc, success := lambdacontext.FromContext(ctx); success {
+			ctx = AddReqIdToCtx(ctx, lc.AwsRequestID)
+		}
+		multiValue := request.MultiValueHeaders != nil
+		loginfo(ctx, fmt.Sprintf("Received ALBTargetGroupRequest '%v'", request.RequestContext.ELB.TargetGroupArn))
+		respw := &responseWriter{header: http.Header{}, body: &bytes.Buffer{}}
+		req, err := newALBRequest(&request)
+		if err != nil {
+			logerror(ctx, fmt.Sprint(err))
+			return albErrorResponse(multiValue), nil
+		}
+		ctx = addTraceIdFromRequestToCtx(ctx, req)
+		handler.ServeHTTP(respw, req.WithContext(ctx))
+		if cfg.requestIdHeader {
+			if reqId, err := ReqIdFromCtx(ctx); err == nil {
+				respw.setResponseHeader(requestIdHeader, reqId)
+			}
+		}
+		resp, err := respw.albResponse(multiValue)
+		if err != nil {
+			logerror(ctx, fmt.Sprint(err))
+			return albErrorResponse(multiValue), nil
+		}
+		return *resp, nil
+	}
+	return fn
+}
+
+func albErrorResponse(multiValue bool) events.ALBTargetGroupResponse {
+	resp := events.ALBTargetGroupResponse{
+		Body:              http.StatusText(http.StatusInternalServerError),
+		StatusCode:        http.StatusInternalServerError,
+		StatusDescription: statusDescription(http.StatusInternalServerError),
+	}
+	if multiValue {
+		resp.MultiValueHeaders = map[string][]string{}
+	}
+	return resp
+}
+
+func newALBRequest(evt *events.ALBTargetGroupRequest) (*http.Request, error) {
+	req := &http.Request{
+		Method: mapMethod(evt.HTTPMethod),
+		URL:    mapALBURL(evt),
+		Header: mapALBHeader(evt),
+	}
+
+	if req.URL.RawQuery != "" {
+		req.RequestURI = req.URL.Path + "?" + req.URL.RawQuery
+	} else {
+		req.RequestURI = req.URL.Path
+	}
+
+	if evt.IsBase64Encoded {
+		decodedString, err := base64.StdEncoding.DecodeString(evt.Body)
+		if err != nil {
+			return nil, fmt.Errorf("Decoding of base64 body failed! cause:%v", err)
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(decodedString))
+	} else {
+		req.Body = ioutil.NopCloser(strings.NewReader(evt.Body))
+	}
+	return req, nil
+}
+
+// the ALB passes query parameters as they are sent by the client, that is url encoded
+func mapALBURL(e *events.ALBTargetGroupRequest) *url.URL {
+	u := &url.URL{Path: e.Path}
+	if e.MultiValueQueryStringParameters != nil {
+		values := make(url.Values)
+		for key, vals := range e.MultiValueQueryStringParameters {
+			for _, value := range vals {
+				values.Add(queryUnescape(key), queryUnescape(value))
+			}
+		}
+		u.RawQuery = values.Encode()
+	} else if e.QueryStringParameters != nil {
+		values := make(url.Values)
+		for key, value := range e.QueryStringParameters {
+			values.Add(queryUnescape(key), queryUnescape(value))
+		}
+		u.RawQuery = values.Encode()
+	}
+	return u
+}
+
+func queryUnescape(s string) string {
+	unescaped, err := url.QueryUnescape(s)
+	if err != nil {
+		return s
+	}
+	return unescaped
+}
+
+func mapALBHeader(e *events.ALBTargetGroupRequest) http.Header {
+	result := http.Header{}
+	if e.MultiValueHeaders != nil {
+		for k, values := range e.MultiValueHeaders {
+			for _, v := range values {
+				result.Add(k, v)
+			}
+		}
+		return result
+	}
+	for k, v := range e.Headers {
+		result.Add(k, v)
+	}
+	return result
+}
+
+func (rw *responseWriter) albResponse(multiValue bool) (*events.ALBTargetGroupResponse, error) {
+	response := &events.ALBTargetGroupResponse{}
+
+	if multiValue {
+		response.MultiValueHeaders = map[string][]string{}
+		for k, v := range rw.snapHeader {
+			response.MultiValueHeaders[k] = v
+		}
+	} else if len(rw.snapHeader) > 0 {
+		response.Headers = map[string]string{}
+		for k, v := range rw.snapHeader {
+			response.Headers[k] = strings.Join(v, ",")
+		}
+	}
+
+	body, isBase64Encoded, err := rw.encodedBody()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = body
+	response.IsBase64Encoded = isBase64Encoded
+
+	response.StatusCode = rw.status()
+	response.StatusDescription = statusDescription(response.StatusCode)
+
+	return response, nil
+}
+
+// the ALB requires a status description like "200 OK"
+func statusDescription(statusCode int) string {
+	return strings.TrimSpace(fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)))
+}
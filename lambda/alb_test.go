@@ -0,0 +1,224 @@
+package lambda_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/d-velop/dvelop-sdk-go/lambda"
+)
+
+func invokeALBAdaptorFunc(t *testing.T, evt *events.ALBTargetGroupRequest) *albTestresult {
+	spy := &handlerSpy{}
+	adaptorFunc := lambda.ALBAdaptorFunc(spy, nullLog, nullLog)
+	_, _ = adaptorFunc(context.Background(), *evt)
+	return &albTestresult{t: t, input: evt, req: spy.req}
+}
+
+type albTestresult struct {
+	t     *testing.T
+	input *events.ALBTargetGroupRequest
+	req   *http.Request
+}
+
+func TestALBAdaptor_InvokesHandlerWithCorrectMethod(t *testing.T) {
+	invokeALBAdaptorFunc(t, &events.ALBTargetGroupRequest{HTTPMethod: "get"}).invokesHandlerWithMethod(http.MethodGet)
+	invokeALBAdaptorFunc(t, &events.ALBTargetGroupRequest{HTTPMethod: "GET"}).invokesHandlerWithMethod(http.MethodGet)
+	invokeALBAdaptorFunc(t, &events.ALBTargetGroupRequest{HTTPMethod: "POST"}).invokesHandlerWithMethod(http.MethodPost)
+	invokeALBAdaptorFunc(t, &events.ALBTargetGroupRequest{HTTPMethod: "PUT"}).invokesHandlerWithMethod(http.MethodPut)
+	invokeALBAdaptorFunc(t, &events.ALBTargetGroupRequest{HTTPMethod: "DELETE"}).invokesHandlerWithMethod(http.MethodDelete)
+}
+
+func (tr *albTestresult) invokesHandlerWithMethod(expected string) {
+	if tr.req == nil {
+		tr.t.Fatalf("ServeALB(%v): should invoke handler with request.method '%v' but request was nil ", tr.input, expected)
+	}
+
+	if tr.req.Method != expected {
+		tr.t.Errorf("ServeALB(%v): should invoke handler with request.method '%v' but request.method was '%v' ", tr.input, expected, tr.req.Method)
+	}
+}
+
+func TestALBAdaptor_InvokesHandlerWithCorrectURL(t *testing.T) {
+	invokeALBAdaptorFunc(t, &events.ALBTargetGroupRequest{Path: "/path"}).invokesHandlerWithURL(&url.URL{Path: "/path"})
+	// sort query parameter by key
+	invokeALBAdaptorFunc(t, &events.ALBTargetGroupRequest{Path: "/path", QueryStringParameters: map[string]string{"foo": "1", "bar": "2"}}).invokesHandlerWithURL(&url.URL{Path: "/path", RawQuery: "bar=2&foo=1"})
+	// query parameters are passed url encoded by the ALB
+	invokeALBAdaptorFunc(t, &events.ALBTargetGroupRequest{Path: "/path", QueryStringParameters: map[string]string{"foo": "foo%2Bbar%40test.de"}}).invokesHandlerWithURL(&url.URL{Path: "/path", RawQuery: "foo=foo%2Bbar%40test.de"})
+	invokeALBAdaptorFunc(t, &events.ALBTargetGroupRequest{Path: "/path", MultiValueQueryStringParameters: map[string][]string{"foo": {"1", "2"}, "bar": {"3"}}}).invokesHandlerWithURL(&url.URL{Path: "/path", RawQuery: "bar=3&foo=1&foo=2"})
+}
+
+func (tr *albTestresult) invokesHandlerWithURL(expected *url.URL) {
+	if tr.req == nil {
+		tr.t.Fatalf("ServeALB(%v): should invoke handler with request.URL '%v' but request was nil ", tr.input, expected)
+	}
+
+	if !reflect.DeepEqual(tr.req.URL, expected) {
+		tr.t.Errorf("ServeALB(%v): should invoke handler with request.URL '%v' but request.URL was '%v' ", tr.input, expected, tr.req.URL)
+	}
+}
+
+func TestALBAdaptor_InvokesHandlerWithCorrectHeader(t *testing.T) {
+	invokeALBAdaptorFunc(t, &events.ALBTargetGroupRequest{Headers: nil}).invokesHandlerWithHeader(http.Header{})
+
+	expected := http.Header{}
+	expected.Add("Accept", "application/json")
+	invokeALBAdaptorFunc(t, &events.ALBTargetGroupRequest{Headers: map[string]string{"accept": "application/json"}}).invokesHandlerWithHeader(expected)
+
+	expected = http.Header{}
+	expected.Add("Cookie", "a=1")
+	expected.Add("Cookie", "b=2")
+	invokeALBAdaptorFunc(t, &events.ALBTargetGroupRequest{MultiValueHeaders: map[string][]string{"cookie": {"a=1", "b=2"}}}).invokesHandlerWithHeader(expected)
+}
+
+func (tr *albTestresult) invokesHandlerWithHeader(expected http.Header) {
+	if tr.req == nil {
+		tr.t.Fatalf("ServeALB(%v): should invoke handler with request.Header '%v' but request was nil", tr.input, expected)
+	}
+
+	if !reflect.DeepEqual(tr.req.Header, expected) {
+		tr.t.Errorf("ServeALB(%v): should invoke handler with request.Header '%v' but request.Header was '%v' ", tr.input, expected, tr.req.Header)
+	}
+}
+
+func TestALBAdaptor_InvokesHandlerWithCorrectBody(t *testing.T) {
+	invokeALBAdaptorFunc(t, &events.ALBTargetGroupRequest{Body: "Hallo Welt", IsBase64Encoded: false}).invokesHandlerWithBody([]byte("Hallo Welt"))
+	invokeALBAdaptorFunc(t, &events.ALBTargetGroupRequest{Body: base64.StdEncoding.EncodeToString([]byte("Hallo Welt")), IsBase64Encoded: true}).invokesHandlerWithBody([]byte("Hallo Welt"))
+}
+
+func (tr *albTestresult) invokesHandlerWithBody(expected []byte) {
+	if tr.req == nil {
+		tr.t.Fatalf("ServeALB(%v): should invoke handler with request.Body '%v' but request was nil", tr.input, expected)
+	}
+
+	b, err := ioutil.ReadAll(tr.req.Body)
+	if err != nil {
+		tr.t.Fatalf("ServeALB(%v): should invoke handler with a valid request.Body but got an error '%v' while reading the request.Body", tr.input, err)
+	}
+	if !reflect.DeepEqual(b, expected) {
+		tr.t.Errorf("ServeALB(%v): should invoke handler with Request.Body '%s' but request.Body was '%s' ", tr.input, expected, b)
+	}
+}
+
+func TestALBAdaptor_HandlerSetsHeaderAndCallsWrite_ReturnsHeaderAndBodyAndStatus(t *testing.T) {
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprint(w, `{"Key": "value"}`)
+	}}
+
+	handler := lambda.ALBAdaptorFunc(spy, nullLog, nullLog)
+	resp, _ := handler(context.Background(), events.ALBTargetGroupRequest{})
+
+	expected := map[string]string{"Content-Type": "application/json"}
+	if !reflect.DeepEqual(resp.Headers, expected) {
+		t.Errorf("ServeALB: should return headers '%v' set by handler but returned headers '%v' ", expected, resp.Headers)
+	}
+	if resp.MultiValueHeaders != nil {
+		t.Errorf("ServeALB: should return nil multi value headers but returned '%v' ", resp.MultiValueHeaders)
+	}
+	if resp.Body != `{"Key": "value"}` {
+		t.Errorf("ServeALB: should return body '%v' set by handler but returned body '%v' ", `{"Key": "value"}`, resp.Body)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("ServeALB: should return StatusCode '%v' set by handler but returned StatusCode '%v' ", http.StatusCreated, resp.StatusCode)
+	}
+	if resp.StatusDescription != "201 Created" {
+		t.Errorf("ServeALB: should return StatusDescription '%v' but returned StatusDescription '%v' ", "201 Created", resp.StatusDescription)
+	}
+}
+
+func TestALBAdaptor_RequestWithMultiValueHeadersAndHandlerSetsHeaderWithMultipleValues_ReturnsMultiValueHeaders(t *testing.T) {
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Set-Cookie", "a=1")
+		w.Header().Add("Set-Cookie", "b=2")
+		w.WriteHeader(http.StatusOK)
+	}}
+
+	handler := lambda.ALBAdaptorFunc(spy, nullLog, nullLog)
+	resp, _ := handler(context.Background(), events.ALBTargetGroupRequest{MultiValueHeaders: map[string][]string{}})
+
+	expected := map[string][]string{"Set-Cookie": {"a=1", "b=2"}}
+	if !reflect.DeepEqual(resp.MultiValueHeaders, expected) {
+		t.Errorf("ServeALB: should return headers '%v' set by handler but returned headers '%v' ", expected, resp.MultiValueHeaders)
+	}
+	if resp.Headers != nil {
+		t.Errorf("ServeALB: should return nil single value headers but returned '%v' ", resp.Headers)
+	}
+}
+
+func TestALBAdaptor_HandlerWritesBinaryBody_ReturnsBase64EncodedBody(t *testing.T) {
+	body := []byte("%PDF-1.4")
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		_, _ = w.Write(body)
+	}}
+
+	handler := lambda.ALBAdaptorFunc(spy, nullLog, nullLog)
+	resp, _ := handler(context.Background(), events.ALBTargetGroupRequest{})
+
+	if !resp.IsBase64Encoded {
+		t.Error("ServeALB: should return base64 encoded body for binary content but IsBase64Encoded was false")
+	}
+	if resp.Body != base64.StdEncoding.EncodeToString(body) {
+		t.Errorf("ServeALB: should return body '%v' but returned body '%v' ", base64.StdEncoding.EncodeToString(body), resp.Body)
+	}
+}
+
+func TestALBAdaptorWithRequestIdHeader_HandlerCallsWrite_ReturnsRequestIdHeader(t *testing.T) {
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, "Hello World")
+	}}
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "7e1f2b4a-2c36-4b5b-9a1e-6f0c8d7e5a12"})
+
+	handler := lambda.ALBAdaptorFunc(spy, nullLog, nullLog, lambda.WithRequestIdHeader())
+	resp, _ := handler(ctx, events.ALBTargetGroupRequest{})
+
+	if got := resp.Headers["X-Aws-Request-Id"]; got != "7e1f2b4a-2c36-4b5b-9a1e-6f0c8d7e5a12" {
+		t.Errorf("ServeALB: should return header X-Aws-Request-Id '%v' but returned '%v'", "7e1f2b4a-2c36-4b5b-9a1e-6f0c8d7e5a12", got)
+	}
+}
+
+func TestALBAdaptorWithRequestIdHeaderAndMultiValueHeaders_HandlerDoesNothing_ReturnsRequestIdHeader(t *testing.T) {
+	spy := &handlerSpy{}
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "7e1f2b4a-2c36-4b5b-9a1e-6f0c8d7e5a12"})
+
+	handler := lambda.ALBAdaptorFunc(spy, nullLog, nullLog, lambda.WithRequestIdHeader())
+	resp, _ := handler(ctx, events.ALBTargetGroupRequest{MultiValueHeaders: map[string][]string{}})
+
+	if got := http.Header(resp.MultiValueHeaders).Get("X-Aws-Request-Id"); got != "7e1f2b4a-2c36-4b5b-9a1e-6f0c8d7e5a12" {
+		t.Errorf("ServeALB: should return header X-Aws-Request-Id '%v' but returned '%v'", "7e1f2b4a-2c36-4b5b-9a1e-6f0c8d7e5a12", got)
+	}
+}
+
+func TestALBAdaptorWithoutRequestIdHeader_HandlerCallsWrite_ReturnsNoRequestIdHeader(t *testing.T) {
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, "Hello World")
+	}}
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "7e1f2b4a-2c36-4b5b-9a1e-6f0c8d7e5a12"})
+
+	handler := lambda.ALBAdaptorFunc(spy, nullLog, nullLog)
+	resp, _ := handler(ctx, events.ALBTargetGroupRequest{})
+
+	if got, ok := resp.Headers["X-Aws-Request-Id"]; ok {
+		t.Errorf("ServeALB: should return no header X-Aws-Request-Id but returned '%v'", got)
+	}
+}
+
+func TestServeALB_StartsLambda(t *testing.T) {
+	spy := &serveSpy{}
+	defer lambda.SetServeFuncs(spy.start, spy.listen)()
+
+	lambda.ServeALB(http.NotFoundHandler(), nullLog, nullLog, lambda.WithRequestIdHeader())
+
+	if !spy.lambdaStarted {
+		t.Error("lambda should have been started")
+	}
+}
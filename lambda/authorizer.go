@@ -0,0 +1,29 @@
+package lambda
+
+import (
+	"context"
+)
+
+const authorizerCtxKey = contextKey("authorizer")
+
+// AddAuthorizerToCtx adds the output of an API Gateway Lambda Authorizer to the context
+func AddAuthorizerToCtx(ctx context.Context, authorizer map[string]interface{}) context.Context {
+	return context.WithValue(ctx, authorizerCtxKey, authorizer)
+}
+
+// AuthorizerContext reads the output of an API Gateway Lambda Authorizer from the context.
+// The second return value is false if the request hasn't been authorized by a Lambda Authorizer.
+func AuthorizerContext(ctx context.Context) (map[string]interface{}, bool) {
+	authorizer, ok := ctx.Value(authorizerCtxKey).(map[string]interface{})
+	return authorizer, ok
+}
+
+// PrincipalIDFromAuthorizerContext reads the principalId returned by an API Gateway Lambda Authorizer from the context.
+func PrincipalIDFromAuthorizerContext(ctx context.Context) (string, bool) {
+	authorizer, ok := AuthorizerContext(ctx)
+	if !ok {
+		return "", false
+	}
+	principalId, ok := authorizer["principalId"].(string)
+	return principalId, ok && principalId != ""
+}
@@ -0,0 +1,13 @@
+package lambda
+
+import "net/http"
+
+// SetServeFuncs replaces the functions which start the lambda runtime and the local http server
+// and returns a function which restores the original ones.
+func SetServeFuncs(start func(handler interface{}), listen func(addr string, handler http.Handler) error) (restore func()) {
+	origStart, origListen := startLambda, listenAndServe
+	startLambda, listenAndServe = start, listen
+	return func() {
+		startLambda, listenAndServe = origStart, origListen
+	}
+}
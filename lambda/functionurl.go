@@ -0,0 +1,139 @@
+package lambda
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+// ServeURL uses a regular http.Handler to serve requests of an AWS Lambda Function URL
+//
+// Example:
+//	func main(){
+//		//...
+//		lambda.ServeURL(handler, logerror, loginfo)
+//	}
+func ServeURL(handler http.Handler, logerror, loginfo func(ctx context.Context, logmessage string), options ...Option) {
+	startLambda(AdaptorFuncURL(handler, logerror, loginfo, options...))
+}
+
+// AdaptorFuncURL adapts a regular http.Handler to an AWS lambda handler which is invoked by a Lambda Function URL
+//
+// Cookies of the request are passed to the handler as Cookie header and Set-Cookie headers written by the handler
+// are returned as cookies of the response.
+// Options which refer to API Gateway stage variables have no effect because Lambda Function URLs have no stage variables.
+func AdaptorFuncURL(handler http.Handler, logerror, loginfo func(ctx context.Context, logmessage string), options ...Option) func(ctx context.Context, request events.LambdaFunctionURLRequest) (events.LambdaFunctionURLResponse, error) {
+	cfg := &adaptorConfig{}
+	for _, o := range options {
+		o(cfg)
+	}
+	fn := func(ctx context.Context, request events.LambdaFunctionURLRequest) (events.LambdaFunctionURLResponse, error) {
+		if lc, success := lambdacontext.FromContext(ctx); success {
+			ctx = AddReqIdToCtx(ctx, lc.AwsRequestID)
+		}
+		loginfo(ctx, fmt.Sprintf("Received LambdaFunctionURLRequest '%v'", request.RequestContext.RequestID))
+		respw := &responseWriter{header: http.Header{}, body: &bytes.Buffer{}}
+		req, err := newFunctionURLRequest(&request)
+		if err != nil {
+			logerror(ctx, fmt.Sprint(err))
+			return functionURLErrorResponse(), nil
+		}
+		ctx = addTraceIdFromRequestToCtx(ctx, req)
+		handler.ServeHTTP(respw, req.WithContext(ctx))
+		if cfg.requestIdHeader {
+			if reqId, err := ReqIdFromCtx(ctx); err == nil {
+				respw.setResponseHeader(requestIdHeader, reqId)
+			}
+		}
+		resp, err := respw.functionURLResponse()
+		if err != nil {
+			logerror(ctx, fmt.Sprint(err))
+			return functionURLErrorResponse(), nil
+		}
+		return *resp, nil
+	}
+	return fn
+}
+
+func functionURLErrorResponse() events.LambdaFunctionURLResponse {
+	return events.LambdaFunctionURLResponse{
+		Body:       http.StatusText(http.StatusInternalServerError),
+		StatusCode: http.StatusInternalServerError,
+	}
+}
+
+func newFunctionURLRequest(evt *events.LambdaFunctionURLRequest) (*http.Request, error) {
+	req := &http.Request{
+		Method: mapMethod(evt.RequestContext.HTTP.Method),
+		URL:    mapFunctionURLURL(evt),
+		Header: mapFunctionURLHeader(evt),
+	}
+	req.RequestURI = req.URL.RequestURI()
+
+	if evt.IsBase64Encoded {
+		decodedString, err := base64.StdEncoding.DecodeString(evt.Body)
+		if err != nil {
+			return nil, fmt.Errorf("Decoding of base64 body failed! cause:%v", err)
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(decodedString))
+	} else {
+		req.Body = ioutil.NopCloser(strings.NewReader(evt.Body))
+	}
+	return req, nil
+}
+
+// the Function URL passes path and query as they are sent by the client, that is url encoded
+func mapFunctionURLURL(e *events.LambdaFunctionURLRequest) *url.URL {
+	u := &url.URL{Path: e.RawPath, RawQuery: e.RawQueryString}
+	if p, err := url.PathUnescape(e.RawPath); err == nil && p != e.RawPath {
+		u.Path = p
+		u.RawPath = e.RawPath
+	}
+	return u
+}
+
+// the Function URL passes cookies separately from the other headers
+func mapFunctionURLHeader(e *events.LambdaFunctionURLRequest) http.Header {
+	result := http.Header{}
+	for k, v := range e.Headers {
+		result.Add(k, v)
+	}
+	if len(e.Cookies) > 0 {
+		result.Set("Cookie", strings.Join(e.Cookies, "; "))
+	}
+	return result
+}
+
+func (rw *responseWriter) functionURLResponse() (*events.LambdaFunctionURLResponse, error) {
+	response := &events.LambdaFunctionURLResponse{}
+
+	for k, v := range rw.snapHeader {
+		if k == "Set-Cookie" {
+			response.Cookies = v
+			continue
+		}
+		if response.Headers == nil {
+			response.Headers = map[string]string{}
+		}
+		response.Headers[k] = strings.Join(v, ",")
+	}
+
+	body, isBase64Encoded, err := rw.encodedBody()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = body
+	response.IsBase64Encoded = isBase64Encoded
+
+	response.StatusCode = rw.status()
+
+	return response, nil
+}
@@ -0,0 +1,385 @@
+package lambda_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/d-velop/dvelop-sdk-go/lambda"
+)
+
+func invokeAdaptorFuncURL(t *testing.T, evt *events.LambdaFunctionURLRequest) *functionURLTestresult {
+	spy := &handlerSpy{}
+	adaptorFunc := lambda.AdaptorFuncURL(spy, nullLog, nullLog)
+	_, _ = adaptorFunc(context.Background(), *evt)
+	return &functionURLTestresult{t: t, input: evt, req: spy.req}
+}
+
+func functionURLRequestWithMethod(method string) *events.LambdaFunctionURLRequest {
+	evt := &events.LambdaFunctionURLRequest{}
+	evt.RequestContext.HTTP.Method = method
+	return evt
+}
+
+type functionURLTestresult struct {
+	t     *testing.T
+	input *events.LambdaFunctionURLRequest
+	req   *http.Request
+}
+
+func TestAdaptorURL_InvokesHandlerWithCorrectMethod(t *testing.T) {
+	invokeAdaptorFuncURL(t, functionURLRequestWithMethod("get")).invokesHandlerWithMethod(http.MethodGet)
+	invokeAdaptorFuncURL(t, functionURLRequestWithMethod("GET")).invokesHandlerWithMethod(http.MethodGet)
+	invokeAdaptorFuncURL(t, functionURLRequestWithMethod("POST")).invokesHandlerWithMethod(http.MethodPost)
+	invokeAdaptorFuncURL(t, functionURLRequestWithMethod("PUT")).invokesHandlerWithMethod(http.MethodPut)
+	invokeAdaptorFuncURL(t, functionURLRequestWithMethod("PATCH")).invokesHandlerWithMethod(http.MethodPatch)
+	invokeAdaptorFuncURL(t, functionURLRequestWithMethod("DELETE")).invokesHandlerWithMethod(http.MethodDelete)
+	invokeAdaptorFuncURL(t, functionURLRequestWithMethod("HEAD")).invokesHandlerWithMethod(http.MethodHead)
+	invokeAdaptorFuncURL(t, functionURLRequestWithMethod("OPTIONS")).invokesHandlerWithMethod(http.MethodOptions)
+}
+
+func (tr *functionURLTestresult) invokesHandlerWithMethod(expected string) {
+	if tr.req == nil {
+		tr.t.Fatalf("ServeURL(%v): should invoke handler with request.method '%v' but request was nil ", tr.input, expected)
+	}
+
+	if tr.req.Method != expected {
+		tr.t.Errorf("ServeURL(%v): should invoke handler with request.method '%v' but request.method was '%v' ", tr.input, expected, tr.req.Method)
+	}
+}
+
+func TestAdaptorURL_InvokesHandlerWithCorrectURL(t *testing.T) {
+	invokeAdaptorFuncURL(t, &events.LambdaFunctionURLRequest{RawPath: "/path"}).invokesHandlerWithURL(&url.URL{Path: "/path"})
+	invokeAdaptorFuncURL(t, &events.LambdaFunctionURLRequest{RawPath: "/path", RawQueryString: "foo=1&bar=2"}).invokesHandlerWithURL(&url.URL{Path: "/path", RawQuery: "foo=1&bar=2"})
+	// path and query are passed url encoded by the Function URL
+	invokeAdaptorFuncURL(t, &events.LambdaFunctionURLRequest{RawPath: "/path", RawQueryString: "foo=foo%2Bbar%40test.de"}).invokesHandlerWithURL(&url.URL{Path: "/path", RawQuery: "foo=foo%2Bbar%40test.de"})
+	invokeAdaptorFuncURL(t, &events.LambdaFunctionURLRequest{RawPath: "/a%2Fb/c%20d"}).invokesHandlerWithURL(&url.URL{Path: "/a/b/c d", RawPath: "/a%2Fb/c%20d"})
+}
+
+func (tr *functionURLTestresult) invokesHandlerWithURL(expected *url.URL) {
+	if tr.req == nil {
+		tr.t.Fatalf("ServeURL(%v): should invoke handler with request.URL '%v' but request was nil ", tr.input, expected)
+	}
+
+	if !reflect.DeepEqual(tr.req.URL, expected) {
+		tr.t.Errorf("ServeURL(%v): should invoke handler with request.URL '%v' but request.URL was '%v' ", tr.input, expected, tr.req.URL)
+	}
+}
+
+func TestAdaptorURL_InvokesHandlerWithCorrectRequestURI(t *testing.T) {
+	invokeAdaptorFuncURL(t, &events.LambdaFunctionURLRequest{RawPath: "/path"}).invokesHandlerWithRequestURI("/path")
+	invokeAdaptorFuncURL(t, &events.LambdaFunctionURLRequest{RawPath: "/path", RawQueryString: "foo=1&bar=2"}).invokesHandlerWithRequestURI("/path?foo=1&bar=2")
+	invokeAdaptorFuncURL(t, &events.LambdaFunctionURLRequest{RawPath: "/a%2Fb"}).invokesHandlerWithRequestURI("/a%2Fb")
+}
+
+func (tr *functionURLTestresult) invokesHandlerWithRequestURI(expected string) {
+	if tr.req == nil {
+		tr.t.Fatalf("ServeURL(%v): should invoke handler with request.RequestURI '%v' but request was nil", tr.input, expected)
+	}
+
+	if tr.req.RequestURI != expected {
+		tr.t.Errorf("ServeURL(%v): should invoke handler with request.RequestURI '%v' but request.RequestURI was '%v' ", tr.input, expected, tr.req.RequestURI)
+	}
+}
+
+func TestAdaptorURL_InvokesHandlerWithCorrectHeader(t *testing.T) {
+	invokeAdaptorFuncURL(t, &events.LambdaFunctionURLRequest{Headers: nil}).invokesHandlerWithHeader(http.Header{})
+
+	expected := http.Header{}
+	expected.Add("Accept", "application/json")
+	invokeAdaptorFuncURL(t, &events.LambdaFunctionURLRequest{Headers: map[string]string{"accept": "application/json"}}).invokesHandlerWithHeader(expected)
+
+	// the Function URL passes multiple values of a header comma separated
+	expected = http.Header{}
+	expected.Add("Accept", "text/html,application/json")
+	invokeAdaptorFuncURL(t, &events.LambdaFunctionURLRequest{Headers: map[string]string{"accept": "text/html,application/json"}}).invokesHandlerWithHeader(expected)
+}
+
+func TestAdaptorURL_RequestWithCookies_InvokesHandlerWithCookieHeader(t *testing.T) {
+	expected := http.Header{}
+	expected.Add("Accept", "application/json")
+	expected.Add("Cookie", "a=1; b=2")
+	invokeAdaptorFuncURL(t, &events.LambdaFunctionURLRequest{Headers: map[string]string{"accept": "application/json"}, Cookies: []string{"a=1", "b=2"}}).invokesHandlerWithHeader(expected)
+}
+
+func (tr *functionURLTestresult) invokesHandlerWithHeader(expected http.Header) {
+	if tr.req == nil {
+		tr.t.Fatalf("ServeURL(%v): should invoke handler with request.Header '%v' but request was nil", tr.input, expected)
+	}
+
+	if !reflect.DeepEqual(tr.req.Header, expected) {
+		tr.t.Errorf("ServeURL(%v): should invoke handler with request.Header '%v' but request.Header was '%v' ", tr.input, expected, tr.req.Header)
+	}
+}
+
+func TestAdaptorURL_InvokesHandlerWithCorrectBody(t *testing.T) {
+	invokeAdaptorFuncURL(t, &events.LambdaFunctionURLRequest{Body: "Hallo Welt", IsBase64Encoded: false}).invokesHandlerWithBody([]byte("Hallo Welt"))
+	invokeAdaptorFuncURL(t, &events.LambdaFunctionURLRequest{Body: base64.StdEncoding.EncodeToString([]byte("Hallo Welt")), IsBase64Encoded: true}).invokesHandlerWithBody([]byte("Hallo Welt"))
+}
+
+func (tr *functionURLTestresult) invokesHandlerWithBody(expected []byte) {
+	if tr.req == nil {
+		tr.t.Fatalf("ServeURL(%v): should invoke handler with request.Body '%v' but request was nil", tr.input, expected)
+	}
+
+	b, err := ioutil.ReadAll(tr.req.Body)
+	if err != nil {
+		tr.t.Fatalf("ServeURL(%v): should invoke handler with a valid request.Body but got an error '%v' while reading the request.Body", tr.input, err)
+	}
+	if !reflect.DeepEqual(b, expected) {
+		tr.t.Errorf("ServeURL(%v): should invoke handler with Request.Body '%s' but request.Body was '%s' ", tr.input, expected, b)
+	}
+}
+
+func TestAdaptorURL_RequestWithInvalidBase64Body_ReturnsInternalServerError(t *testing.T) {
+	spy := &handlerSpy{}
+
+	handler := lambda.AdaptorFuncURL(spy, nullLog, nullLog)
+	resp, _ := handler(context.Background(), events.LambdaFunctionURLRequest{Body: "not base64!", IsBase64Encoded: true})
+
+	if spy.req != nil {
+		t.Error("ServeURL: should not invoke handler")
+	}
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("ServeURL: should return StatusCode '%v' but returned StatusCode '%v' ", http.StatusInternalServerError, resp.StatusCode)
+	}
+}
+
+func TestAdaptorURL_HandlerDoesNothing_ReturnsEmptyBodyAndNoHeadersAndStatusOK(t *testing.T) {
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+	}}
+
+	handler := lambda.AdaptorFuncURL(spy, nullLog, nullLog)
+	resp, _ := handler(context.Background(), events.LambdaFunctionURLRequest{})
+
+	if resp.Body != "" {
+		t.Errorf("ServeURL: should return empty body but returned '%v'", resp.Body)
+	}
+	if resp.Headers != nil {
+		t.Errorf("ServeURL: should return nil headers but returned '%v'", resp.Headers)
+	}
+	if resp.Cookies != nil {
+		t.Errorf("ServeURL: should return nil cookies but returned '%v'", resp.Cookies)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("ServeURL: should status '%v' but returned '%v'", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestAdaptorURL_HandlerSetsHeaderAndCallsWrite_ReturnsHeaderAndBodyAndStatus(t *testing.T) {
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprint(w, `{"Key": "value"}`)
+	}}
+
+	handler := lambda.AdaptorFuncURL(spy, nullLog, nullLog)
+	resp, _ := handler(context.Background(), events.LambdaFunctionURLRequest{})
+
+	expected := map[string]string{"Content-Type": "application/json"}
+	if !reflect.DeepEqual(resp.Headers, expected) {
+		t.Errorf("ServeURL: should return headers '%v' set by handler but returned headers '%v' ", expected, resp.Headers)
+	}
+	if resp.Body != `{"Key": "value"}` {
+		t.Errorf("ServeURL: should return body '%v' set by handler but returned body '%v' ", `{"Key": "value"}`, resp.Body)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("ServeURL: should return StatusCode '%v' set by handler but returned StatusCode '%v' ", http.StatusCreated, resp.StatusCode)
+	}
+}
+
+func TestAdaptorURL_HandlerSetsHeaderWithMultipleValues_ReturnsCommaSeparatedHeader(t *testing.T) {
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Cache-Control", "no-cache")
+		w.Header().Add("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+	}}
+
+	handler := lambda.AdaptorFuncURL(spy, nullLog, nullLog)
+	resp, _ := handler(context.Background(), events.LambdaFunctionURLRequest{})
+
+	expected := map[string]string{"Cache-Control": "no-cache,no-store"}
+	if !reflect.DeepEqual(resp.Headers, expected) {
+		t.Errorf("ServeURL: should return headers '%v' set by handler but returned headers '%v' ", expected, resp.Headers)
+	}
+}
+
+func TestAdaptorURL_HandlerSetsTwoCookies_ReturnsTwoCookiesAndNoSetCookieHeader(t *testing.T) {
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "a", Value: "1"})
+		http.SetCookie(w, &http.Cookie{Name: "b", Value: "2"})
+		w.WriteHeader(http.StatusOK)
+	}}
+
+	handler := lambda.AdaptorFuncURL(spy, nullLog, nullLog)
+	resp, _ := handler(context.Background(), events.LambdaFunctionURLRequest{})
+
+	expected := []string{"a=1", "b=2"}
+	if !reflect.DeepEqual(resp.Cookies, expected) {
+		t.Errorf("ServeURL: should return cookies '%v' set by handler but returned cookies '%v' ", expected, resp.Cookies)
+	}
+	if resp.Headers != nil {
+		t.Errorf("ServeURL: should return nil headers but returned '%v'", resp.Headers)
+	}
+}
+
+func TestAdaptorURL_HandlerModifiesHeaderAfterCallingWriteHeader_ReturnsUnmodifiedHeaders(t *testing.T) {
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Header", "value")
+		w.WriteHeader(http.StatusNotAcceptable)
+		w.Header().Add("Content-Type", "text/html")
+	}}
+
+	handler := lambda.AdaptorFuncURL(spy, nullLog, nullLog)
+	resp, _ := handler(context.Background(), events.LambdaFunctionURLRequest{})
+
+	expected := map[string]string{"X-Header": "value"}
+	if !reflect.DeepEqual(resp.Headers, expected) {
+		t.Errorf("ServeURL: should return headers '%v' set by handler but returned headers '%v' ", expected, resp.Headers)
+	}
+}
+
+func TestAdaptorURL_HandlerDoesntSetContentTypeAndCallsWrite_ReturnsDetectedContentTypeAndBodyAndStatusCodeOK(t *testing.T) {
+	body := "<h1>Hello world!</h1>"
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, body)
+	}}
+
+	handler := lambda.AdaptorFuncURL(spy, nullLog, nullLog)
+	resp, _ := handler(context.Background(), events.LambdaFunctionURLRequest{})
+
+	expected := map[string]string{"Content-Type": "text/html; charset=utf-8"}
+	if !reflect.DeepEqual(resp.Headers, expected) {
+		t.Errorf("ServeURL: should return headers '%v' set by handler but returned headers '%v' ", expected, resp.Headers)
+	}
+	if resp.Body != body {
+		t.Errorf("ServeURL: should return body '%v' set by handler but returned body '%v' ", body, resp.Body)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("ServeURL: should return StatusCode '%v' set by handler but returned StatusCode '%v' ", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestAdaptorURL_HandlerWritesBinaryBody_ReturnsBase64EncodedBody(t *testing.T) {
+	body := []byte("%PDF-1.4")
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		_, _ = w.Write(body)
+	}}
+
+	handler := lambda.AdaptorFuncURL(spy, nullLog, nullLog)
+	resp, _ := handler(context.Background(), events.LambdaFunctionURLRequest{})
+
+	if !resp.IsBase64Encoded {
+		t.Error("ServeURL: should return base64 encoded body for binary content but IsBase64Encoded was false")
+	}
+	if resp.Body != base64.StdEncoding.EncodeToString(body) {
+		t.Errorf("ServeURL: should return body '%v' but returned body '%v' ", base64.StdEncoding.EncodeToString(body), resp.Body)
+	}
+}
+
+func TestAdaptorURL_HandlerWritesTextBody_ReturnsPlainBody(t *testing.T) {
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"Key": "value"}`)
+	}}
+
+	handler := lambda.AdaptorFuncURL(spy, nullLog, nullLog)
+	resp, _ := handler(context.Background(), events.LambdaFunctionURLRequest{})
+
+	if resp.IsBase64Encoded {
+		t.Error("ServeURL: should return plain body for text content but IsBase64Encoded was true")
+	}
+	if resp.Body != `{"Key": "value"}` {
+		t.Errorf("ServeURL: should return body '%v' but returned body '%v' ", `{"Key": "value"}`, resp.Body)
+	}
+}
+
+func TestAdaptorURL_LambdaContextWithRequestId_InvokesHandlerWithRequestIdOnContext(t *testing.T) {
+	var reqId string
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		reqId, _ = lambda.ReqIdFromCtx(r.Context())
+	}}
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "7e1f2b4a-2c36-4b5b-9a1e-6f0c8d7e5a12"})
+
+	handler := lambda.AdaptorFuncURL(spy, nullLog, nullLog)
+	_, _ = handler(ctx, events.LambdaFunctionURLRequest{})
+
+	if reqId != "7e1f2b4a-2c36-4b5b-9a1e-6f0c8d7e5a12" {
+		t.Errorf("ServeURL: should add request id '%v' to context but added '%v'", "7e1f2b4a-2c36-4b5b-9a1e-6f0c8d7e5a12", reqId)
+	}
+}
+
+func TestAdaptorURL_RequestWithTraceIdHeader_InvokesHandlerWithTraceIdOnContext(t *testing.T) {
+	var traceId string
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		traceId, _ = lambda.TraceIdFromCtx(r.Context())
+	}}
+
+	handler := lambda.AdaptorFuncURL(spy, nullLog, nullLog)
+	_, _ = handler(context.Background(), events.LambdaFunctionURLRequest{
+		Headers: map[string]string{"x-amzn-trace-id": "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1"},
+	})
+
+	if traceId != "1-5759e988-bd862e3fe1be46a994272793" {
+		t.Errorf("ServeURL: should add trace id '%v' to context but added '%v'", "1-5759e988-bd862e3fe1be46a994272793", traceId)
+	}
+}
+
+func TestAdaptorURLWithRequestIdHeader_HandlerCallsWrite_ReturnsRequestIdHeader(t *testing.T) {
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, "Hello World")
+	}}
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "7e1f2b4a-2c36-4b5b-9a1e-6f0c8d7e5a12"})
+
+	handler := lambda.AdaptorFuncURL(spy, nullLog, nullLog, lambda.WithRequestIdHeader())
+	resp, _ := handler(ctx, events.LambdaFunctionURLRequest{})
+
+	if got := resp.Headers["X-Aws-Request-Id"]; got != "7e1f2b4a-2c36-4b5b-9a1e-6f0c8d7e5a12" {
+		t.Errorf("ServeURL: should return header X-Aws-Request-Id '%v' but returned '%v'", "7e1f2b4a-2c36-4b5b-9a1e-6f0c8d7e5a12", got)
+	}
+}
+
+func TestAdaptorURLWithRequestIdHeader_HandlerDoesNothing_ReturnsRequestIdHeader(t *testing.T) {
+	spy := &handlerSpy{}
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "7e1f2b4a-2c36-4b5b-9a1e-6f0c8d7e5a12"})
+
+	handler := lambda.AdaptorFuncURL(spy, nullLog, nullLog, lambda.WithRequestIdHeader())
+	resp, _ := handler(ctx, events.LambdaFunctionURLRequest{})
+
+	if got := resp.Headers["X-Aws-Request-Id"]; got != "7e1f2b4a-2c36-4b5b-9a1e-6f0c8d7e5a12" {
+		t.Errorf("ServeURL: should return header X-Aws-Request-Id '%v' but returned '%v'", "7e1f2b4a-2c36-4b5b-9a1e-6f0c8d7e5a12", got)
+	}
+}
+
+func TestAdaptorURLWithoutRequestIdHeader_HandlerCallsWrite_ReturnsNoRequestIdHeader(t *testing.T) {
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, "Hello World")
+	}}
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "7e1f2b4a-2c36-4b5b-9a1e-6f0c8d7e5a12"})
+
+	handler := lambda.AdaptorFuncURL(spy, nullLog, nullLog)
+	resp, _ := handler(ctx, events.LambdaFunctionURLRequest{})
+
+	if got, ok := resp.Headers["X-Aws-Request-Id"]; ok {
+		t.Errorf("ServeURL: should return no header X-Aws-Request-Id but returned '%v'", got)
+	}
+}
+
+func TestServeURL_StartsLambda(t *testing.T) {
+	spy := &serveSpy{}
+	defer lambda.SetServeFuncs(spy.start, spy.listen)()
+
+	lambda.ServeURL(http.NotFoundHandler(), nullLog, nullLog, lambda.WithRequestIdHeader())
+
+	if !spy.lambdaStarted {
+		t.Error("lambda should have been started")
+	}
+}
@@ -1,5 +1,5 @@
 module github.com/d-velop/dvelop-sdk-go/lambda
 
-require github.com/aws/aws-lambda-go v1.17.0
+require github.com/aws/aws-lambda-go v1.38.0
 
-go 1.13
+go 1.18
@@ -0,0 +1,34 @@
+package lambda
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+const lambdaRuntimeApiEnvVar = "AWS_LAMBDA_RUNTIME_API"
+
+var listenAndServe = http.ListenAndServe
+
+// ServeOrLocal serves AWS APIGatewayProxyRequests like Serve if it is running in a lambda environment.
+// Otherwise it starts a regular http server which listens on addr.
+//
+// The lambda environment is detected by the environment variable AWS_LAMBDA_RUNTIME_API.
+//
+// Example:
+//
+//	func main(){
+//		//...
+//		lambda.ServeOrLocal(handler, ":8080", logerror, loginfo)
+//	}
+func ServeOrLocal(handler http.Handler, addr string, logerror, loginfo func(ctx context.Context, logmessage string), options ...Option) {
+	if os.Getenv(lambdaRuntimeApiEnvVar) != "" {
+		Serve(handler, logerror, loginfo, options...)
+		return
+	}
+	loginfo(context.Background(), fmt.Sprintf("%s is not set. Starting local http server on '%s'", lambdaRuntimeApiEnvVar, addr))
+	if err := listenAndServe(addr, handler); err != nil {
+		logerror(context.Background(), fmt.Sprintf("local http server stopped because: %v", err))
+	}
+}
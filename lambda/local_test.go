@@ -0,0 +1,55 @@
+package lambda_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/d-velop/dvelop-sdk-go/lambda"
+)
+
+type serveSpy struct {
+	lambdaStarted bool
+	listenAddr    string
+}
+
+func (s *serveSpy) start(handler interface{}) {
+	s.lambdaStarted = true
+}
+
+func (s *serveSpy) listen(addr string, handler http.Handler) error {
+	s.listenAddr = addr
+	return errors.New("server closed")
+}
+
+func TestLambdaRuntimeApiEnvVarIsSet_ServeOrLocal_StartsLambda(t *testing.T) {
+	os.Setenv("AWS_LAMBDA_RUNTIME_API", "127.0.0.1:9001")
+	defer os.Unsetenv("AWS_LAMBDA_RUNTIME_API")
+	spy := &serveSpy{}
+	defer lambda.SetServeFuncs(spy.start, spy.listen)()
+
+	lambda.ServeOrLocal(http.NotFoundHandler(), ":8080", nullLog, nullLog)
+
+	if !spy.lambdaStarted {
+		t.Error("lambda should have been started")
+	}
+	if spy.listenAddr != "" {
+		t.Errorf("local http server should not have been started but listened on '%v'", spy.listenAddr)
+	}
+}
+
+func TestLambdaRuntimeApiEnvVarIsNotSet_ServeOrLocal_StartsLocalServer(t *testing.T) {
+	os.Unsetenv("AWS_LAMBDA_RUNTIME_API")
+	spy := &serveSpy{}
+	defer lambda.SetServeFuncs(spy.start, spy.listen)()
+
+	lambda.ServeOrLocal(http.NotFoundHandler(), ":8080", nullLog, nullLog)
+
+	if spy.lambdaStarted {
+		t.Error("lambda should not have been started")
+	}
+	if spy.listenAddr != ":8080" {
+		t.Errorf("got listen address '%v', want '%v'", spy.listenAddr, ":8080")
+	}
+}
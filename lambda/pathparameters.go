@@ -0,0 +1,20 @@
+package lambda
+
+import (
+	"context"
+)
+
+const pathParametersCtxKey = contextKey("pathParameters")
+
+// AddPathParametersToCtx adds the path parameters of an APIGatewayProxyRequest to the context
+func AddPathParametersToCtx(ctx context.Context, pathParameters map[string]string) context.Context {
+	return context.WithValue(ctx, pathParametersCtxKey, pathParameters)
+}
+
+// PathParametersFromCtx reads the path parameters which API Gateway parsed from the request path, e.g. the
+// parameter 'id' of the resource '/users/{id}', from the context.
+// The second return value is false if the request has no path parameters.
+func PathParametersFromCtx(ctx context.Context) (map[string]string, bool) {
+	pathParameters, ok := ctx.Value(pathParametersCtxKey).(map[string]string)
+	return pathParameters, ok
+}
@@ -26,6 +26,7 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"mime"
 	"net/http"
 	"net/url"
 	"strings"
@@ -42,16 +43,49 @@ import (
 //		//...
 //		lambda.Serve (handler, logerror, loginfo)
 //	}
-func Serve(handler http.Handler, logerror, loginfo func(ctx context.Context, logmessage string)) {
-	lambda.Start(AdaptorFunc(handler, logerror, loginfo))
+func Serve(handler http.Handler, logerror, loginfo func(ctx context.Context, logmessage string), options ...Option) {
+	startLambda(AdaptorFunc(handler, logerror, loginfo, options...))
 }
 
+var startLambda = lambda.Start
+
+// Option configures the adaptor created by AdaptorFunc, ALBAdaptorFunc or AdaptorFuncURL.
+type Option func(*adaptorConfig)
+
+type adaptorConfig struct {
+	requestIdHeader            bool
+	systemBaseUriStageVariable string
+	tenantIdStageVariable      string
+	setSystemBaseUri           func(ctx context.Context, systemBaseUri string) context.Context
+	setTenantId                func(ctx context.Context, tenantId string) context.Context
+}
+
+// WithRequestIdHeader adds the lambda request ID as X-Aws-Request-Id header to every response.
+func WithRequestIdHeader() Option {
+	return func(c *adaptorConfig) {
+		c.requestIdHeader = true
+	}
+}
+
+const requestIdHeader = "X-Aws-Request-Id"
+
 // AdaptorFunc adapts a regular http.Handler to an AWS lambda handler
-func AdaptorFunc(handler http.Handler, logerror, loginfo func(ctx context.Context, logmessage string)) func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+func AdaptorFunc(handler http.Handler, logerror, loginfo func(ctx context.Context, logmessage string), options ...Option) func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	cfg := &adaptorConfig{}
+	for _, o := range options {
+		o(cfg)
+	}
 	fn := func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 		if lc, success := lambdacontext.FromContext(ctx); success {
 			ctx = AddReqIdToCtx(ctx, lc.AwsRequestID)
 		}
+		if request.RequestContext.Authorizer != nil {
+			ctx = AddAuthorizerToCtx(ctx, request.RequestContext.Authorizer)
+		}
+		if request.PathParameters != nil {
+			ctx = AddPathParametersToCtx(ctx, request.PathParameters)
+		}
+		ctx = cfg.addStageVariablesToCtx(ctx, request.StageVariables)
 		loginfo(ctx, fmt.Sprintf("Received APIGatewayRequest '%v'", request.RequestContext.RequestID))
 		respw := &responseWriter{header: http.Header{}, body: &bytes.Buffer{}}
 		req, err := newRequest(&request)
@@ -60,7 +94,13 @@ func AdaptorFunc(handler http.Handler, logerror, loginfo func(ctx context.Contex
 			resp := events.APIGatewayProxyResponse{Body: http.StatusText(http.StatusInternalServerError), StatusCode: http.StatusInternalServerError}
 			return resp, nil
 		}
+		ctx = addTraceIdFromRequestToCtx(ctx, req)
 		handler.ServeHTTP(respw, req.WithContext(ctx))
+		if cfg.requestIdHeader {
+			if reqId, err := ReqIdFromCtx(ctx); err == nil {
+				respw.setResponseHeader(requestIdHeader, reqId)
+			}
+		}
 		resp, err := respw.response()
 		if err != nil {
 			logerror(ctx, fmt.Sprint(err))
@@ -74,7 +114,7 @@ func AdaptorFunc(handler http.Handler, logerror, loginfo func(ctx context.Contex
 
 func newRequest(evt *events.APIGatewayProxyRequest) (*http.Request, error) {
 	req := &http.Request{
-		Method: mapMethod(evt),
+		Method: mapMethod(evt.HTTPMethod),
 		URL:    mapURL(evt),
 		Header: *mapHeader(evt),
 	}
@@ -97,8 +137,8 @@ func newRequest(evt *events.APIGatewayProxyRequest) (*http.Request, error) {
 	return req, nil
 }
 
-func mapMethod(e *events.APIGatewayProxyRequest) string {
-	switch strings.ToUpper(e.HTTPMethod) {
+func mapMethod(httpMethod string) string {
+	switch strings.ToUpper(httpMethod) {
 	case "GET":
 		return http.MethodGet
 	case "POST":
@@ -159,21 +199,66 @@ func (rw *responseWriter) response() (*events.APIGatewayProxyResponse, error) {
 		response.MultiValueHeaders = rw.snapHeader
 	}
 
-	if rw.body.Len() > 0 {
-		b, err := ioutil.ReadAll(rw.body)
-		if err != nil {
-			return nil, err
-		}
-		response.Body = string(b)
+	body, isBase64Encoded, err := rw.encodedBody()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = body
+	response.IsBase64Encoded = isBase64Encoded
+
+	response.StatusCode = rw.status()
+
+	return response, nil
+}
+
+// encodedBody returns the body written by the handler. Binary bodies are base64 encoded.
+func (rw *responseWriter) encodedBody() (string, bool, error) {
+	if rw.body.Len() == 0 {
+		return "", false, nil
 	}
+	b, err := ioutil.ReadAll(rw.body)
+	if err != nil {
+		return "", false, err
+	}
+	if isBinaryMediaType(rw.snapHeader.Get("Content-Type")) {
+		return base64.StdEncoding.EncodeToString(b), true, nil
+	}
+	return string(b), false, nil
+}
 
+func (rw *responseWriter) status() int {
 	if rw.statusCode == 0 {
 		rw.statusCode = http.StatusOK
 	}
+	return rw.statusCode
+}
 
-	response.StatusCode = rw.statusCode
+// binaryMediaTypes are the media types of response bodies which are returned base64 encoded.
+// A trailing '*' matches all subtypes of a type.
+var binaryMediaTypes = []string{
+	"image/*",
+	"audio/*",
+	"video/*",
+	"application/octet-stream",
+	"application/pdf",
+	"application/zip",
+}
 
-	return response, nil
+func isBinaryMediaType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, b := range binaryMediaTypes {
+		if strings.HasSuffix(b, "/*") {
+			if strings.HasPrefix(mediaType, strings.TrimSuffix(b, "*")) {
+				return true
+			}
+		} else if mediaType == b {
+			return true
+		}
+	}
+	return false
 }
 
 func (rw *responseWriter) Header() http.Header {
@@ -213,6 +298,14 @@ func (rw *responseWriter) WriteHeader(statusCode int) {
 	rw.snapHeader = cloneHeader(rw.header)
 }
 
+// setResponseHeader sets a header of the response after the handler has finished.
+func (rw *responseWriter) setResponseHeader(key, value string) {
+	if rw.snapHeader == nil {
+		rw.snapHeader = http.Header{}
+	}
+	rw.snapHeader.Set(key, value)
+}
+
 func cloneHeader(h http.Header) http.Header {
 	h2 := make(http.Header, len(h))
 	for k, v := range h {
@@ -9,10 +9,12 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"os"
 	"reflect"
 	"testing"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
 	"github.com/d-velop/dvelop-sdk-go/lambda"
 )
 
@@ -262,6 +264,25 @@ func TestAdaptor_HandlerSetsHeaderWithMultipleValues_ReturnsHeaderWithMultipleVa
 	}
 }
 
+func TestAdaptor_HandlerSetsTwoCookies_ReturnsTwoSetCookieValuesAndNoSingleValueHeaders(t *testing.T) {
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "a", Value: "1"})
+		http.SetCookie(w, &http.Cookie{Name: "b", Value: "2"})
+		w.WriteHeader(http.StatusOK)
+	}}
+
+	handler := lambda.AdaptorFunc(spy, nullLog, nullLog)
+	resp, _ := handler(context.Background(), events.APIGatewayProxyRequest{})
+
+	expected := map[string][]string{"Set-Cookie": {"a=1", "b=2"}}
+	if !reflect.DeepEqual(resp.MultiValueHeaders, expected) {
+		t.Errorf("Serve: should return headers '%v' set by handler but returned headers '%v' ", expected, resp.MultiValueHeaders)
+	}
+	if resp.Headers != nil {
+		t.Errorf("Serve: should return nil single value headers but returned '%v'", resp.Headers)
+	}
+}
+
 func TestAdaptor_HandlerModifiesHeaderAfterCallingWriteHeader_ReturnsUnmodifiedHeaders(t *testing.T) {
 	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("X-Header", "value")
@@ -358,4 +379,296 @@ func TestAdaptor_HandlerModifiesHeaderAfterWriteAndCallsWriteHeader_ReturnsUnmod
 	}
 }
 
+func TestAdaptor_HandlerWritesBinaryBody_ReturnsBase64EncodedBody(t *testing.T) {
+	pngHeader := []byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A, 0x00, 0x00, 0x00, 0x0D, 0x49, 0x48, 0x44, 0x52}
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(pngHeader)
+	}}
+
+	handler := lambda.AdaptorFunc(spy, nullLog, nullLog)
+	resp, _ := handler(context.Background(), events.APIGatewayProxyRequest{})
+
+	if !resp.IsBase64Encoded {
+		t.Error("Serve: should return base64 encoded body for binary content but IsBase64Encoded was false")
+	}
+	b, err := base64.StdEncoding.DecodeString(resp.Body)
+	if err != nil {
+		t.Fatalf("Serve: should return valid base64 body but decoding failed: %v", err)
+	}
+	if !bytes.Equal(b, pngHeader) {
+		t.Errorf("Serve: should return body '%v' set by handler but returned body '%v' ", pngHeader, b)
+	}
+	expected := map[string][]string{"Content-Type": {"image/png"}}
+	if !reflect.DeepEqual(resp.MultiValueHeaders, expected) {
+		t.Errorf("Serve: should return headers '%v' set by handler but returned headers '%v' ", expected, resp.MultiValueHeaders)
+	}
+}
+
+func TestAdaptor_HandlerSetsBinaryContentTypeAndCallsWrite_ReturnsBase64EncodedBody(t *testing.T) {
+	body := []byte("%PDF-1.4")
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		_, _ = w.Write(body)
+	}}
+
+	handler := lambda.AdaptorFunc(spy, nullLog, nullLog)
+	resp, _ := handler(context.Background(), events.APIGatewayProxyRequest{})
+
+	if !resp.IsBase64Encoded {
+		t.Error("Serve: should return base64 encoded body for binary content but IsBase64Encoded was false")
+	}
+	if resp.Body != base64.StdEncoding.EncodeToString(body) {
+		t.Errorf("Serve: should return body '%v' but returned body '%v' ", base64.StdEncoding.EncodeToString(body), resp.Body)
+	}
+}
+
+func TestAdaptor_HandlerWritesTextBody_ReturnsPlainBody(t *testing.T) {
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"Key": "value"}`)
+	}}
+
+	handler := lambda.AdaptorFunc(spy, nullLog, nullLog)
+	resp, _ := handler(context.Background(), events.APIGatewayProxyRequest{})
+
+	if resp.IsBase64Encoded {
+		t.Error("Serve: should return plain body for text content but IsBase64Encoded was true")
+	}
+	if resp.Body != `{"Key": "value"}` {
+		t.Errorf("Serve: should return body '%v' but returned body '%v' ", `{"Key": "value"}`, resp.Body)
+	}
+}
+
+func TestAdaptorWithRequestIdHeader_HandlerCallsWrite_ReturnsRequestIdHeader(t *testing.T) {
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, "Hello World")
+	}}
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "7e1f2b4a-2c36-4b5b-9a1e-6f0c8d7e5a12"})
+
+	handler := lambda.AdaptorFunc(spy, nullLog, nullLog, lambda.WithRequestIdHeader())
+	resp, _ := handler(ctx, events.APIGatewayProxyRequest{})
+
+	if got := http.Header(resp.MultiValueHeaders).Get("X-Aws-Request-Id"); got != "7e1f2b4a-2c36-4b5b-9a1e-6f0c8d7e5a12" {
+		t.Errorf("Serve: should return header X-Aws-Request-Id '%v' but returned '%v'", "7e1f2b4a-2c36-4b5b-9a1e-6f0c8d7e5a12", got)
+	}
+}
+
+func TestAdaptorWithRequestIdHeader_HandlerDoesNothing_ReturnsRequestIdHeader(t *testing.T) {
+	spy := &handlerSpy{}
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "7e1f2b4a-2c36-4b5b-9a1e-6f0c8d7e5a12"})
+
+	handler := lambda.AdaptorFunc(spy, nullLog, nullLog, lambda.WithRequestIdHeader())
+	resp, _ := handler(ctx, events.APIGatewayProxyRequest{})
+
+	if got := http.Header(resp.MultiValueHeaders).Get("X-Aws-Request-Id"); got != "7e1f2b4a-2c36-4b5b-9a1e-6f0c8d7e5a12" {
+		t.Errorf("Serve: should return header X-Aws-Request-Id '%v' but returned '%v'", "7e1f2b4a-2c36-4b5b-9a1e-6f0c8d7e5a12", got)
+	}
+}
+
+func TestAdaptorWithoutRequestIdHeader_HandlerCallsWrite_ReturnsNoRequestIdHeader(t *testing.T) {
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, "Hello World")
+	}}
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "7e1f2b4a-2c36-4b5b-9a1e-6f0c8d7e5a12"})
+
+	handler := lambda.AdaptorFunc(spy, nullLog, nullLog)
+	resp, _ := handler(ctx, events.APIGatewayProxyRequest{})
+
+	if got := http.Header(resp.MultiValueHeaders).Get("X-Aws-Request-Id"); got != "" {
+		t.Errorf("Serve: should return no header X-Aws-Request-Id but returned '%v'", got)
+	}
+}
+
+func TestAdaptor_RequestWithTraceIdHeader_InvokesHandlerWithTraceIdOnContext(t *testing.T) {
+	var traceId string
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		traceId, _ = lambda.TraceIdFromCtx(r.Context())
+	}}
+
+	handler := lambda.AdaptorFunc(spy, nullLog, nullLog)
+	_, _ = handler(context.Background(), events.APIGatewayProxyRequest{
+		Headers: map[string]string{"X-Amzn-Trace-Id": "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1"},
+	})
+
+	if traceId != "1-5759e988-bd862e3fe1be46a994272793" {
+		t.Errorf("Serve: should add trace id '%v' to context but added '%v'", "1-5759e988-bd862e3fe1be46a994272793", traceId)
+	}
+}
+
+func TestAdaptor_RequestWithoutTraceIdHeaderAndTraceIdEnvVar_InvokesHandlerWithTraceIdFromEnvVarOnContext(t *testing.T) {
+	_ = os.Setenv("_X_AMZN_TRACE_ID", "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1")
+	defer os.Unsetenv("_X_AMZN_TRACE_ID")
+	var traceId string
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		traceId, _ = lambda.TraceIdFromCtx(r.Context())
+	}}
+
+	handler := lambda.AdaptorFunc(spy, nullLog, nullLog)
+	_, _ = handler(context.Background(), events.APIGatewayProxyRequest{})
+
+	if traceId != "1-5759e988-bd862e3fe1be46a994272793" {
+		t.Errorf("Serve: should add trace id '%v' to context but added '%v'", "1-5759e988-bd862e3fe1be46a994272793", traceId)
+	}
+}
+
+func TestAdaptor_RequestWithoutTraceId_InvokesHandlerWithoutTraceIdOnContext(t *testing.T) {
+	_ = os.Unsetenv("_X_AMZN_TRACE_ID")
+	var err error
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		_, err = lambda.TraceIdFromCtx(r.Context())
+	}}
+
+	handler := lambda.AdaptorFunc(spy, nullLog, nullLog)
+	_, _ = handler(context.Background(), events.APIGatewayProxyRequest{})
+
+	if err == nil {
+		t.Error("Serve: should add no trace id to context")
+	}
+}
+
+func TestAdaptor_RequestWithAuthorizer_InvokesHandlerWithAuthorizerOnContext(t *testing.T) {
+	var authorizer map[string]interface{}
+	var principalId string
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		authorizer, _ = lambda.AuthorizerContext(r.Context())
+		principalId, _ = lambda.PrincipalIDFromAuthorizerContext(r.Context())
+	}}
+
+	handler := lambda.AdaptorFunc(spy, nullLog, nullLog)
+	_, _ = handler(context.Background(), events.APIGatewayProxyRequest{
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: map[string]interface{}{"principalId": "4711", "tenantId": "a12be5"},
+		},
+	})
+
+	if authorizer["tenantId"] != "a12be5" {
+		t.Errorf("Serve: should add authorizer to context but added '%v'", authorizer)
+	}
+	if principalId != "4711" {
+		t.Errorf("Serve: should add principalId '%v' to context but added '%v'", "4711", principalId)
+	}
+}
+
+func TestAdaptor_RequestWithoutAuthorizer_InvokesHandlerWithoutAuthorizerOnContext(t *testing.T) {
+	var found bool
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		_, found = lambda.AuthorizerContext(r.Context())
+	}}
+
+	handler := lambda.AdaptorFunc(spy, nullLog, nullLog)
+	_, _ = handler(context.Background(), events.APIGatewayProxyRequest{})
+
+	if found {
+		t.Error("Serve: should add no authorizer to context")
+	}
+}
+
+func TestAdaptor_RequestWithPathParameters_InvokesHandlerWithPathParametersOnContext(t *testing.T) {
+	var pathParameters map[string]string
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		pathParameters, _ = lambda.PathParametersFromCtx(r.Context())
+	}}
+
+	handler := lambda.AdaptorFunc(spy, nullLog, nullLog)
+	_, _ = handler(context.Background(), events.APIGatewayProxyRequest{
+		Path:           "/myapp/users/4711",
+		PathParameters: map[string]string{"id": "4711"},
+	})
+
+	if pathParameters["id"] != "4711" {
+		t.Errorf("Serve: should add path parameters to context but added '%v'", pathParameters)
+	}
+}
+
+func TestAdaptor_RequestWithoutPathParameters_InvokesHandlerWithoutPathParametersOnContext(t *testing.T) {
+	var found bool
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		_, found = lambda.PathParametersFromCtx(r.Context())
+	}}
+
+	handler := lambda.AdaptorFunc(spy, nullLog, nullLog)
+	_, _ = handler(context.Background(), events.APIGatewayProxyRequest{})
+
+	if found {
+		t.Error("Serve: should add no path parameters to context")
+	}
+}
+
 var _, _ = lambda.ReqIdFromCtx(context.Background())
+
+func TestAdaptor_RequestWithStageVariables_InvokesHandlerWithStageVariablesOnContext(t *testing.T) {
+	var stageVariables map[string]string
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		stageVariables, _ = lambda.StageVariablesFromCtx(r.Context())
+	}}
+
+	handler := lambda.AdaptorFunc(spy, nullLog, nullLog)
+	_, _ = handler(context.Background(), events.APIGatewayProxyRequest{
+		StageVariables: map[string]string{"environment": "prod"},
+	})
+
+	if stageVariables["environment"] != "prod" {
+		t.Errorf("Serve: should add stage variables to context but added '%v'", stageVariables)
+	}
+}
+
+func TestAdaptor_RequestWithoutStageVariables_InvokesHandlerWithoutStageVariablesOnContext(t *testing.T) {
+	var found bool
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		_, found = lambda.StageVariablesFromCtx(r.Context())
+	}}
+
+	handler := lambda.AdaptorFunc(spy, nullLog, nullLog)
+	_, _ = handler(context.Background(), events.APIGatewayProxyRequest{})
+
+	if found {
+		t.Error("Serve: should add no stage variables to context")
+	}
+}
+
+type systemBaseUriKey struct{}
+
+type tenantIdKey struct{}
+
+func setSystemBaseUri(ctx context.Context, systemBaseUri string) context.Context {
+	return context.WithValue(ctx, systemBaseUriKey{}, systemBaseUri)
+}
+
+func setTenantId(ctx context.Context, tenantId string) context.Context {
+	return context.WithValue(ctx, tenantIdKey{}, tenantId)
+}
+
+func TestAdaptorWithStageVariableAsTenantContext_RequestWithStageVariables_InvokesHandlerWithTenantOnContext(t *testing.T) {
+	var systemBaseUri, tenantId string
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		systemBaseUri, _ = r.Context().Value(systemBaseUriKey{}).(string)
+		tenantId, _ = r.Context().Value(tenantIdKey{}).(string)
+	}}
+
+	handler := lambda.AdaptorFunc(spy, nullLog, nullLog, lambda.WithStageVariableAsTenantContext("systemBaseUri", "tenantId", setSystemBaseUri, setTenantId))
+	_, _ = handler(context.Background(), events.APIGatewayProxyRequest{
+		StageVariables: map[string]string{"systemBaseUri": "https://sample.example.com", "tenantId": "a12be5"},
+	})
+
+	if systemBaseUri != "https://sample.example.com" {
+		t.Errorf("Serve: should add systemBaseUri '%v' to context but added '%v'", "https://sample.example.com", systemBaseUri)
+	}
+	if tenantId != "a12be5" {
+		t.Errorf("Serve: should add tenantId '%v' to context but added '%v'", "a12be5", tenantId)
+	}
+}
+
+func TestAdaptorWithStageVariableAsTenantContext_RequestWithoutStageVariables_InvokesHandlerWithoutTenantOnContext(t *testing.T) {
+	var sbuFound, tenantFound bool
+	spy := &handlerSpy{handlerFunc: func(w http.ResponseWriter, r *http.Request) {
+		_, sbuFound = r.Context().Value(systemBaseUriKey{}).(string)
+		_, tenantFound = r.Context().Value(tenantIdKey{}).(string)
+	}}
+
+	handler := lambda.AdaptorFunc(spy, nullLog, nullLog, lambda.WithStageVariableAsTenantContext("systemBaseUri", "tenantId", setSystemBaseUri, setTenantId))
+	_, _ = handler(context.Background(), events.APIGatewayProxyRequest{})
+
+	if sbuFound || tenantFound {
+		t.Error("Serve: should add no tenant to context")
+	}
+}
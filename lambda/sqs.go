@@ -0,0 +1,38 @@
+package lambda
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+// NewSQSHandler returns an AWS lambda handler for events of an SQS queue which calls messageHandler for each
+// message of the event.
+//
+// Messages for which messageHandler returns an error are reported as batch item failures, so that only these
+// messages return to the queue. This requires ReportBatchItemFailures to be enabled for the event source mapping.
+//
+// Example:
+//
+//	func main() {
+//		//...
+//		lambda.Start(lambda.NewSQSHandler(handleMessage, logerror))
+//	}
+func NewSQSHandler(messageHandler func(ctx context.Context, msg events.SQSMessage) error, logerror func(ctx context.Context, logmessage string)) func(ctx context.Context, evt events.SQSEvent) (events.SQSEventResponse, error) {
+	fn := func(ctx context.Context, evt events.SQSEvent) (events.SQSEventResponse, error) {
+		if lc, success := lambdacontext.FromContext(ctx); success {
+			ctx = AddReqIdToCtx(ctx, lc.AwsRequestID)
+		}
+		resp := events.SQSEventResponse{BatchItemFailures: []events.SQSBatchItemFailure{}}
+		for _, msg := range evt.Records {
+			if err := messageHandler(ctx, msg); err != nil {
+				logerror(ctx, fmt.Sprintf("processing of SQS message '%v' failed because: %v", msg.MessageId, err))
+				resp.BatchItemFailures = append(resp.BatchItemFailures, events.SQSBatchItemFailure{ItemIdentifier: msg.MessageId})
+			}
+		}
+		return resp, nil
+	}
+	return fn
+}
@@ -0,0 +1,91 @@
+package lambda_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/d-velop/dvelop-sdk-go/lambda"
+)
+
+func sqsEvent(messageIds ...string) events.SQSEvent {
+	evt := events.SQSEvent{}
+	for _, id := range messageIds {
+		evt.Records = append(evt.Records, events.SQSMessage{MessageId: id, Body: "body of " + id})
+	}
+	return evt
+}
+
+func TestSQSEvent_SQSHandler_CallsMessageHandlerForEachMessage(t *testing.T) {
+	var bodies []string
+	handler := lambda.NewSQSHandler(func(ctx context.Context, msg events.SQSMessage) error {
+		bodies = append(bodies, msg.Body)
+		return nil
+	}, nullLog)
+
+	_, _ = handler(context.Background(), sqsEvent("1", "2", "3"))
+
+	expected := []string{"body of 1", "body of 2", "body of 3"}
+	if !reflect.DeepEqual(bodies, expected) {
+		t.Errorf("SQSHandler: should call message handler with '%v' but called it with '%v'", expected, bodies)
+	}
+}
+
+func TestMessageHandlerSucceeds_SQSHandler_ReturnsNoBatchItemFailures(t *testing.T) {
+	handler := lambda.NewSQSHandler(func(ctx context.Context, msg events.SQSMessage) error {
+		return nil
+	}, nullLog)
+
+	resp, err := handler(context.Background(), sqsEvent("1", "2"))
+
+	if err != nil {
+		t.Fatalf("SQSHandler: should return no error but returned '%v'", err)
+	}
+	if len(resp.BatchItemFailures) != 0 {
+		t.Errorf("SQSHandler: should return no batch item failures but returned '%v'", resp.BatchItemFailures)
+	}
+}
+
+func TestMessageHandlerFailsForSomeMessages_SQSHandler_ReturnsFailedMessagesAsBatchItemFailures(t *testing.T) {
+	var logged []string
+	logerror := func(ctx context.Context, logmessage string) {
+		logged = append(logged, logmessage)
+	}
+	handler := lambda.NewSQSHandler(func(ctx context.Context, msg events.SQSMessage) error {
+		if msg.MessageId == "2" || msg.MessageId == "4" {
+			return errors.New("failed")
+		}
+		return nil
+	}, logerror)
+
+	resp, err := handler(context.Background(), sqsEvent("1", "2", "3", "4"))
+
+	if err != nil {
+		t.Fatalf("SQSHandler: should return no error but returned '%v'", err)
+	}
+	expected := []events.SQSBatchItemFailure{{ItemIdentifier: "2"}, {ItemIdentifier: "4"}}
+	if !reflect.DeepEqual(resp.BatchItemFailures, expected) {
+		t.Errorf("SQSHandler: should return batch item failures '%v' but returned '%v'", expected, resp.BatchItemFailures)
+	}
+	if len(logged) != 2 {
+		t.Errorf("SQSHandler: should log 2 errors but logged '%v'", logged)
+	}
+}
+
+func TestLambdaContextWithRequestId_SQSHandler_CallsMessageHandlerWithRequestIdOnContext(t *testing.T) {
+	var reqId string
+	handler := lambda.NewSQSHandler(func(ctx context.Context, msg events.SQSMessage) error {
+		reqId, _ = lambda.ReqIdFromCtx(ctx)
+		return nil
+	}, nullLog)
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "7e1f2b4a-2c36-4b5b-9a1e-6f0c8d7e5a12"})
+
+	_, _ = handler(ctx, sqsEvent("1"))
+
+	if reqId != "7e1f2b4a-2c36-4b5b-9a1e-6f0c8d7e5a12" {
+		t.Errorf("SQSHandler: should add request id '%v' to context but added '%v'", "7e1f2b4a-2c36-4b5b-9a1e-6f0c8d7e5a12", reqId)
+	}
+}
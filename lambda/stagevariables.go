@@ -0,0 +1,49 @@
+package lambda
+
+import (
+	"context"
+)
+
+const stageVariablesCtxKey = contextKey("stageVariables")
+
+// AddStageVariablesToCtx adds the stage variables of an API Gateway stage to the context
+func AddStageVariablesToCtx(ctx context.Context, stageVariables map[string]string) context.Context {
+	return context.WithValue(ctx, stageVariablesCtxKey, stageVariables)
+}
+
+// StageVariablesFromCtx reads the stage variables of an API Gateway stage from the context.
+// The second return value is false if the request contains no stage variables.
+func StageVariablesFromCtx(ctx context.Context) (map[string]string, bool) {
+	stageVariables, ok := ctx.Value(stageVariablesCtxKey).(map[string]string)
+	return stageVariables, ok
+}
+
+// WithStageVariableAsTenantContext adds the values of the stage variables systemBaseUriKey and tenantIdKey
+// as systemBaseUri and tenantId to the context using the functions setSystemBaseUri and setTenantId.
+// Stage variables which are missing or empty are ignored.
+//
+// Example:
+//
+//	lambda.Serve(handler, logerror, loginfo, lambda.WithStageVariableAsTenantContext("systemBaseUri", "tenantId", tenant.SetSystemBaseUri, tenant.SetId))
+func WithStageVariableAsTenantContext(systemBaseUriKey, tenantIdKey string, setSystemBaseUri, setTenantId func(ctx context.Context, value string) context.Context) Option {
+	return func(c *adaptorConfig) {
+		c.systemBaseUriStageVariable = systemBaseUriKey
+		c.tenantIdStageVariable = tenantIdKey
+		c.setSystemBaseUri = setSystemBaseUri
+		c.setTenantId = setTenantId
+	}
+}
+
+func (c *adaptorConfig) addStageVariablesToCtx(ctx context.Context, stageVariables map[string]string) context.Context {
+	if stageVariables == nil {
+		return ctx
+	}
+	ctx = AddStageVariablesToCtx(ctx, stageVariables)
+	if systemBaseUri := stageVariables[c.systemBaseUriStageVariable]; c.systemBaseUriStageVariable != "" && systemBaseUri != "" {
+		ctx = c.setSystemBaseUri(ctx, systemBaseUri)
+	}
+	if tenantId := stageVariables[c.tenantIdStageVariable]; c.tenantIdStageVariable != "" && tenantId != "" {
+		ctx = c.setTenantId(ctx, tenantId)
+	}
+	return ctx
+}
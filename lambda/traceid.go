@@ -0,0 +1,54 @@
+package lambda
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const traceIdCtxKey = contextKey("traceId")
+
+const (
+	traceIdHeader = "X-Amzn-Trace-Id"
+	traceIdEnvVar = "_X_AMZN_TRACE_ID"
+)
+
+// AddTraceIdToCtx adds the AWS X-Ray trace ID to the context
+func AddTraceIdToCtx(ctx context.Context, traceId string) context.Context {
+	return context.WithValue(ctx, traceIdCtxKey, traceId)
+}
+
+// TraceIdFromCtx reads the AWS X-Ray trace ID from the context
+func TraceIdFromCtx(ctx context.Context) (string, error) {
+	traceId, ok := ctx.Value(traceIdCtxKey).(string)
+	if !ok {
+		return "", errors.New("no traceid on context")
+	}
+	return traceId, nil
+}
+
+// traceIdFromRequest reads the trace ID from the X-Amzn-Trace-Id header or, if the header is missing,
+// from the _X_AMZN_TRACE_ID environment variable which is set by the lambda runtime.
+// The header has the form Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1
+// of which only the value of Root is returned.
+func traceIdFromRequest(req *http.Request) string {
+	traceHeader := req.Header.Get(traceIdHeader)
+	if traceHeader == "" {
+		traceHeader = os.Getenv(traceIdEnvVar)
+	}
+	for _, part := range strings.Split(traceHeader, ";") {
+		if strings.HasPrefix(part, "Root=") {
+			return strings.TrimPrefix(part, "Root=")
+		}
+	}
+	return traceHeader
+}
+
+func addTraceIdFromRequestToCtx(ctx context.Context, req *http.Request) context.Context {
+	if traceId := traceIdFromRequest(req); traceId != "" {
+		return AddTraceIdToCtx(ctx, traceId)
+	}
+	return ctx
+}
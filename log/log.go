@@ -12,18 +12,57 @@ import (
 	"time"
 )
 
+// Level is the level of a Logger. A Logger only writes logstatements if its level is at least its min level.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
 type Logger struct {
 	mu           sync.Mutex // ensures atomic writes; protects the following fields
 	out          io.Writer
-	buf          []byte // for accumulating text to write
 	writeMessage []func(ctx context.Context, buf []byte, message string) []byte
+	level        Level
+	minLevel     Level
 }
 
-// New creates an new Logger.
+// New creates an new Logger with level LevelDebug.
 // The out variable determines the destination for logstatements.
 // The writeMessage variable determines callback functions which are invoked in the given order when writing the logstatement.
 func New(out io.Writer, writeMessage ...func(ctx context.Context, buf []byte, message string) []byte) *Logger {
-	return &Logger{out: out, writeMessage: writeMessage}
+	return &Logger{out: out, writeMessage: writeMessage, level: LevelDebug, minLevel: LevelDebug}
+}
+
+func newWithLevel(level Level, out io.Writer, writeMessage ...func(ctx context.Context, buf []byte, message string) []byte) *Logger {
+	l := New(out, writeMessage...)
+	l.level = level
+	return l
+}
+
+// SetLevel sets the level of the logger
+func (l *Logger) SetLevel(level Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.level = level
+}
+
+// SetMinLevel sets the min level of the logger.
+// Logstatements are suppressed if the level of the logger is below the min level,
+// e.g. log.StdDebug.SetMinLevel(log.LevelInfo) suppresses all debug logstatements.
+func (l *Logger) SetMinLevel(level Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.minLevel = level
+}
+
+func (l *Logger) enabled() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.level >= l.minLevel
 }
 
 // SetOuput sets the output destination for the logger
@@ -43,42 +82,49 @@ func (l *Logger) SetWriteMessage(writeMsgFunc ...func(ctx context.Context, buf [
 // Print writes v to the log.
 // Arguments are handled in the same manner as fmt.Print.
 func (l *Logger) Print(ctx context.Context, v ...interface{}) {
+	if !l.enabled() {
+		return
+	}
 	l.writeOutput(ctx, fmt.Sprint(v...))
 }
 
 // Print writes v to the log.
 // Arguments are handled in the same manner as fmt.Printf.
 func (l *Logger) Printf(ctx context.Context, format string, v ...interface{}) {
+	if !l.enabled() {
+		return
+	}
 	l.writeOutput(ctx, fmt.Sprintf(format, v...))
 }
 
 func (l *Logger) writeOutput(ctx context.Context, message string) {
+	buf := make([]byte, 0, len(message)+64) // per call buffer for accumulating text to write
+
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	l.buf = l.buf[:0]
 	for _, f := range l.writeMessage {
-		l.buf = f(ctx, l.buf, message)
+		buf = f(ctx, buf, message)
 	}
 
-	if len(l.buf) == 0 || l.buf[len(l.buf)-1] != '\n' {
-		l.buf = append(l.buf, '\n')
+	if len(buf) == 0 || buf[len(buf)-1] != '\n' {
+		buf = append(buf, '\n')
 	}
 
-	_, _ = l.out.Write(l.buf)
+	_, _ = l.out.Write(buf)
 }
 
 // StdDebug is the standard logger for debug messages
-var StdDebug = New(os.Stderr, newWriteMessageFunc("DEBUG"))
+var StdDebug = newWithLevel(LevelDebug, os.Stderr, newWriteMessageFunc("DEBUG"))
 
 // StdInfo is the standard logger for info messages
-var StdInfo = New(os.Stderr, newWriteMessageFunc("INFO"))
+var StdInfo = newWithLevel(LevelInfo, os.Stderr, newWriteMessageFunc("INFO"))
 
 // StdWarn is the standard logger for warn messages
-var StdWarn = New(os.Stderr, newWriteMessageFunc("WARN"))
+var StdWarn = newWithLevel(LevelWarn, os.Stderr, newWriteMessageFunc("WARN"))
 
 // StdError is the standard logger for error messages
-var StdError = New(os.Stderr, newWriteMessageFunc("ERROR"))
+var StdError = newWithLevel(LevelError, os.Stderr, newWriteMessageFunc("ERROR"))
 
 func newWriteMessageFunc(severity string) func(ctx context.Context, buf []byte, message string) []byte {
 	return func(ctx context.Context, buf []byte, message string) []byte {
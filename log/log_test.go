@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 	"regexp"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/d-velop/dvelop-sdk-go/log"
@@ -127,6 +129,76 @@ func TestMultipleWriteMessageFuncsDefined_Print_InvokesWriteMessageFuncsInOrder(
 	rec.OutputShouldBe("Hello from writer 1Hello from writer 2\n")
 }
 
+func TestConcurrentCalls_Print_WritesEveryMessageUncorrupted(t *testing.T) {
+	rec := newOutputRecorder(t)
+	l := log.New(rec, messageOnlyWriteMsgFunc)
+	const goroutines = 100
+
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.Print(context.Background(), "a message which is long enough to reveal interleaved writes")
+		}()
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(rec.String(), "\n"), "\n")
+	if len(lines) != goroutines {
+		t.Fatalf("got %v lines want %v", len(lines), goroutines)
+	}
+	for _, line := range lines {
+		if line != "a message which is long enough to reveal interleaved writes" {
+			t.Errorf("corrupted line '%v'", line)
+		}
+	}
+}
+
+func TestMinLevelAboveLevel_PrintAndPrintf_WritesNothing(t *testing.T) {
+	rec := newOutputRecorder(t)
+	l := log.New(rec, messageOnlyWriteMsgFunc)
+	l.SetLevel(log.LevelInfo)
+	l.SetMinLevel(log.LevelWarn)
+
+	l.Print(context.Background(), "message")
+	l.Printf(context.Background(), "message %v", 1)
+
+	rec.OutputShouldBe("")
+}
+
+func TestMinLevelEqualsLevel_Print_WritesMessage(t *testing.T) {
+	rec := newOutputRecorder(t)
+	l := log.New(rec, messageOnlyWriteMsgFunc)
+	l.SetLevel(log.LevelWarn)
+	l.SetMinLevel(log.LevelWarn)
+
+	l.Print(context.Background(), "message")
+
+	rec.OutputShouldBe("message\n")
+}
+
+func TestStdDebugMinLevelIsInfo_DebugAndInfo_WritesOnlyInfoMessage(t *testing.T) {
+	rec := newOutputRecorder(t)
+	log.StdDebug.SetOutput(rec)
+	log.StdDebug.SetMinLevel(log.LevelInfo)
+	log.StdInfo.SetOutput(rec)
+	log.StdInfo.SetMinLevel(log.LevelInfo)
+	defer func() {
+		log.StdDebug.SetMinLevel(log.LevelDebug)
+		log.StdInfo.SetMinLevel(log.LevelDebug)
+	}()
+
+	log.Debug(context.Background(), "debug message")
+	log.Info(context.Background(), "info message")
+
+	r, _ := regexp.Compile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z INFO info message\n$`)
+	actual := rec.String()
+	if !r.MatchString(actual) {
+		t.Errorf("'%v' doesn't match the pattern '<RFC3339 Timestamp> INFO info message\n'", actual)
+	}
+}
+
 func TestStdInfoWriteMessageUnchanged_Debug_WritesMessageWithTimestampAndInfo(t *testing.T) {
 	rec := newOutputRecorder(t)
 	log.StdDebug.SetOutput(rec)
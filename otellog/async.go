@@ -0,0 +1,202 @@
+package otellog
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+)
+
+type asyncWriter struct {
+	out      io.Writer
+	events   chan []byte
+	flushReq chan chan error
+	quit     chan struct{}
+	quitOnce sync.Once
+	stopped  chan struct{}
+	dropped  int64
+	err      error // first error of the output destination since the last flush. Only accessed by run and after stop.
+}
+
+// SetAsyncWriter replaces the output destination of the logger with a writer which writes the log statements
+// asynchronously in batches to the previous output destination.
+// At most bufferSize log statements are buffered. Further log statements are dropped until there is space
+// in the buffer again (cf. DroppedEvents).
+//
+// Call Flush or Shutdown before the program exits to make sure that all buffered log statements are written.
+//
+// Example:
+//
+//	func main() {
+//		otellog.SetAsyncWriter(1000)
+//		otellog.RegisterShutdownHook()
+//		defer otellog.Shutdown(context.Background())
+//		...
+//	}
+func SetAsyncWriter(bufferSize int) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	out := std.out
+	if w, ok := out.(*asyncWriter); ok {
+		w.stop()
+		out = w.out
+	}
+	std.out = newAsyncWriter(out, bufferSize)
+}
+
+// Flush blocks until all log statements which have been buffered by the async writer are written.
+// It returns the first error of the output destination since the last call of Flush.
+// Flush does nothing if SetAsyncWriter hasn't been called.
+func Flush() error {
+	if w, ok := Writer().(*asyncWriter); ok {
+		return w.flush()
+	}
+	return nil
+}
+
+// Shutdown writes all log statements which have been buffered by the async writer and stops the async writer.
+// Afterwards log statements are written synchronously to the output destination again.
+// Log statements of other goroutines wait until the buffered log statements are written, so that they aren't
+// written before or in between them.
+// Shutdown returns the error of ctx if ctx is done before all log statements are written. In this case the async writer
+// keeps running in the background and the logger switches back to synchronous writes as soon as it has finished.
+// Shutdown does nothing if SetAsyncWriter hasn't been called.
+func Shutdown(ctx context.Context) error {
+	std.mu.Lock()
+	w, ok := std.out.(*asyncWriter)
+	if !ok {
+		std.mu.Unlock()
+		return nil
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- w.stop()
+	}()
+	select {
+	case err := <-done:
+		std.out = w.out
+		std.mu.Unlock()
+		return err
+	case <-ctx.Done():
+		std.mu.Unlock()
+		go func() {
+			<-done
+			std.mu.Lock()
+			defer std.mu.Unlock()
+			if std.out == io.Writer(w) {
+				// log statements which have been buffered after ctx was done
+				if batch := w.drain(nil); len(batch) > 0 {
+					_, _ = w.out.Write(batch)
+				}
+				std.out = w.out
+			}
+		}()
+		return ctx.Err()
+	}
+}
+
+// DroppedEvents returns the number of log statements which have been dropped by the async writer
+// because its buffer was full.
+func DroppedEvents() int64 {
+	if w, ok := Writer().(*asyncWriter); ok {
+		return atomic.LoadInt64(&w.dropped)
+	}
+	return 0
+}
+
+// RegisterShutdownHook calls Shutdown if the program receives an interrupt or a SIGTERM signal.
+// The hook doesn't terminate the program. The program has to handle the signal itself, e.g. by shutting down its
+// http server. A second signal terminates the program as it would without the hook.
+func RegisterShutdownHook() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+		_ = Shutdown(context.Background())
+	}()
+}
+
+func newAsyncWriter(out io.Writer, bufferSize int) *asyncWriter {
+	w := &asyncWriter{
+		out:      out,
+		events:   make(chan []byte, bufferSize),
+		flushReq: make(chan chan error),
+		quit:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	e := make([]byte, len(p))
+	copy(e, p) // the caller may reuse p
+	select {
+	case w.events <- e:
+	default:
+		atomic.AddInt64(&w.dropped, 1)
+	}
+	return len(p), nil
+}
+
+func (w *asyncWriter) run() {
+	defer close(w.stopped)
+	var batch []byte
+	for {
+		select {
+		case e := <-w.events:
+			batch = w.drain(append(batch[:0], e...))
+			w.write(batch)
+		case ack := <-w.flushReq:
+			w.writeBuffered(batch[:0])
+			ack <- w.err
+			w.err = nil
+		case <-w.quit:
+			w.writeBuffered(batch[:0])
+			return
+		}
+	}
+}
+
+// drain appends the log statements which are currently buffered to batch
+func (w *asyncWriter) drain(batch []byte) []byte {
+	for n := len(w.events); n > 0; n-- {
+		batch = append(batch, <-w.events...)
+	}
+	return batch
+}
+
+func (w *asyncWriter) writeBuffered(batch []byte) {
+	batch = w.drain(batch)
+	if len(batch) > 0 {
+		w.write(batch)
+	}
+}
+
+func (w *asyncWriter) write(batch []byte) {
+	if _, err := w.out.Write(batch); err != nil && w.err == nil {
+		w.err = err
+	}
+}
+
+// flush returns the first error of the output destination since the last flush
+func (w *asyncWriter) flush() error {
+	ack := make(chan error, 1)
+	select {
+	case w.flushReq <- ack:
+		return <-ack
+	case <-w.stopped:
+		return w.err
+	}
+}
+
+func (w *asyncWriter) stop() error {
+	w.quitOnce.Do(func() {
+		close(w.quit)
+	})
+	<-w.stopped
+	return w.err
+}
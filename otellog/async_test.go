@@ -0,0 +1,210 @@
+package otellog_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	log "github.com/d-velop/dvelop-sdk-go/otellog"
+)
+
+func TestAsyncWriter_InfoAndFlush_WritesAllEvents(t *testing.T) {
+	rec := initializeLogger(t)
+	log.SetAsyncWriter(100)
+	defer log.Default().Reset()
+
+	for i := 0; i < 50; i++ {
+		log.Infof(context.Background(), "Log message %d", i)
+	}
+	log.Flush()
+
+	lines := strings.Split(strings.TrimSuffix(rec.String(), "\n"), "\n")
+	if len(lines) != 50 {
+		t.Fatalf("got %v lines want %v", len(lines), 50)
+	}
+	if lines[49] != "{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":9,\"body\":\"Log message 49\"}" {
+		t.Errorf("wrong last line '%v'", lines[49])
+	}
+}
+
+type blockingWriter struct {
+	strings.Builder
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	select {
+	case w.entered <- struct{}{}:
+		<-w.release
+	default:
+	}
+	return w.Builder.Write(p)
+}
+
+func TestAsyncWriterIsBlockedAndBufferIsFull_Info_DropsEvents(t *testing.T) {
+	initializeLogger(t)
+	out := &blockingWriter{entered: make(chan struct{}), release: make(chan struct{})}
+	log.SetOutput(out)
+	log.SetAsyncWriter(1)
+	defer log.Default().Reset()
+
+	log.Info(context.Background(), "first")
+	<-out.entered // first is being written
+	log.Info(context.Background(), "second")
+	log.Info(context.Background(), "dropped")
+	log.Info(context.Background(), "dropped")
+	close(out.release)
+	log.Flush()
+
+	if dropped := log.DroppedEvents(); dropped != 2 {
+		t.Errorf("got %v dropped events want %v", dropped, 2)
+	}
+	if got := out.String(); !strings.Contains(got, "first") || !strings.Contains(got, "second") || strings.Contains(got, "dropped") {
+		t.Errorf("wrong output '%v'", got)
+	}
+}
+
+func TestNoAsyncWriter_DroppedEvents_ReturnsZero(t *testing.T) {
+	initializeLogger(t)
+
+	log.Flush()
+
+	if dropped := log.DroppedEvents(); dropped != 0 {
+		t.Errorf("got %v dropped events want %v", dropped, 0)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestAsyncWriterAndOutputFails_Flush_ReturnsError(t *testing.T) {
+	initializeLogger(t)
+	log.SetOutput(failingWriter{})
+	log.SetAsyncWriter(10)
+	defer log.Default().Reset()
+
+	log.Info(context.Background(), "Log message")
+
+	if err := log.Flush(); err == nil {
+		t.Error("expected an error but got nil")
+	}
+	if err := log.Flush(); err != nil {
+		t.Errorf("expected no error after the error has been returned but got %v", err)
+	}
+}
+
+func TestAsyncWriter_InfoAndShutdown_WritesAllEventsAndWritesSynchronouslyAfterwards(t *testing.T) {
+	rec := initializeLogger(t)
+	log.SetAsyncWriter(100)
+	defer log.Default().Reset()
+
+	for i := 0; i < 10; i++ {
+		log.Infof(context.Background(), "Log message %d", i)
+	}
+	if err := log.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if lines := strings.Count(rec.String(), "\n"); lines != 10 {
+		t.Errorf("got %v lines want %v", lines, 10)
+	}
+	log.Info(context.Background(), "after shutdown")
+	if !strings.Contains(rec.String(), "after shutdown") {
+		t.Error("log statement after shutdown should have been written synchronously")
+	}
+}
+
+func TestAsyncWriterIsBlockedAndInfoDuringShutdown_Shutdown_WritesBufferedEventsFirst(t *testing.T) {
+	initializeLogger(t)
+	out := &blockingWriter{entered: make(chan struct{}), release: make(chan struct{})}
+	log.SetOutput(out)
+	log.SetAsyncWriter(10)
+	defer log.Default().Reset()
+
+	log.Info(context.Background(), "first")
+	<-out.entered // first is being written
+	log.Info(context.Background(), "second")
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- log.Shutdown(context.Background())
+	}()
+	time.Sleep(10 * time.Millisecond) // lets Shutdown start before the next log statement
+	logged := make(chan struct{})
+	go func() {
+		log.Info(context.Background(), "third")
+		close(logged)
+	}()
+	time.Sleep(10 * time.Millisecond) // gives the log statement the chance to be written before the buffered ones
+	close(out.release)
+	if err := <-shutdown; err != nil {
+		t.Fatal(err)
+	}
+	<-logged
+
+	got := out.String()
+	if first, second, third := strings.Index(got, "first"), strings.Index(got, "second"), strings.Index(got, "third"); first < 0 || first > second || second > third {
+		t.Errorf("wrong order of log statements '%v'", got)
+	}
+}
+
+func TestAsyncWriterIsBlockedAndContextIsDone_Shutdown_ReturnsContextError(t *testing.T) {
+	initializeLogger(t)
+	out := &blockingWriter{entered: make(chan struct{}), release: make(chan struct{})}
+	log.SetOutput(out)
+	log.SetAsyncWriter(10)
+	defer close(out.release)
+
+	log.Info(context.Background(), "first")
+	<-out.entered
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := log.Shutdown(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v want %v", err, context.Canceled)
+	}
+}
+
+func TestAsyncWriterIsBlockedAndContextIsDone_Shutdown_WritesSynchronouslyAfterAsyncWriterHasFinished(t *testing.T) {
+	initializeLogger(t)
+	out := &blockingWriter{entered: make(chan struct{}), release: make(chan struct{})}
+	log.SetOutput(out)
+	log.SetAsyncWriter(10)
+	defer log.Default().Reset()
+
+	log.Info(context.Background(), "first")
+	<-out.entered
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = log.Shutdown(ctx)
+
+	if log.Writer() == out {
+		t.Fatal("output destination should not be replaced while the async writer is still writing")
+	}
+	log.Info(context.Background(), "second")
+	close(out.release)
+	for deadline := time.Now().Add(time.Second); log.Writer() != out; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("output destination should be replaced after the async writer has finished")
+		}
+	}
+	log.Info(context.Background(), "third")
+
+	got := out.String()
+	if first, second, third := strings.Index(got, "first"), strings.Index(got, "second"), strings.Index(got, "third"); first < 0 || first > second || second > third {
+		t.Errorf("wrong order of log statements '%v'", got)
+	}
+}
+
+func TestNoAsyncWriter_Shutdown_ReturnsNil(t *testing.T) {
+	initializeLogger(t)
+
+	if err := log.Shutdown(context.Background()); err != nil {
+		t.Errorf("expected no error but got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package otellog
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"time"
+)
+
+type batchWriter struct {
+	mu            sync.Mutex
+	out           io.Writer
+	batchSize     int
+	flushInterval time.Duration
+	lines         [][]byte
+	timer         *time.Timer
+	closed        bool
+	err           error // first error of the output destination which hasn't been returned yet
+}
+
+// NewBatchWriter returns a writer which collects the log statements written to it and writes them
+// with a single call of out.Write as soon as batchSize log statements have been collected or flushInterval
+// has elapsed since the first log statement of the batch has been written, whichever comes first.
+// The returned writer is safe for concurrent use.
+//
+// Call Close before the program exits to write the remaining log statements.
+// Log statements which are written after Close are written to out immediately.
+//
+// Example:
+//
+//	func main() {
+//		w := otellog.NewBatchWriter(os.Stdout, 100, time.Second)
+//		otellog.SetOutput(w)
+//		defer w.Close()
+//		...
+//	}
+func NewBatchWriter(out io.Writer, batchSize int, flushInterval time.Duration) io.WriteCloser {
+	return &batchWriter{out: out, batchSize: batchSize, flushInterval: flushInterval}
+}
+
+func (w *batchWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return w.out.Write(p)
+	}
+	line := make([]byte, len(p))
+	copy(line, p) // the caller may reuse p
+	w.lines = append(w.lines, line)
+	if len(w.lines) >= w.batchSize {
+		w.flush()
+	} else if w.timer == nil && w.flushInterval > 0 {
+		w.timer = time.AfterFunc(w.flushInterval, w.flushOnTimer)
+	}
+	return len(p), w.takeErr()
+}
+
+// Close writes the remaining log statements and returns the first error of the output destination
+// which hasn't been returned by Write yet.
+func (w *batchWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.flush()
+	w.closed = true
+	return w.takeErr()
+}
+
+func (w *batchWriter) flushOnTimer() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.flush()
+}
+
+// flush writes the collected log statements. The caller must hold w.mu.
+func (w *batchWriter) flush() {
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	if len(w.lines) == 0 {
+		return
+	}
+	_, err := w.out.Write(bytes.Join(w.lines, nil))
+	if err != nil && w.err == nil {
+		w.err = err
+	}
+	w.lines = w.lines[:0]
+}
+
+func (w *batchWriter) takeErr() error {
+	err := w.err
+	w.err = nil
+	return err
+}
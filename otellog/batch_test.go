@@ -0,0 +1,141 @@
+package otellog_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	log "github.com/d-velop/dvelop-sdk-go/otellog"
+)
+
+type writeRecorder struct {
+	mu      sync.Mutex
+	writes  []string
+	written chan struct{}
+}
+
+func newWriteRecorder() *writeRecorder {
+	return &writeRecorder{written: make(chan struct{}, 100)}
+}
+
+func (r *writeRecorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.writes = append(r.writes, string(p))
+	select {
+	case r.written <- struct{}{}:
+	default:
+	}
+	return len(p), nil
+}
+
+func (r *writeRecorder) Writes() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.writes...)
+}
+
+func TestBatchWriter_WriteBatchSizeLines_WritesLinesWithSingleWrite(t *testing.T) {
+	out := newWriteRecorder()
+	w := log.NewBatchWriter(out, 3, time.Hour)
+	defer w.Close()
+
+	fmt.Fprint(w, "a\n")
+	fmt.Fprint(w, "b\n")
+	if writes := out.Writes(); len(writes) != 0 {
+		t.Fatalf("got writes %q before batch is full want none", writes)
+	}
+	fmt.Fprint(w, "c\n")
+
+	if writes := out.Writes(); len(writes) != 1 || writes[0] != "a\nb\nc\n" {
+		t.Errorf("got writes %q want %q", writes, []string{"a\nb\nc\n"})
+	}
+}
+
+func TestBatchWriter_FlushIntervalElapses_WritesLines(t *testing.T) {
+	out := newWriteRecorder()
+	w := log.NewBatchWriter(out, 100, 10*time.Millisecond)
+	defer w.Close()
+
+	fmt.Fprint(w, "a\n")
+	fmt.Fprint(w, "b\n")
+
+	select {
+	case <-out.written:
+	case <-time.After(time.Second):
+		t.Fatal("lines have not been written after flush interval")
+	}
+	if writes := out.Writes(); len(writes) != 1 || writes[0] != "a\nb\n" {
+		t.Errorf("got writes %q want %q", writes, []string{"a\nb\n"})
+	}
+}
+
+func TestBatchWriter_Close_WritesRemainingLines(t *testing.T) {
+	out := newWriteRecorder()
+	w := log.NewBatchWriter(out, 3, time.Hour)
+
+	fmt.Fprint(w, "a\n")
+	fmt.Fprint(w, "b\n")
+	fmt.Fprint(w, "c\n")
+	fmt.Fprint(w, "d\n")
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if writes := out.Writes(); len(writes) != 2 || writes[1] != "d\n" {
+		t.Errorf("got writes %q want %q", writes, []string{"a\nb\nc\n", "d\n"})
+	}
+}
+
+func TestBatchWriterIsClosed_Write_WritesLineImmediately(t *testing.T) {
+	out := newWriteRecorder()
+	w := log.NewBatchWriter(out, 3, time.Hour)
+	w.Close()
+
+	fmt.Fprint(w, "a\n")
+
+	if writes := out.Writes(); len(writes) != 1 || writes[0] != "a\n" {
+		t.Errorf("got writes %q want %q", writes, []string{"a\n"})
+	}
+}
+
+func TestBatchWriter_ConcurrentWrites_WritesAllLines(t *testing.T) {
+	out := newWriteRecorder()
+	w := log.NewBatchWriter(out, 7, time.Millisecond)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				fmt.Fprintf(w, "%d-%d\n", i, j)
+			}
+		}(i)
+	}
+	wg.Wait()
+	w.Close()
+
+	lines := strings.Split(strings.TrimSuffix(strings.Join(out.Writes(), ""), "\n"), "\n")
+	if len(lines) != 1000 {
+		t.Errorf("got %v lines want %v", len(lines), 1000)
+	}
+}
+
+func TestBatchWriter_SetOutputAndInfo_WritesEventsInBatches(t *testing.T) {
+	initializeLogger(t)
+	defer log.Default().Reset()
+	out := newWriteRecorder()
+	w := log.NewBatchWriter(out, 2, time.Hour)
+	log.SetOutput(w)
+
+	log.Info(context.Background(), "first")
+	log.Info(context.Background(), "second")
+
+	if writes := out.Writes(); len(writes) != 1 || strings.Count(writes[0], "\n") != 2 {
+		t.Errorf("got writes %q want a single write with two events", writes)
+	}
+}
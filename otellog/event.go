@@ -7,16 +7,18 @@ import (
 
 // An Event represents a structured logeevent inspired by the semantic model of OTEL (https://github.com/open-telemetry/opentelemetry-specification/blob/main/specification/logs/data-model.md)
 type Event struct {
-	Time       *time.Time  `json:"time,omitempty"`  // Time when the event occurred measured by the origin clock, normalized to UTC.
-	Severity   Severity    `json:"sev,omitempty"`   // Numerical value of the severity cf. Severity constants like SeverityInfo for possible values and their semantics
-	Name       string      `json:"name,omitempty"`  // Short event identifier that does not contain varying parts. Name describes what happened (e.g. "ProcessStarted"). Recommended to be no longer than 50 characters. Not guaranteed to be unique in any way. Typically used for filtering and grouping purposes in backends. Can be used to identify domain events like FeaturesRequested or UserLoggedIn (cf. example).
-	Body       interface{} `json:"body,omitempty"`  // A value containing the body of the log record. Can be for example a human-readable string message (including multi-line) describing the event in a free form or it can be a structured data composed of arrays and maps of other values. Can vary for each occurrence of the event coming from the same source.
-	TenantId   string      `json:"tn,omitempty"`    // ID of the tenant to which this event belongs.
-	TraceId    string      `json:"trace,omitempty"` // Request trace-id as defined in W3C Trace Context (https://www.w3.org/TR/trace-context/#trace-id) specification. That is the ID of the whole trace forest used to uniquely identify a distributed trace through a system.
-	SpanId     string      `json:"span,omitempty"`  // span-id. Can be set for logs that are part of a particular processing span. A span (https://opentracing.io/docs/overview/spans/) is the primary building block of a distributed trace, representing an individual unit of work done in a distributed system.
-	Resource   *Resource   `json:"res,omitempty"`   // Describes the source of the log. Multiple occurrences of events coming from the same event source can happen across time and they all have the same value of Resource. Can contain for example information about the application that emits the record or about the infrastructure where the application runs.
-	Attributes *Attributes `json:"attr,omitempty"`  // Additional information about the specific event occurrence. Unlike the Resource field, which is fixed for a particular source, Attributes can vary for each occurrence of the event coming from the same source. Can contain information about the request context (other than TraceId/SpanId).
-	Visibility *int        `json:"vis,omitempty"`   // Specifies if the logstatement is visible for tenant owner / customer. For now possible values are 1: true 0: false	1 is the default value, that is statements are visible if not explicitly denied by setting this value to 0
+	Time          *time.Time  `json:"time,omitempty"`  // Time when the event occurred measured by the origin clock, normalized to UTC.
+	Severity      Severity    `json:"sev,omitempty"`   // Numerical value of the severity cf. Severity constants like SeverityInfo for possible values and their semantics
+	Name          string      `json:"name,omitempty"`  // Short event identifier that does not contain varying parts. Name describes what happened (e.g. "ProcessStarted"). Recommended to be no longer than 50 characters. Not guaranteed to be unique in any way. Typically used for filtering and grouping purposes in backends. Can be used to identify domain events like FeaturesRequested or UserLoggedIn (cf. example).
+	Body          interface{} `json:"body,omitempty"`  // A value containing the body of the log record. Can be for example a human-readable string message (including multi-line) describing the event in a free form or it can be a structured data composed of arrays and maps of other values. Can vary for each occurrence of the event coming from the same source.
+	TenantId      string      `json:"tn,omitempty"`    // ID of the tenant to which this event belongs.
+	UserIdHash    string      `json:"uid,omitempty"`   // Hex encoded SHA-256 hash of the ID of the user who caused the event. The ID itself isn't logged to protect the privacy of the user.
+	TraceId       string      `json:"trace,omitempty"` // Request trace-id as defined in W3C Trace Context (https://www.w3.org/TR/trace-context/#trace-id) specification. That is the ID of the whole trace forest used to uniquely identify a distributed trace through a system.
+	SpanId        string      `json:"span,omitempty"`  // span-id. Can be set for logs that are part of a particular processing span. A span (https://opentracing.io/docs/overview/spans/) is the primary building block of a distributed trace, representing an individual unit of work done in a distributed system.
+	CorrelationId string      `json:"corr,omitempty"`  // Correlation id of the request. Used to correlate log events of a request across service boundaries in environments without W3C Trace Context support.
+	Resource      *Resource   `json:"res,omitempty"`   // Describes the source of the log. Multiple occurrences of events coming from the same event source can happen across time and they all have the same value of Resource. Can contain for example information about the application that emits the record or about the infrastructure where the application runs.
+	Attributes    *Attributes `json:"attr,omitempty"`  // Additional information about the specific event occurrence. Unlike the Resource field, which is fixed for a particular source, Attributes can vary for each occurrence of the event coming from the same source. Can contain information about the request context (other than TraceId/SpanId).
+	Visibility    *int        `json:"vis,omitempty"`   // Specifies if the logstatement is visible for tenant owner / customer. For now possible values are 1: true 0: false	1 is the default value, that is statements are visible if not explicitly denied by setting this value to 0
 }
 
 // A Resource describes the source of the log. Multiple occurrences of events coming from the same event source can happen across time and they all have the same value of res. Can contain for example information about the application that emits the record or about the infrastructure where the application runs.
@@ -78,7 +80,10 @@ type Exception struct {
 	Stacktrace string `json:"stacktrace,omitempty"` // A stacktrace as a string in the natural representation for the language runtime.
 }
 
-// AddAdditionalAttributes adds a struct-like interface to the Attributes
+// AddAdditionalAttributes adds a struct-like interface to the Attributes.
+// The top-level keys of additionalAttr are merged deeply into the previously added attributes.
+// If a key already exists the value of additionalAttr wins. This applies to the keys http, db and exception
+// of the fields Http, DB and Exception as well when the attributes are marshaled.
 func (attr *Attributes) AddAdditionalAttributes(additionalAttr interface{}) error {
 	if attr.additionalAttributes == nil {
 		attr.additionalAttributes = make(map[string]interface{})
@@ -103,19 +108,58 @@ func (attr Attributes) MarshalJSON() ([]byte, error) {
 		if err != nil {
 			return nil, err
 		}
+		// additional attributes win over http, db and exception if they use the same key
 		toMarshal = merge(attrMap, attr.additionalAttributes)
 	}
 	return json.Marshal(toMarshal)
 }
 
-// merge mapTwo in mapOne by keys
+// merge mapTwo deeply in mapOne by keys. Values of mapTwo win on conflicts.
+// Nested maps of mapOne are copied and not modified.
 func merge(mapOne map[string]interface{}, mapTwo map[string]interface{}) map[string]interface{} {
 	for k, v := range mapTwo {
+		if vMap, ok := v.(map[string]interface{}); ok {
+			if oneMap, ok := mapOne[k].(map[string]interface{}); ok {
+				mapOne[k] = merge(merge(make(map[string]interface{}), oneMap), vMap)
+				continue
+			}
+		}
 		mapOne[k] = v
 	}
 	return mapOne
 }
 
+// mergeResource returns a new Resource with the fields of existing overridden by the non-zero fields of incoming.
+// Neither existing nor incoming are modified.
+func mergeResource(existing, incoming *Resource) *Resource {
+	if existing == nil {
+		existing = &Resource{}
+	}
+	if incoming == nil {
+		incoming = &Resource{}
+	}
+	merged := &Resource{}
+	if existing.Service != nil || incoming.Service != nil {
+		svc := Service{}
+		if existing.Service != nil {
+			svc = *existing.Service
+		}
+		if in := incoming.Service; in != nil {
+			if in.Name != "" {
+				svc.Name = in.Name
+			}
+			if in.Version != "" {
+				svc.Version = in.Version
+			}
+			if in.Instance != "" {
+				svc.Instance = in.Instance
+			}
+		}
+		merged.Service = &svc
+	}
+	return merged
+}
+
 // toMap transform a struct-like interface to a map of interfaces
 func toMap(i interface{}) (iMap map[string]interface{}, err error) {
 	bytes, err := json.Marshal(i)
@@ -212,5 +256,6 @@ type Severity uint8
 const (
 	SeverityDebug = 5  // The information is meant for the developer of the app or component. The purpose it to follow the execution path while explicitly debugging a certain problem.
 	SeverityInfo  = 9  // The information is meant for the developer or operator of the own or other teams. In contrast to SeverityError this severity is used to emit events which work as designed.
+	SeverityWarn  = 13 // The information is meant for the developer or operator of the own or other teams. In contrast to SeverityError this severity is used to emit events which denote that something unexpected happened which doesn't affect the outcome of the current operation. For example a deprecated api was called or a fallback value had to be used.
 	SeverityError = 17 // The information is meant for the developer or operator of the own or other teams. In contrast to SeverityInfo this severity number is used to emit events which denote that something unexpected happened. Which can't be compensated in the own component. For example a failed outbound http request which can be compensated with a subsequent retry must not be logged as SeverityError. SeverityInfo must be used because from the outside everything works as expected. Whereas an inbound request which yields a 500 - internal server error must use SeverityError because there is no chance for the own component to recover.
 )
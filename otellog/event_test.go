@@ -305,3 +305,73 @@ func TestEventWithAdditionalProperties_Marshal_JsonObjectWithAllAdditionalProper
 		t.Errorf("\ngot   :'%v'\nwanted:'%v'", actualJsonString, expectedJsonString)
 	}
 }
+
+func TestAttributesWithAdditionalAttributes_AddAdditionalAttributesWithOverlappingKeys_MergesDeeplyAndLaterValueWins(t *testing.T) {
+	type Nested struct {
+		One int `json:"one,omitempty"`
+		Two int `json:"two,omitempty"`
+	}
+	type First struct {
+		A Nested `json:"a"`
+		C string `json:"c"`
+	}
+	type Second struct {
+		A Nested `json:"a"`
+		D string `json:"d"`
+	}
+
+	attr := log.Attributes{}
+	if err := attr.AddAdditionalAttributes(First{A: Nested{One: 1, Two: 2}, C: "c"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := attr.AddAdditionalAttributes(Second{A: Nested{Two: 3}, D: "d"}); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := json.Marshal(attr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := string(b)
+	expected := `{"a":{"one":1,"two":3},"c":"c","d":"d"}`
+	if actual != expected {
+		t.Errorf("\ngot   :'%v'\nwanted:'%v'", actual, expected)
+	}
+}
+
+func TestAttributesWithHttpAndAdditionalHttpAttribute_Marshal_AdditionalAttributeWins(t *testing.T) {
+	attr := log.Attributes{Http: &log.Http{Host: "testhost"}}
+	if err := attr.AddAdditionalAttributes(map[string]interface{}{"http": "custom", "c": "3"}); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := json.Marshal(attr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := string(b)
+	expected := `{"c":"3","http":"custom"}`
+	if actual != expected {
+		t.Errorf("\ngot   :'%v'\nwanted:'%v'", actual, expected)
+	}
+}
+
+func TestAttributesWithHttpAndAdditionalHttpMap_Marshal_MergesHttpProperty(t *testing.T) {
+	attr := log.Attributes{Http: &log.Http{Host: "testhost", Method: "GET"}}
+	if err := attr.AddAdditionalAttributes(map[string]interface{}{"http": map[string]interface{}{"method": "POST", "custom": "1"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := json.Marshal(attr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := string(b)
+	expected := `{"http":{"custom":"1","host":"testhost","method":"POST"}}`
+	if actual != expected {
+		t.Errorf("\ngot   :'%v'\nwanted:'%v'", actual, expected)
+	}
+}
@@ -1,3 +1,7 @@
 module github.com/d-velop/dvelop-sdk-go/otellog
 
 go 1.17
+
+require go.opentelemetry.io/otel/trace v1.11.1
+
+require go.opentelemetry.io/otel v1.11.1 // indirect
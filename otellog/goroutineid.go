@@ -0,0 +1,41 @@
+//go:build debug_goroutine_id
+// +build debug_goroutine_id
+
+package otellog
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+)
+
+// WithGoRoutineId adds the id of the calling goroutine as additional attribute "gid" to the log event.
+//
+// Goroutine ids are an undocumented implementation detail of the go runtime and reading them is slow.
+// So this option is only meant for debugging concurrency issues and is only available if the
+// build tag debug_goroutine_id is set.
+func (ob *LogBuilder) WithGoRoutineId() *LogBuilder {
+	return ob.WithAdditionalAttributes(struct {
+		Gid uint64 `json:"gid"`
+	}{goroutineId()})
+}
+
+// WithGoRoutineId adds the id of the calling goroutine as additional attribute "gid" to the log event.
+// Cf. LogBuilder.WithGoRoutineId.
+func WithGoRoutineId() *LogBuilder {
+	ob := &LogBuilder{}
+	ob.WithGoRoutineId()
+	return ob
+}
+
+// goroutineId parses the id from the first line of the stack which looks like "goroutine 18 [running]:"
+func goroutineId() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	if i := bytes.IndexByte(buf, ' '); i >= 0 {
+		buf = buf[:i]
+	}
+	id, _ := strconv.ParseUint(string(buf), 10, 64)
+	return id
+}
@@ -0,0 +1,31 @@
+//go:build debug_goroutine_id
+// +build debug_goroutine_id
+
+package otellog_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	log "github.com/d-velop/dvelop-sdk-go/otellog"
+)
+
+func TestLogMessageWithGoRoutineId_Info_AddsGidAttribute(t *testing.T) {
+	rec := initializeLogger(t)
+
+	log.WithGoRoutineId().Info(context.Background(), "Log message")
+
+	var e struct {
+		Attr struct {
+			Gid json.Number `json:"gid"`
+		} `json:"attr"`
+	}
+	if err := json.Unmarshal(rec.Bytes(), &e); err != nil {
+		t.Fatal(err)
+	}
+	gid, err := e.Attr.Gid.Int64()
+	if err != nil || gid <= 0 {
+		t.Errorf("gid should be an integer > 0 but was '%v'", e.Attr.Gid)
+	}
+}
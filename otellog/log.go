@@ -16,12 +16,18 @@ type Logger struct {
 	outputFormatter OutputFormatter
 	time            Time
 	hooks           []Hook
+	filters         []Filter
+	minSeverity     Severity
+	autoResource    bool // true if the hook of AutoDetectResource has been registered
 }
 
 type Time func() time.Time
 
 type Hook func(ctx context.Context, e *Event)
 
+// Filter decides whether a log statement with severity sev is written. Returning false drops the log statement.
+type Filter func(ctx context.Context, sev Severity) bool
+
 type OutputFormatter func(e *Event) ([]byte, error)
 
 // New creates a new Logger.
@@ -42,7 +48,13 @@ func Default() *Logger {
 func (l *Logger) Reset() {
 	l.mu.Lock()
 	defer l.mu.Unlock()
+	if w, ok := l.out.(*asyncWriter); ok {
+		w.stop()
+	}
 	l.hooks = nil
+	l.filters = nil
+	l.autoResource = false
+	l.minSeverity = SeverityDebug
 	l.out = os.Stdout
 	l.time = time.Now
 	l.outputFormatter = func(e *Event) ([]byte, error) {
@@ -55,6 +67,10 @@ func (l *Logger) output(ctx context.Context, sev Severity, msg interface{}, opti
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
+	if !l.shouldLog(ctx, sev) {
+		return
+	}
+
 	t := l.time()
 	e := Event{
 		Time:     &t,
@@ -91,6 +107,13 @@ func SetOutput(w io.Writer) {
 	std.out = w
 }
 
+// Writer returns the output destination for the logger.
+func Writer() io.Writer {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	return std.out
+}
+
 // SetTime sets the default clock for outputting the timestamp in the log statement.
 func SetTime(time Time) {
 	std.mu.Lock()
@@ -105,6 +128,55 @@ func SetOutputFormatter(f OutputFormatter) {
 	std.outputFormatter = f
 }
 
+// SetMinSeverity sets the minimum severity of the log statements which are written.
+// Log statements with a lower severity are dropped before any hook is called.
+func SetMinSeverity(sev Severity) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	std.minSeverity = sev
+}
+
+// ShouldLog reports whether a log statement with severity sev would be written by the standard logger.
+// It returns false if sev is below the minimum severity or if a filter registered with RegisterFilterHook returns false.
+// It can be used to skip expensive preparations of log messages which would be dropped anyway.
+//
+// Example:
+//
+//	if otellog.ShouldLog(ctx, otellog.SeverityDebug) {
+//		otellog.Debug(ctx, expensiveString())
+//	}
+func ShouldLog(ctx context.Context, sev Severity) bool {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	return std.shouldLog(ctx, sev)
+}
+
+func (l *Logger) shouldLog(ctx context.Context, sev Severity) bool {
+	if sev < l.minSeverity {
+		return false
+	}
+	for _, f := range l.filters {
+		if !f(ctx, sev) {
+			return false
+		}
+	}
+	return true
+}
+
+// RegisterFilterHook adds a filter which is called before the hooks for every log statement
+// that isn't below the minimum severity. The log statement is dropped if any filter returns false.
+//
+// Example (log debug statements only for requests which ask for it):
+//
+//	otellog.RegisterFilterHook(func(ctx context.Context, sev otellog.Severity) bool {
+//		return sev > otellog.SeverityDebug || debugEnabledFromCtx(ctx)
+//	})
+func RegisterFilterHook(f Filter) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	std.filters = append(std.filters, f)
+}
+
 // RegisterHook adds a callback function that will be called before the logger writes the log statement.
 // Inside the callback function the log event can be extended.
 func RegisterHook(h Hook) {
@@ -113,6 +185,74 @@ func RegisterHook(h Hook) {
 	std.hooks = append(std.hooks, h)
 }
 
+// RegisterTenantHook adds a hook which sets the TenantId of every log event to the tenant id returned by getFromCtx.
+// The TenantId remains unchanged if getFromCtx returns an error or an empty string.
+//
+// Example:
+//
+//	otellog.RegisterTenantHook(tenant.IdFromCtx)
+func RegisterTenantHook(getFromCtx func(ctx context.Context) (string, error)) {
+	RegisterHook(func(ctx context.Context, e *Event) {
+		if tenantId, err := getFromCtx(ctx); err == nil && tenantId != "" {
+			e.TenantId = tenantId
+		}
+	})
+}
+
+// RegisterPrincipalHook adds a hook which sets the UserIdHash of every log event to the SHA-256 hash of the
+// principal id returned by getFromCtx, like WithUserId does. The UserIdHash remains unchanged if getFromCtx
+// returns an error or an empty string.
+//
+// Example (use the principal of the idp middleware):
+//
+//	otellog.RegisterPrincipalHook(func(ctx context.Context) (string, error) {
+//		principal, err := idp.PrincipalFromCtx(ctx)
+//		return principal.Id, err
+//	})
+func RegisterPrincipalHook(getFromCtx func(ctx context.Context) (string, error)) {
+	RegisterHook(func(ctx context.Context, e *Event) {
+		if principalId, err := getFromCtx(ctx); err == nil && principalId != "" {
+			e.UserIdHash = hashUserId(principalId)
+		}
+	})
+}
+
+// RegisterCorrelationHook adds a hook which sets the CorrelationId of every log event to the id returned by fn.
+// The CorrelationId remains unchanged if fn returns an empty string.
+func RegisterCorrelationHook(fn func(ctx context.Context) string) {
+	RegisterHook(func(ctx context.Context, e *Event) {
+		if id := fn(ctx); id != "" {
+			e.CorrelationId = id
+		}
+	})
+}
+
+// AutoDetectResource adds a hook which sets the Resource of every log event to the service described by
+// the environment variables OTEL_SERVICE_NAME, OTEL_SERVICE_VERSION and HOSTNAME (as instance).
+// The environment variables are read when AutoDetectResource is called. Calling it multiple times has no further effect.
+//
+// A Resource which is set by an option of the log statement takes precedence over the detected one.
+func AutoDetectResource() {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	if std.autoResource {
+		return
+	}
+	std.autoResource = true
+	svc := Service{
+		Name:     os.Getenv("OTEL_SERVICE_NAME"),
+		Version:  os.Getenv("OTEL_SERVICE_VERSION"),
+		Instance: os.Getenv("HOSTNAME"),
+	}
+	if svc == (Service{}) {
+		return
+	}
+	std.hooks = append(std.hooks, func(ctx context.Context, e *Event) {
+		s := svc
+		e.Resource = &Resource{Service: &s}
+	})
+}
+
 // Debug logs an event body according to the otel definition
 func Debug(ctx context.Context, body interface{}) {
 	std.output(ctx, SeverityDebug, body, nil)
@@ -133,6 +273,16 @@ func Infof(ctx context.Context, format string, v ...interface{}) {
 	std.output(ctx, SeverityInfo, fmt.Sprintf(format, v...), nil)
 }
 
+// Warn logs an event body according to the otel definition
+func Warn(ctx context.Context, body interface{}) {
+	std.output(ctx, SeverityWarn, body, nil)
+}
+
+// Warnf formats the body according to a format specifier and logs it with SeverityWarn
+func Warnf(ctx context.Context, format string, v ...interface{}) {
+	std.output(ctx, SeverityWarn, fmt.Sprintf(format, v...), nil)
+}
+
 // Error logs an event body according to the otel definition
 func Error(ctx context.Context, body interface{}) {
 	std.output(ctx, SeverityError, body, nil)
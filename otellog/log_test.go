@@ -42,6 +42,7 @@ var severities = []struct {
 }{
 	{"Info", 9, log.Info, log.Infof},
 	{"Debug", 5, log.Debug, log.Debugf},
+	{"Warn", 13, log.Warn, log.Warnf},
 	{"Error", 17, log.Error, log.Errorf},
 }
 
@@ -117,6 +118,170 @@ func TestLogMessageWithRegisteredHook_Info_AddServiceAndWritesJSONToBuffer(t *te
 	rec.OutputShouldBe("{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":9,\"body\":\"Log message\",\"res\":{\"svc\":{\"name\":\"GoApplication\",\"ver\":\"1.0.0\",\"inst\":\"instanceId\"}}}\n")
 }
 
+type tenantCtxKey struct{}
+
+func tenantIdFromCtx(ctx context.Context) (string, error) {
+	tenantId, ok := ctx.Value(tenantCtxKey{}).(string)
+	if !ok {
+		return "", fmt.Errorf("no TenantId on context")
+	}
+	return tenantId, nil
+}
+
+func TestLogMessageWithRegisteredTenantHookAndTenantIdOnContext_Info_AddTenantIdAndWritesJSONToBuffer(t *testing.T) {
+	rec := initializeLogger(t)
+	log.RegisterTenantHook(tenantIdFromCtx)
+
+	log.Info(context.WithValue(context.Background(), tenantCtxKey{}, "a12be5"), "Log message")
+
+	rec.OutputShouldBe("{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":9,\"body\":\"Log message\",\"tn\":\"a12be5\"}\n")
+}
+
+func TestLogMessageWithRegisteredTenantHookAndNoTenantIdOnContext_Info_WritesJSONWithoutTenantIdToBuffer(t *testing.T) {
+	rec := initializeLogger(t)
+	log.RegisterTenantHook(tenantIdFromCtx)
+
+	log.Info(context.Background(), "Log message")
+
+	rec.OutputShouldBe("{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":9,\"body\":\"Log message\"}\n")
+}
+
+type principalCtxKey struct{}
+
+func principalIdFromCtx(ctx context.Context) (string, error) {
+	principalId, ok := ctx.Value(principalCtxKey{}).(string)
+	if !ok {
+		return "", fmt.Errorf("no principal on context")
+	}
+	return principalId, nil
+}
+
+func TestLogMessageWithRegisteredPrincipalHookAndPrincipalIdOnContext_Info_AddHashedUidAndWritesJSONToBuffer(t *testing.T) {
+	rec := initializeLogger(t)
+	log.RegisterPrincipalHook(principalIdFromCtx)
+
+	log.Info(context.WithValue(context.Background(), principalCtxKey{}, "4711"), "Log message")
+
+	rec.OutputShouldBe("{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":9,\"body\":\"Log message\",\"uid\":\"de650d61f5bd166a91f8ccec3158297db18b9d50eaedca238cd29dc3a214a916\"}\n")
+}
+
+func TestLogMessageWithRegisteredPrincipalHookAndNoPrincipalOnContext_Info_WritesJSONWithoutUidToBuffer(t *testing.T) {
+	rec := initializeLogger(t)
+	log.RegisterPrincipalHook(principalIdFromCtx)
+
+	log.Info(context.Background(), "Log message")
+
+	rec.OutputShouldBe("{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":9,\"body\":\"Log message\"}\n")
+}
+
+type correlationCtxKey struct{}
+
+func correlationIdFromCtx(ctx context.Context) string {
+	id, _ := ctx.Value(correlationCtxKey{}).(string)
+	return id
+}
+
+func TestLogMessageWithRegisteredCorrelationHookAndCorrelationIdOnContext_Info_AddCorrelationIdAndWritesJSONToBuffer(t *testing.T) {
+	rec := initializeLogger(t)
+	log.RegisterCorrelationHook(correlationIdFromCtx)
+
+	log.Info(context.WithValue(context.Background(), correlationCtxKey{}, "7a3f9c"), "Log message")
+
+	rec.OutputShouldBe("{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":9,\"body\":\"Log message\",\"corr\":\"7a3f9c\"}\n")
+}
+
+func TestLogMessageWithRegisteredCorrelationHookAndNoCorrelationIdOnContext_Info_WritesJSONWithoutCorrelationIdToBuffer(t *testing.T) {
+	rec := initializeLogger(t)
+	log.RegisterCorrelationHook(correlationIdFromCtx)
+
+	log.Info(context.Background(), "Log message")
+
+	rec.OutputShouldBe("{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":9,\"body\":\"Log message\"}\n")
+}
+
+func TestMinSeverityIsInfo_Debug_WritesNothingAndDoesNotCallHooks(t *testing.T) {
+	rec := initializeLogger(t)
+	log.SetMinSeverity(log.SeverityInfo)
+	hookCalled := false
+	log.RegisterHook(func(ctx context.Context, e *log.Event) {
+		hookCalled = true
+	})
+
+	log.Debug(context.Background(), "Log message")
+	log.Debugf(context.Background(), "Log %s", "message")
+
+	rec.OutputShouldBe("")
+	if hookCalled {
+		t.Error("hook should not be called for suppressed log statements")
+	}
+}
+
+func TestMinSeverityIsInfo_Info_WritesJSONToBuffer(t *testing.T) {
+	rec := initializeLogger(t)
+	log.SetMinSeverity(log.SeverityInfo)
+
+	log.Info(context.Background(), "Log message")
+
+	rec.OutputShouldBe("{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":9,\"body\":\"Log message\"}\n")
+}
+
+func TestMinSeverityIsInfo_ShouldLog_ReturnsFalseBelowInfo(t *testing.T) {
+	initializeLogger(t)
+	log.SetMinSeverity(log.SeverityInfo)
+
+	if log.ShouldLog(context.Background(), log.SeverityDebug) {
+		t.Error("ShouldLog should return false for SeverityDebug")
+	}
+}
+
+func TestMinSeverityIsInfo_ShouldLog_ReturnsTrueFromInfo(t *testing.T) {
+	initializeLogger(t)
+	log.SetMinSeverity(log.SeverityInfo)
+
+	for _, sev := range []log.Severity{log.SeverityInfo, log.SeverityWarn, log.SeverityError} {
+		if !log.ShouldLog(context.Background(), sev) {
+			t.Errorf("ShouldLog should return true for severity %v", sev)
+		}
+	}
+}
+
+type debugKey struct{}
+
+func debugFilter(ctx context.Context, sev log.Severity) bool {
+	return sev > log.SeverityDebug || ctx.Value(debugKey{}) != nil
+}
+
+func TestFilterHookReturnsFalse_ShouldLog_ReturnsFalse(t *testing.T) {
+	initializeLogger(t)
+	log.RegisterFilterHook(debugFilter)
+
+	if log.ShouldLog(context.Background(), log.SeverityDebug) {
+		t.Error("ShouldLog should return false if a filter hook returns false")
+	}
+	if !log.ShouldLog(context.WithValue(context.Background(), debugKey{}, true), log.SeverityDebug) {
+		t.Error("ShouldLog should return true if all filter hooks return true")
+	}
+	if !log.ShouldLog(context.Background(), log.SeverityInfo) {
+		t.Error("ShouldLog should return true for SeverityInfo")
+	}
+}
+
+func TestFilterHookReturnsFalse_Debug_WritesNothingAndDoesNotCallHooks(t *testing.T) {
+	rec := initializeLogger(t)
+	log.RegisterFilterHook(debugFilter)
+	hookCalled := false
+	log.RegisterHook(func(ctx context.Context, e *log.Event) {
+		hookCalled = true
+	})
+
+	log.Debug(context.Background(), "Log message")
+
+	rec.OutputShouldBe("")
+	if hookCalled {
+		t.Error("hook should not be called for filtered log statements")
+	}
+}
+
 func TestLogMessageWithCustomOutputFormatter_Info_WritesCustomFormatToBuffer(t *testing.T) {
 	rec := initializeLogger(t)
 	log.SetOutputFormatter(func(e *log.Event) ([]byte, error) {
@@ -179,3 +344,42 @@ func TestSeveralLogMessagesAtTheSameTime_Info_WritesJSONToBuffer(t *testing.T) {
 		"{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":9,\"body\":\"Log message\"}\n",
 		"{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":9,\"body\":\"Log message\"}\n"))
 }
+
+func TestServiceEnvVarsAndAutoDetectResource_Info_WritesJSONWithResourceToBuffer(t *testing.T) {
+	rec := initializeLogger(t)
+	t.Setenv("OTEL_SERVICE_NAME", "myservice")
+	t.Setenv("OTEL_SERVICE_VERSION", "1.2.3")
+	t.Setenv("HOSTNAME", "host-1")
+	log.AutoDetectResource()
+	log.AutoDetectResource()
+
+	log.Info(context.Background(), "Log message")
+
+	rec.OutputShouldBe("{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":9,\"body\":\"Log message\",\"res\":{\"svc\":{\"name\":\"myservice\",\"ver\":\"1.2.3\",\"inst\":\"host-1\"}}}\n")
+}
+
+func TestServiceEnvVarsAndAutoDetectResourceAndResourceOption_Info_WritesJSONWithResourceOfOptionToBuffer(t *testing.T) {
+	rec := initializeLogger(t)
+	t.Setenv("OTEL_SERVICE_NAME", "myservice")
+	t.Setenv("OTEL_SERVICE_VERSION", "")
+	t.Setenv("HOSTNAME", "")
+	log.AutoDetectResource()
+
+	log.With(func(e *log.Event) {
+		e.Resource = &log.Resource{Service: &log.Service{Name: "otherservice"}}
+	}).Info(context.Background(), "Log message")
+
+	rec.OutputShouldBe("{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":9,\"body\":\"Log message\",\"res\":{\"svc\":{\"name\":\"otherservice\"}}}\n")
+}
+
+func TestNoServiceEnvVarsAndAutoDetectResource_Info_WritesJSONWithoutResourceToBuffer(t *testing.T) {
+	rec := initializeLogger(t)
+	t.Setenv("OTEL_SERVICE_NAME", "")
+	t.Setenv("OTEL_SERVICE_VERSION", "")
+	t.Setenv("HOSTNAME", "")
+	log.AutoDetectResource()
+
+	log.Info(context.Background(), "Log message")
+
+	rec.OutputShouldBe("{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":9,\"body\":\"Log message\"}\n")
+}
@@ -1,6 +1,8 @@
 package otellog
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
@@ -33,6 +35,62 @@ func LogHttpRequest() func(http.Handler) http.Handler {
 	}
 }
 
+// LogStructured logs information about the request and response as structured events using the provided log function.
+//
+// The log function is called at the beginning and at the end of each request. Responses with a status code
+// of 500 and above are logged with SeverityError, everything else with SeverityInfo.
+// The values of the headers Authorization, Cookie and Set-Cookie are not logged.
+//
+// Example:
+//
+//	func main() {
+//		mux := http.NewServeMux()
+//		mux.Handle("/hello", otellog.LogStructured(func(ctx context.Context, sev otellog.Severity, e *otellog.Event) {
+//			l := otellog.With(func(o *otellog.Event) { o.Attributes = e.Attributes })
+//			if sev >= otellog.SeverityError {
+//				l.Error(ctx, e.Body)
+//			} else {
+//				l.Info(ctx, e.Body)
+//			}
+//		})(helloHandler()))
+//	}
+func LogStructured(log func(ctx context.Context, sev Severity, event *Event)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			before := time.Now()
+			log(r.Context(), SeverityInfo, structuredEvent(fmt.Sprintf("BEGIN request %v %v", r.Method, r.URL.Path), getHttpClientAttribute(r), r.Header))
+			lrw := newLogResponseWriter(w)
+			next.ServeHTTP(lrw, r)
+			h := getHttpClientAttribute(r)
+			h.StatusCode = uint16(lrw.statusCode)
+			h.Server = &Server{Duration: time.Since(before)}
+			sev := Severity(SeverityInfo)
+			if lrw.statusCode >= http.StatusInternalServerError {
+				sev = SeverityError
+			}
+			log(r.Context(), sev, structuredEvent(fmt.Sprintf("END request %v %v", r.Method, r.URL.Path), h, lrw.Header()))
+		})
+	}
+}
+
+func structuredEvent(body string, h *Http, header http.Header) *Event {
+	attr := &Attributes{Http: h}
+	_ = attr.AddAdditionalAttributes(map[string]interface{}{"headers": redactHeaders(header, "authorization", "cookie", "set-cookie")})
+	return &Event{Body: body, Attributes: attr}
+}
+
+func getHttpClientAttribute(r *http.Request) *Http {
+	clientIP := r.RemoteAddr
+	if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+		clientIP = strings.TrimSpace(strings.Split(fwd, ",")[0])
+	}
+	return &Http{
+		Method:   r.Method,
+		URL:      r.URL.Path,
+		ClientIP: clientIP,
+	}
+}
+
 func getAdditionalAttributes(r *http.Request) map[string]interface{} {
 	result := map[string]interface{}{}
 
@@ -42,11 +100,18 @@ func getAdditionalAttributes(r *http.Request) map[string]interface{} {
 }
 
 func getHttpHeaders(r *http.Request) map[string]string {
+	return redactHeaders(r.Header, "authorization", "cookie")
+}
+
+// redactHeaders returns the headers with the values of the sensitive headers replaced by ***
+func redactHeaders(header http.Header, sensitive ...string) map[string]string {
 	resultHeaders := map[string]string{}
-	for name, requestHeaders := range r.Header {
+	for name, requestHeaders := range header {
 		for _, hdr := range requestHeaders {
-			if strings.EqualFold(name, "authorization") || strings.EqualFold(name, "cookie") {
-				hdr = "***"
+			for _, s := range sensitive {
+				if strings.EqualFold(name, s) {
+					hdr = "***"
+				}
 			}
 			if oldValue, ok := resultHeaders[name]; ok {
 				hdr = oldValue + ", " + hdr
@@ -2,8 +2,11 @@ package otellog
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"net/http"
+	"runtime/debug"
 	"strings"
 )
 
@@ -41,6 +44,20 @@ func enrichHttpAttributeWithRequest(h *Http, req *http.Request) *Http {
 	return h
 }
 
+// stacktrace returns the stack of the calling goroutine without the frames of this package.
+func stacktrace() string {
+	lines := strings.Split(strings.TrimSpace(string(debug.Stack())), "\n")
+	if len(lines) < 1 {
+		return ""
+	}
+	// skip the goroutine header and every frame (function line and file line) which belongs to this package
+	frames := lines[1:]
+	for len(frames) >= 2 && (strings.HasPrefix(frames[0], "runtime/debug.") || strings.HasPrefix(frames[0], "github.com/d-velop/dvelop-sdk-go/otellog.")) {
+		frames = frames[2:]
+	}
+	return strings.Join(frames, "\n")
+}
+
 // With adds a custom option to the log event.
 func (ob *LogBuilder) With(o Option) *LogBuilder {
 	ob.options = append(ob.options, o)
@@ -66,6 +83,47 @@ func (ob *LogBuilder) WithName(name string) *LogBuilder {
 	return ob
 }
 
+// WithCorrelationId adds the correlation id to the log event.
+func (ob *LogBuilder) WithCorrelationId(id string) *LogBuilder {
+	ob.options = append(ob.options, func(e *Event) {
+		e.CorrelationId = id
+	})
+	return ob
+}
+
+// WithUserId adds the SHA-256 hash of the user id to the log event. The user id itself isn't logged.
+func (ob *LogBuilder) WithUserId(id string) *LogBuilder {
+	ob.options = append(ob.options, func(e *Event) {
+		e.UserIdHash = hashUserId(id)
+	})
+	return ob
+}
+
+// WithTraceId adds the trace id to the log event, e.g. if the trace id has been read from a custom header.
+func (ob *LogBuilder) WithTraceId(traceId string) *LogBuilder {
+	ob.options = append(ob.options, func(e *Event) {
+		e.TraceId = traceId
+	})
+	return ob
+}
+
+// WithSpanId adds the span id to the log event.
+func (ob *LogBuilder) WithSpanId(spanId string) *LogBuilder {
+	ob.options = append(ob.options, func(e *Event) {
+		e.SpanId = spanId
+	})
+	return ob
+}
+
+// WithResource adds the resource to the log event. If the log event already has a resource, e.g. set by a hook,
+// the non-zero fields of res override the existing ones and all other fields are kept.
+func (ob *LogBuilder) WithResource(res Resource) *LogBuilder {
+	ob.options = append(ob.options, func(e *Event) {
+		e.Resource = mergeResource(e.Resource, &res)
+	})
+	return ob
+}
+
 // WithHttp adds the http attribute to the log event.
 func (ob *LogBuilder) WithHttp(http Http) *LogBuilder {
 	ob.options = append(ob.options, func(e *Event) {
@@ -142,6 +200,26 @@ func (ob *LogBuilder) WithException(err Exception) *LogBuilder {
 	return ob
 }
 
+// WithError adds the exception attribute created from a go error to the log event.
+// If err is nil the log event remains unchanged.
+func (ob *LogBuilder) WithError(err error) *LogBuilder {
+	if err == nil {
+		return ob
+	}
+	exception := Exception{
+		Type:       fmt.Sprintf("%T", err),
+		Message:    err.Error(),
+		Stacktrace: stacktrace(),
+	}
+	ob.options = append(ob.options, func(e *Event) {
+		if e.Attributes == nil {
+			e.Attributes = &Attributes{}
+		}
+		e.Attributes.Exception = &exception
+	})
+	return ob
+}
+
 // WithAdditionalAttributes adds custom attributes to the log event.
 func (ob *LogBuilder) WithAdditionalAttributes(additionalAttr interface{}) *LogBuilder {
 	ob.options = append(ob.options, func(e *Event) {
@@ -177,6 +255,42 @@ func WithName(name string) *LogBuilder {
 	return ob
 }
 
+// WithCorrelationId adds the correlation id to the log event.
+func WithCorrelationId(id string) *LogBuilder {
+	ob := &LogBuilder{}
+	ob.WithCorrelationId(id)
+	return ob
+}
+
+// WithUserId adds the SHA-256 hash of the user id to the log event. The user id itself isn't logged.
+func WithUserId(id string) *LogBuilder {
+	ob := &LogBuilder{}
+	ob.WithUserId(id)
+	return ob
+}
+
+// WithTraceId adds the trace id to the log event, e.g. if the trace id has been read from a custom header.
+func WithTraceId(traceId string) *LogBuilder {
+	ob := &LogBuilder{}
+	ob.WithTraceId(traceId)
+	return ob
+}
+
+// WithSpanId adds the span id to the log event.
+func WithSpanId(spanId string) *LogBuilder {
+	ob := &LogBuilder{}
+	ob.WithSpanId(spanId)
+	return ob
+}
+
+// WithResource adds the resource to the log event. If the log event already has a resource, e.g. set by a hook,
+// the non-zero fields of res override the existing ones and all other fields are kept.
+func WithResource(res Resource) *LogBuilder {
+	ob := &LogBuilder{}
+	ob.WithResource(res)
+	return ob
+}
+
 // WithHttp adds the http attribute to the log event.
 func WithHttp(http Http) *LogBuilder {
 	ob := &LogBuilder{}
@@ -219,6 +333,14 @@ func WithException(err Exception) *LogBuilder {
 	return ob
 }
 
+// WithError adds the exception attribute created from a go error to the log event.
+// If err is nil the log event remains unchanged.
+func WithError(err error) *LogBuilder {
+	ob := &LogBuilder{}
+	ob.WithError(err)
+	return ob
+}
+
 // WithAdditionalAttributes adds the exception attribute to the log event.
 func WithAdditionalAttributes(additionalAttr interface{}) *LogBuilder {
 	ob := &LogBuilder{}
@@ -246,6 +368,16 @@ func (ob *LogBuilder) Infof(ctx context.Context, format string, v ...interface{}
 	std.output(ctx, SeverityInfo, fmt.Sprintf(format, v...), ob.options)
 }
 
+// Warn logs an event body according to the otel definition
+func (ob *LogBuilder) Warn(ctx context.Context, body interface{}) {
+	std.output(ctx, SeverityWarn, body, ob.options)
+}
+
+// Warnf formats the body according to a format specifier and logs it with SeverityWarn
+func (ob *LogBuilder) Warnf(ctx context.Context, format string, v ...interface{}) {
+	std.output(ctx, SeverityWarn, fmt.Sprintf(format, v...), ob.options)
+}
+
 // Error logs an event body according to the otel definition
 func (ob *LogBuilder) Error(ctx context.Context, body interface{}) {
 	std.output(ctx, SeverityError, body, ob.options)
@@ -255,3 +387,12 @@ func (ob *LogBuilder) Error(ctx context.Context, body interface{}) {
 func (ob *LogBuilder) Errorf(ctx context.Context, format string, v ...interface{}) {
 	std.output(ctx, SeverityError, fmt.Sprintf(format, v...), ob.options)
 }
+
+// hashUserId returns the hex encoded SHA-256 hash of the user id or an empty string if the id is empty
+func hashUserId(id string) string {
+	if id == "" {
+		return ""
+	}
+	h := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(h[:])
+}
@@ -2,7 +2,10 @@ package otellog_test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"strings"
 	"net/http/httptest"
 	"testing"
 
@@ -100,6 +103,22 @@ func TestLogMessageWithVisibilityIsFalseAndIsFormatted_Debug_WritesJSONToBuffer(
 	rec.OutputShouldBe("{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":5,\"body\":\"This is a formatted log message\",\"vis\":0}\n")
 }
 
+func TestLogMessageWithVisibilityIsFalse_Warn_AddVisPropertyAndWritesJSONToBuffer(t *testing.T) {
+	rec := initializeLogger(t)
+
+	log.WithVisibility(false).Warn(context.Background(), "Log message")
+
+	rec.OutputShouldBe("{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":13,\"body\":\"Log message\",\"vis\":0}\n")
+}
+
+func TestLogMessageWithVisibilityIsFalseAndIsFormatted_Warn_WritesJSONToBuffer(t *testing.T) {
+	rec := initializeLogger(t)
+
+	log.WithVisibility(false).Warnf(context.Background(), "This is a %s log message", "formatted")
+
+	rec.OutputShouldBe("{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":13,\"body\":\"This is a formatted log message\",\"vis\":0}\n")
+}
+
 func TestLogMessageWithVisibilityIsFalseAndIsFormatted_Info_WritesJSONToBuffer(t *testing.T) {
 	rec := initializeLogger(t)
 
@@ -124,6 +143,62 @@ func TestLogMessageWithName_Info_AddNamePropertyAndWritesJSONToBuffer(t *testing
 	rec.OutputShouldBe("{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":9,\"name\":\"Log message name\",\"body\":\"Log message\"}\n")
 }
 
+func TestLogMessageWithCorrelationId_Info_AddCorrPropertyAndWritesJSONToBuffer(t *testing.T) {
+	rec := initializeLogger(t)
+
+	log.WithCorrelationId("7a3f9c").Info(context.Background(), "Log message")
+
+	rec.OutputShouldBe("{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":9,\"body\":\"Log message\",\"corr\":\"7a3f9c\"}\n")
+}
+
+func TestLogMessageWithEmptyCorrelationId_Info_OmitsCorrPropertyAndWritesJSONToBuffer(t *testing.T) {
+	rec := initializeLogger(t)
+
+	log.WithCorrelationId("").Info(context.Background(), "Log message")
+
+	rec.OutputShouldBe("{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":9,\"body\":\"Log message\"}\n")
+}
+
+func TestLogMessageWithUserId_Info_AddHashedUidPropertyAndWritesJSONToBuffer(t *testing.T) {
+	rec := initializeLogger(t)
+
+	log.WithUserId("4711").Info(context.Background(), "Log message")
+
+	rec.OutputShouldBe("{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":9,\"body\":\"Log message\",\"uid\":\"de650d61f5bd166a91f8ccec3158297db18b9d50eaedca238cd29dc3a214a916\"}\n")
+}
+
+func TestLogMessageWithEmptyUserId_Info_OmitsUidPropertyAndWritesJSONToBuffer(t *testing.T) {
+	rec := initializeLogger(t)
+
+	log.WithUserId("").Info(context.Background(), "Log message")
+
+	rec.OutputShouldBe("{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":9,\"body\":\"Log message\"}\n")
+}
+
+func TestLogMessageWithTraceIdAndSpanId_Info_AddTraceAndSpanPropertyAndWritesJSONToBuffer(t *testing.T) {
+	rec := initializeLogger(t)
+
+	log.WithTraceId("4bf92f3577b34da6a3ce929d0e0e4736").WithSpanId("00f067aa0ba902b7").Info(context.Background(), "Log message")
+
+	rec.OutputShouldBe("{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":9,\"body\":\"Log message\",\"trace\":\"4bf92f3577b34da6a3ce929d0e0e4736\",\"span\":\"00f067aa0ba902b7\"}\n")
+}
+
+func TestLogMessageWithSpanId_Info_AddSpanPropertyAndWritesJSONToBuffer(t *testing.T) {
+	rec := initializeLogger(t)
+
+	log.WithSpanId("00f067aa0ba902b7").Info(context.Background(), "Log message")
+
+	rec.OutputShouldBe("{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":9,\"body\":\"Log message\",\"span\":\"00f067aa0ba902b7\"}\n")
+}
+
+func TestLogMessageWithEmptyTraceIdAndSpanId_Info_OmitsTraceAndSpanPropertyAndWritesJSONToBuffer(t *testing.T) {
+	rec := initializeLogger(t)
+
+	log.WithTraceId("").WithSpanId("").Info(context.Background(), "Log message")
+
+	rec.OutputShouldBe("{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":9,\"body\":\"Log message\"}\n")
+}
+
 func TestLogMessageWithHttp_Info_AddHttpPropertyAndWritesJSONToBuffer(t *testing.T) {
 	rec := initializeLogger(t)
 
@@ -233,6 +308,37 @@ func TestLogMessageWithException_Info_AddExceptionPropertyAndWritesJSONToBuffer(
 	rec.OutputShouldBe("{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":9,\"body\":\"Log message\",\"attr\":{\"exception\":{\"type\":\"CustomLogException\"}}}\n")
 }
 
+func TestLogMessageWithError_Error_AddExceptionPropertyWithTypeMessageAndStacktraceAndWritesJSONToBuffer(t *testing.T) {
+	rec := initializeLogger(t)
+
+	log.WithError(errors.New("something went wrong")).Error(context.Background(), "Log message")
+
+	var e log.Event
+	if err := json.Unmarshal(rec.Bytes(), &e); err != nil {
+		t.Fatalf("output is no valid json: %v", err)
+	}
+	if e.Attributes == nil || e.Attributes.Exception == nil {
+		t.Fatalf("exception property is missing in output '%v'", rec.String())
+	}
+	if e.Attributes.Exception.Type != "*errors.errorString" {
+		t.Errorf("\ngot   :'%v'\nwanted:'%v'", e.Attributes.Exception.Type, "*errors.errorString")
+	}
+	if e.Attributes.Exception.Message != "something went wrong" {
+		t.Errorf("\ngot   :'%v'\nwanted:'%v'", e.Attributes.Exception.Message, "something went wrong")
+	}
+	if !strings.HasPrefix(e.Attributes.Exception.Stacktrace, "github.com/d-velop/dvelop-sdk-go/otellog_test.TestLogMessageWithError_Error_") {
+		t.Errorf("stacktrace should start with the frame of the caller but was '%v'", e.Attributes.Exception.Stacktrace)
+	}
+}
+
+func TestLogMessageWithNilError_Error_DoNotAddExceptionPropertyAndWritesJSONToBuffer(t *testing.T) {
+	rec := initializeLogger(t)
+
+	log.WithError(nil).Error(context.Background(), "Log message")
+
+	rec.OutputShouldBe("{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":17,\"body\":\"Log message\"}\n")
+}
+
 func TestLogMessageWithAdditionalAttributes_Info_AddAdditionalAttributesPropertyAndWritesJSONToBuffer(t *testing.T) {
 	rec := initializeLogger(t)
 	type A struct {
@@ -294,6 +400,27 @@ func TestLogMessageWithEveryPossibleOption_Info_AddAllPropertiesAndWritesJSONToB
 	rec.OutputShouldBe("{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":9,\"name\":\"Log message name\",\"body\":\"Log message\",\"attr\":{\"a\":{\"one\":1,\"two\":2},\"b\":{\"one\":1,\"two\":2},\"c\":\"3\",\"db\":{\"name\":\"CustomDb\"},\"exception\":{\"type\":\"CustomLogException\"},\"http\":{\"method\":\"Get\"}},\"vis\":0}\n")
 }
 
+func TestLogMessageWithChainedAdditionalAttributes_Info_MergesAttributesAndWritesJSONToBuffer(t *testing.T) {
+	rec := initializeLogger(t)
+	type Item struct {
+		Id    string `json:"id,omitempty"`
+		State string `json:"state,omitempty"`
+	}
+	type First struct {
+		Item  Item   `json:"item"`
+		Order string `json:"order"`
+	}
+	type Second struct {
+		Item Item `json:"item"`
+	}
+
+	log.WithAdditionalAttributes(First{Item: Item{Id: "4711", State: "open"}, Order: "0815"}).
+		WithAdditionalAttributes(Second{Item: Item{State: "closed"}}).
+		Info(context.Background(), "Log message")
+
+	rec.OutputShouldBe("{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":9,\"body\":\"Log message\",\"attr\":{\"item\":{\"id\":\"4711\",\"state\":\"closed\"},\"order\":\"0815\"}}\n")
+}
+
 func TestLogMessageWithRegisteredHookAndOtherService_Info_OverrideServicePropertyAndWritesJSONToBuffer(t *testing.T) {
 	rec := initializeLogger(t)
 	log.RegisterHook(func(ctx context.Context, e *log.Event) {
@@ -318,3 +445,58 @@ func TestLogMessageWithRegisteredHookAndOtherService_Info_OverrideServicePropert
 
 	rec.OutputShouldBe("{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":9,\"body\":\"Log message\",\"res\":{\"svc\":{\"name\":\"OtherGoApplication\",\"ver\":\"2.0.0\",\"inst\":\"instanceId\"}}}\n")
 }
+
+func TestLogMessageWithResource_Info_MergesResourceWithResourceOfHook(t *testing.T) {
+	testcases := map[string]struct {
+		hookResource *log.Resource
+		resource     log.Resource
+		want         string
+	}{
+		"NoResourceOfHook": {
+			hookResource: nil,
+			resource:     log.Resource{Service: &log.Service{Name: "myservice"}},
+			want:         `"res":{"svc":{"name":"myservice"}}`,
+		},
+		"InstanceOnlyKeepsNameAndVersionOfHook": {
+			hookResource: &log.Resource{Service: &log.Service{Name: "myservice", Version: "1.2.3"}},
+			resource:     log.Resource{Service: &log.Service{Instance: "host-1"}},
+			want:         `"res":{"svc":{"name":"myservice","ver":"1.2.3","inst":"host-1"}}`,
+		},
+		"NonZeroFieldsOverrideFieldsOfHook": {
+			hookResource: &log.Resource{Service: &log.Service{Name: "myservice", Version: "1.2.3", Instance: "host-1"}},
+			resource:     log.Resource{Service: &log.Service{Version: "2.0.0"}},
+			want:         `"res":{"svc":{"name":"myservice","ver":"2.0.0","inst":"host-1"}}`,
+		},
+		"EmptyResourceKeepsResourceOfHook": {
+			hookResource: &log.Resource{Service: &log.Service{Name: "myservice"}},
+			resource:     log.Resource{},
+			want:         `"res":{"svc":{"name":"myservice"}}`,
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			rec := initializeLogger(t)
+			log.RegisterHook(func(ctx context.Context, e *log.Event) {
+				e.Resource = tc.hookResource
+			})
+
+			log.WithResource(tc.resource).Info(context.Background(), "Log message")
+
+			rec.OutputShouldBe("{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":9,\"body\":\"Log message\"," + tc.want + "}\n")
+		})
+	}
+}
+
+func TestLogMessageWithResource_Info_DoesNotModifyResourceOfHook(t *testing.T) {
+	initializeLogger(t)
+	hookResource := &log.Resource{Service: &log.Service{Name: "myservice"}}
+	log.RegisterHook(func(ctx context.Context, e *log.Event) {
+		e.Resource = hookResource
+	})
+
+	log.WithResource(log.Resource{Service: &log.Service{Instance: "host-1"}}).Info(context.Background(), "Log message")
+
+	if hookResource.Service.Instance != "" {
+		t.Errorf("resource of hook should not have been modified but got instance '%v'", hookResource.Service.Instance)
+	}
+}
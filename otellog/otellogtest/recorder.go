@@ -0,0 +1,111 @@
+// Package otellogtest provides utilities to verify the log output of the otellog package in unit tests.
+//
+// Example:
+//
+//	func TestSomething(t *testing.T) {
+//		rec := otellogtest.NewLogRecorder(t)
+//
+//		doSomething(context.Background())
+//
+//		rec.ShouldHaveLogged("something done", log.SeverityInfo)
+//	}
+package otellogtest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	log "github.com/d-velop/dvelop-sdk-go/otellog"
+)
+
+// LogRecorder captures the events written by the otellog standard logger.
+type LogRecorder struct {
+	t      testing.TB
+	mu     sync.Mutex
+	events []log.Event
+}
+
+// NewLogRecorder replaces the output destination of the otellog standard logger
+// with a LogRecorder. The original output destination is restored when the test finishes.
+func NewLogRecorder(t *testing.T) *LogRecorder {
+	return newLogRecorder(t)
+}
+
+func newLogRecorder(t testing.TB) *LogRecorder {
+	rec := &LogRecorder{t: t}
+	original := log.Writer()
+	log.SetOutput(rec)
+	t.Cleanup(func() {
+		log.SetOutput(original)
+	})
+	return rec
+}
+
+// FailOnError creates a LogRecorder like NewLogRecorder and reports an error when the test finishes
+// if an event with severity SeverityError or higher has been captured.
+// It declares that the code under test must not log errors.
+func FailOnError(t *testing.T) *LogRecorder {
+	return failOnError(t)
+}
+
+func failOnError(t testing.TB) *LogRecorder {
+	rec := newLogRecorder(t)
+	t.Cleanup(func() {
+		for _, e := range rec.Events() {
+			if e.Severity >= log.SeverityError {
+				t.Errorf("should not have logged an error but captured event %v", e)
+			}
+		}
+	})
+	return rec
+}
+
+// Write parses the json formatted events written by the logger.
+func (r *LogRecorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	scanner := bufio.NewScanner(bytes.NewReader(p))
+	for scanner.Scan() {
+		var e log.Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			r.t.Errorf("log output '%s' is not a valid json formatted event: %v", scanner.Bytes(), err)
+			continue
+		}
+		r.events = append(r.events, e)
+	}
+	return len(p), nil
+}
+
+// Events returns all captured events.
+func (r *LogRecorder) Events() []log.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := make([]log.Event, len(r.events))
+	copy(events, r.events)
+	return events
+}
+
+// ShouldHaveLogged reports an error if no captured event has the given body and severity.
+func (r *LogRecorder) ShouldHaveLogged(body string, sev log.Severity) {
+	r.t.Helper()
+	for _, e := range r.Events() {
+		if e.Body == body && e.Severity == sev {
+			return
+		}
+	}
+	r.t.Errorf("should have logged '%v' with severity %v but captured events were %v", body, sev, r.Events())
+}
+
+// ShouldNotHaveLogged reports an error if a captured event has the given body.
+func (r *LogRecorder) ShouldNotHaveLogged(body string) {
+	r.t.Helper()
+	for _, e := range r.Events() {
+		if e.Body == body {
+			r.t.Errorf("should not have logged '%v' but captured event %v", body, e)
+			return
+		}
+	}
+}
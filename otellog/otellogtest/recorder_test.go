@@ -0,0 +1,154 @@
+package otellogtest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	log "github.com/d-velop/dvelop-sdk-go/otellog"
+)
+
+type tbSpy struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (s *tbSpy) Helper() {}
+
+func (s *tbSpy) Errorf(format string, args ...interface{}) {
+	s.errors = append(s.errors, fmt.Sprintf(format, args...))
+}
+
+func (s *tbSpy) Cleanup(f func()) {
+	s.cleanups = append(s.cleanups, f)
+}
+
+func TestEventWithBodyAndSeverityWasLogged_ShouldHaveLogged_ReportsNoError(t *testing.T) {
+	spy := &tbSpy{}
+	rec := newLogRecorder(spy)
+	defer spy.cleanups[0]()
+
+	log.Info(context.Background(), "Log message")
+	log.Error(context.Background(), "Other message")
+
+	rec.ShouldHaveLogged("Log message", log.SeverityInfo)
+	if len(spy.errors) != 0 {
+		t.Errorf("should report no error but reported %v", spy.errors)
+	}
+}
+
+func TestEventWithOtherSeverityWasLogged_ShouldHaveLogged_ReportsError(t *testing.T) {
+	spy := &tbSpy{}
+	rec := newLogRecorder(spy)
+	defer spy.cleanups[0]()
+
+	log.Info(context.Background(), "Log message")
+
+	rec.ShouldHaveLogged("Log message", log.SeverityError)
+	if len(spy.errors) != 1 {
+		t.Errorf("should report one error but reported %v", spy.errors)
+	}
+}
+
+func TestNoEventWasLogged_ShouldHaveLogged_ReportsError(t *testing.T) {
+	spy := &tbSpy{}
+	rec := newLogRecorder(spy)
+	defer spy.cleanups[0]()
+
+	rec.ShouldHaveLogged("Log message", log.SeverityInfo)
+	if len(spy.errors) != 1 {
+		t.Errorf("should report one error but reported %v", spy.errors)
+	}
+}
+
+func TestEventWithBodyWasLogged_ShouldNotHaveLogged_ReportsError(t *testing.T) {
+	spy := &tbSpy{}
+	rec := newLogRecorder(spy)
+	defer spy.cleanups[0]()
+
+	log.Debug(context.Background(), "Log message")
+
+	rec.ShouldNotHaveLogged("Log message")
+	if len(spy.errors) != 1 {
+		t.Errorf("should report one error but reported %v", spy.errors)
+	}
+}
+
+func TestEventWithOtherBodyWasLogged_ShouldNotHaveLogged_ReportsNoError(t *testing.T) {
+	spy := &tbSpy{}
+	rec := newLogRecorder(spy)
+	defer spy.cleanups[0]()
+
+	log.Debug(context.Background(), "Other message")
+
+	rec.ShouldNotHaveLogged("Log message")
+	if len(spy.errors) != 0 {
+		t.Errorf("should report no error but reported %v", spy.errors)
+	}
+}
+
+func TestEventsWereLogged_Events_ReturnsAllEvents(t *testing.T) {
+	rec := NewLogRecorder(t)
+
+	log.Info(context.Background(), "First message")
+	log.Warn(context.Background(), "Second message")
+
+	events := rec.Events()
+	if len(events) != 2 {
+		t.Fatalf("should return 2 events but returned %v", events)
+	}
+	if events[0].Body != "First message" || events[0].Severity != log.SeverityInfo {
+		t.Errorf("\ngot   :'%v'\nwanted:'%v'", events[0], "First message with severity 9")
+	}
+	if events[1].Body != "Second message" || events[1].Severity != log.SeverityWarn {
+		t.Errorf("\ngot   :'%v'\nwanted:'%v'", events[1], "Second message with severity 13")
+	}
+}
+
+func TestTestFinished_NewLogRecorder_RestoresOriginalOutput(t *testing.T) {
+	original := log.Writer()
+	t.Run("recording", func(t *testing.T) {
+		NewLogRecorder(t)
+	})
+
+	if log.Writer() != original {
+		t.Error("should restore the original output destination")
+	}
+}
+
+func TestErrorWasLogged_FailOnError_ReportsErrorWhenTestFinishes(t *testing.T) {
+	spy := &tbSpy{}
+	failOnError(spy)
+
+	log.Info(context.Background(), "Log message")
+	log.Error(context.Background(), "Error message")
+
+	if len(spy.errors) != 0 {
+		t.Errorf("should report no error before the test finishes but reported %v", spy.errors)
+	}
+	for i := len(spy.cleanups) - 1; i >= 0; i-- {
+		spy.cleanups[i]()
+	}
+	if len(spy.errors) != 1 {
+		t.Errorf("should report one error but reported %v", spy.errors)
+	}
+}
+
+func TestNoErrorWasLogged_FailOnError_ReportsNoError(t *testing.T) {
+	spy := &tbSpy{}
+	rec := failOnError(spy)
+
+	log.Info(context.Background(), "Log message")
+	log.Warn(context.Background(), "Warn message")
+
+	for i := len(spy.cleanups) - 1; i >= 0; i-- {
+		spy.cleanups[i]()
+	}
+	if len(spy.errors) != 0 {
+		t.Errorf("should report no error but reported %v", spy.errors)
+	}
+	if len(rec.Events()) != 2 {
+		t.Errorf("should capture 2 events but captured %v", rec.Events())
+	}
+}
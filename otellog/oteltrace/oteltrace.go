@@ -0,0 +1,43 @@
+// Package oteltrace connects the otellog package with OpenTelemetry tracing.
+//
+// The package is separate from otellog so that only programs which import it
+// depend on go.opentelemetry.io/otel.
+//
+// Example:
+//
+//	func main() {
+//		oteltrace.RegisterTraceHook()
+//		// ...
+//		// events logged with a context that contains an active span
+//		// are enriched with the trace-id and span-id of the span
+//		otellog.Info(ctx, "Hello World")
+//	}
+package oteltrace
+
+import (
+	"context"
+	"sync"
+
+	"github.com/d-velop/dvelop-sdk-go/otellog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var registerOnce sync.Once
+
+// RegisterTraceHook adds a hook to the default otellog logger which sets the TraceId and SpanId of every log event
+// to the hex-encoded ids of the span stored in the context. The ids remain unchanged if the context doesn't contain a valid span.
+// Calling it multiple times has no further effect.
+func RegisterTraceHook() {
+	registerOnce.Do(func() {
+		otellog.RegisterHook(traceHook)
+	})
+}
+
+func traceHook(ctx context.Context, e *otellog.Event) {
+	sc := trace.SpanFromContext(ctx).SpanContext()
+	if !sc.IsValid() {
+		return
+	}
+	e.TraceId = sc.TraceID().String()
+	e.SpanId = sc.SpanID().String()
+}
@@ -0,0 +1,41 @@
+package oteltrace_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/d-velop/dvelop-sdk-go/otellog"
+	"github.com/d-velop/dvelop-sdk-go/otellog/oteltrace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestContextWithValidSpan_RegisterTraceHook_LogsTraceAndSpanId(t *testing.T) {
+	var buf bytes.Buffer
+	otellog.SetOutput(&buf)
+	oteltrace.RegisterTraceHook()
+	oteltrace.RegisterTraceHook()
+	traceId, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanId, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceId, SpanID: spanId, TraceFlags: trace.FlagsSampled})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	otellog.Info(ctx, "Hello World")
+
+	if !strings.Contains(buf.String(), `"trace":"4bf92f3577b34da6a3ce929d0e0e4736","span":"00f067aa0ba902b7"`) {
+		t.Errorf("log event should contain trace-id and span-id but was '%v'", buf.String())
+	}
+}
+
+func TestContextWithoutSpan_RegisterTraceHook_LogsNoIds(t *testing.T) {
+	var buf bytes.Buffer
+	otellog.SetOutput(&buf)
+	oteltrace.RegisterTraceHook()
+
+	otellog.Info(context.Background(), "Hello World")
+
+	if strings.Contains(buf.String(), `"trace"`) || strings.Contains(buf.String(), `"span"`) {
+		t.Errorf("log event should contain no ids but was '%v'", buf.String())
+	}
+}
@@ -0,0 +1,26 @@
+package otellog
+
+import (
+	"context"
+	"io"
+	"strings"
+)
+
+type writer struct {
+	sev Severity
+}
+
+// NewWriter returns an io.Writer which logs everything written to it with the given severity.
+// A trailing newline is removed from the body.
+//
+// Example:
+//
+//	srv := http.Server{ErrorLog: stdlog.New(otellog.NewWriter(otellog.SeverityError), "", 0)}
+func NewWriter(sev Severity) io.Writer {
+	return &writer{sev: sev}
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	std.output(context.Background(), w.sev, strings.TrimSuffix(string(p), "\n"), nil)
+	return len(p), nil
+}
@@ -0,0 +1,31 @@
+package otellog_test
+
+import (
+	stdlog "log"
+	"testing"
+
+	log "github.com/d-velop/dvelop-sdk-go/otellog"
+)
+
+func TestWriterWithSeverityError_Write_WritesJSONWithoutTrailingNewlineToBuffer(t *testing.T) {
+	rec := initializeLogger(t)
+
+	n, err := log.NewWriter(log.SeverityError).Write([]byte("Log message\n"))
+
+	if err != nil {
+		t.Errorf("should return no error but returned %v", err)
+	}
+	if n != len("Log message\n") {
+		t.Errorf("\ngot   :'%v'\nwanted:'%v'", n, len("Log message\n"))
+	}
+	rec.OutputShouldBe("{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":17,\"body\":\"Log message\"}\n")
+}
+
+func TestStdLoggerWithWriter_Printf_WritesJSONToBuffer(t *testing.T) {
+	rec := initializeLogger(t)
+	logger := stdlog.New(log.NewWriter(log.SeverityInfo), "", 0)
+
+	logger.Printf("This is a %s log message", "formatted")
+
+	rec.OutputShouldBe("{\"time\":\"2022-01-01T01:02:03.000000004Z\",\"sev\":9,\"body\":\"This is a formatted log message\"}\n")
+}
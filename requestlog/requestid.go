@@ -0,0 +1,57 @@
+package requestlog
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"regexp"
+)
+
+type contextKey string
+
+const requestIdCtxKey = contextKey("requestId")
+const requestIdHeader = "X-Request-Id"
+
+// requestIdRegEx matches the ids of the X-Request-Id header which are accepted. It rules out ids which could
+// tamper with the log messages or response headers and ids which are unreasonably long.
+var requestIdRegEx = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID reads the X-Request-Id header of the current request and stores the id in the context.
+// If the request doesn't have an id or the id is invalid a new UUID v4 is generated. Valid ids consist of
+// at most 128 letters, digits or the characters '.', '_', ':' and '-'.
+//
+// The id is added to the response as X-Request-Id header and is included in the messages of Log.
+func RequestID() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			id := req.Header.Get(requestIdHeader)
+			if !requestIdRegEx.MatchString(id) {
+				var err error
+				id, err = newUUID()
+				if err != nil {
+					http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+					return
+				}
+			}
+			rw.Header().Set(requestIdHeader, id)
+			next.ServeHTTP(rw, req.WithContext(context.WithValue(req.Context(), requestIdCtxKey, id)))
+		})
+	}
+}
+
+// RequestIDFromCtx reads the request id from the context.
+func RequestIDFromCtx(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIdCtxKey).(string)
+	return id, ok
+}
+
+func newUUID() (string, error) {
+	var u [16]byte
+	if _, err := rand.Read(u[:]); err != nil {
+		return "", err
+	}
+	u[6] = (u[6] & 0x0f) | 0x40 // version 4
+	u[8] = (u[8] & 0x3f) | 0x80 // variant RFC 4122
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:]), nil
+}
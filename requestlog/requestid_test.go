@@ -0,0 +1,94 @@
+package requestlog_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/d-velop/dvelop-sdk-go/requestlog"
+)
+
+var uuidV4RegEx = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+type requestIdSpy struct {
+	id string
+	ok bool
+}
+
+func (spy *requestIdSpy) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	spy.id, spy.ok = requestlog.RequestIDFromCtx(r.Context())
+}
+
+func TestRequestWithRequestIdHeader_RequestID_PutsIdIntoContextAndResponse(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/myresource", nil)
+	req.Header.Set("X-Request-Id", "4711")
+	spy := &requestIdSpy{}
+	rec := httptest.NewRecorder()
+
+	requestlog.RequestID()(spy).ServeHTTP(rec, req)
+
+	if !spy.ok || spy.id != "4711" {
+		t.Errorf("\ngot   :'%v'\nwanted:'%v'", spy.id, "4711")
+	}
+	if rec.Header().Get("X-Request-Id") != "4711" {
+		t.Errorf("\ngot   :'%v'\nwanted:'%v'", rec.Header().Get("X-Request-Id"), "4711")
+	}
+}
+
+func TestRequestWithoutRequestIdHeader_RequestID_GeneratesUUIDAndPutsItIntoContextAndResponse(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/myresource", nil)
+	spy := &requestIdSpy{}
+	rec := httptest.NewRecorder()
+
+	requestlog.RequestID()(spy).ServeHTTP(rec, req)
+
+	if !spy.ok || !uuidV4RegEx.MatchString(spy.id) {
+		t.Errorf("request id '%v' in context should be a UUID v4", spy.id)
+	}
+	if rec.Header().Get("X-Request-Id") != spy.id {
+		t.Errorf("\ngot   :'%v'\nwanted:'%v'", rec.Header().Get("X-Request-Id"), spy.id)
+	}
+}
+
+func TestContextWithoutRequestId_RequestIDFromCtx_ReturnsFalse(t *testing.T) {
+	if _, ok := requestlog.RequestIDFromCtx(context.Background()); ok {
+		t.Error("should return false for a context without request id")
+	}
+}
+
+func TestRequestWithRequestId_Log_LogsRequestId(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/myresource", nil)
+	req.Header.Set("X-Request-Id", "4711")
+	loggedMessages := make([]string, 0)
+
+	requestlog.RequestID()(requestlog.Log(func(ctx context.Context, logmessage string) {
+		loggedMessages = append(loggedMessages, logmessage)
+	})(&handlerMock{})).ServeHTTP(httptest.NewRecorder(), req)
+
+	for _, m := range loggedMessages {
+		if !strings.Contains(m, `requestId="4711"`) {
+			t.Errorf("Logmessage '%v' should contain request id '%v'", m, "4711")
+		}
+	}
+}
+
+func TestRequestWithInvalidRequestIdHeader_RequestID_GeneratesUUID(t *testing.T) {
+	for _, id := range []string{`4711" status="200`, "47\n11", strings.Repeat("a", 129), "4711]"} {
+		req := httptest.NewRequest(http.MethodGet, "/myresource", nil)
+		req.Header.Set("X-Request-Id", id)
+		spy := &requestIdSpy{}
+		rec := httptest.NewRecorder()
+
+		requestlog.RequestID()(spy).ServeHTTP(rec, req)
+
+		if !spy.ok || !uuidV4RegEx.MatchString(spy.id) {
+			t.Errorf("request id '%v' in context should be a UUID v4 for header '%v'", spy.id, id)
+		}
+		if rec.Header().Get("X-Request-Id") != spy.id {
+			t.Errorf("\ngot   :'%v'\nwanted:'%v'", rec.Header().Get("X-Request-Id"), spy.id)
+		}
+	}
+}
@@ -9,6 +9,7 @@
 // 49610 is the private enterprise number officially reserved for d.velop
 // (cf. https://www.iana.org/assignments/enterprise-numbers/enterprise-numbers)
 //
+// Use otellog.LogStructured to log the same information as structured otellog events instead of strings.
 //
 // Example:
 //	func main() {
@@ -27,20 +28,107 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"path"
 	"regexp"
 	"strings"
 	"time"
 )
 
+// LogOption configures the request logging.
+type LogOption func(*logConfig)
+
+type logConfig struct {
+	sensitiveHeaders map[string]bool
+	slowThreshold    time.Duration
+	slowLog          func(ctx context.Context, logmessage string)
+	tenantIdFromCtx  func(ctx context.Context) (string, error)
+	traceContext     bool
+	excludePaths     []string
+}
+
+func newLogConfig(options []LogOption) *logConfig {
+	c := &logConfig{sensitiveHeaders: map[string]bool{}}
+	for _, o := range options {
+		o(c)
+	}
+	return c
+}
+
+// WithSensitiveHeaders adds headers whose values must not be logged.
+// The values of these headers are replaced with [REDACTED]. Header names are matched case-insensitive.
+//
+// The Authorization header and the AuthSessionId cookie are always redacted.
+func WithSensitiveHeaders(headers ...string) LogOption {
+	return func(c *logConfig) {
+		for _, h := range headers {
+			c.sensitiveHeaders[strings.ToLower(h)] = true
+		}
+	}
+}
+
+// WithSlowRequestThreshold logs the end of requests which take longer than d with slowLog
+// instead of the regular log function. The message contains url, elapsed time and status code.
+//
+// WithSlowRequestThreshold is only used by Log.
+func WithSlowRequestThreshold(d time.Duration, slowLog func(ctx context.Context, logmessage string)) LogOption {
+	return func(c *logConfig) {
+		c.slowThreshold = d
+		c.slowLog = slowLog
+	}
+}
+
+// WithTenantId adds the tenant id returned by getFromCtx to the logged begin and end of a request.
+// The tenant id is omitted if getFromCtx returns an error.
+//
+// Example:
+//	requestlog.Log(logInfo, requestlog.WithTenantId(tenant.IdFromCtx))
+func WithTenantId(getFromCtx func(ctx context.Context) (string, error)) LogOption {
+	return func(c *logConfig) {
+		c.tenantIdFromCtx = getFromCtx
+	}
+}
+
+// WithExcludePaths skips the logging of requests whose url path matches one of the patterns,
+// e.g. to keep frequently called health endpoints out of the log. The patterns use the syntax of path.Match.
+// Requests to excluded paths are passed to the next handler unchanged.
+//
+// Example:
+//	requestlog.Log(logInfo, requestlog.WithExcludePaths("/health", "/ready", "/myapp/status/*"))
+func WithExcludePaths(patterns ...string) LogOption {
+	return func(c *logConfig) {
+		c.excludePaths = append(c.excludePaths, patterns...)
+	}
+}
+
+func (c *logConfig) isExcluded(r *http.Request) bool {
+	for _, pattern := range c.excludePaths {
+		if matched, err := path.Match(pattern, r.URL.Path); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
 // Log logs information about the request and response using the provided log function
-func Log(log func(ctx context.Context, logmessage string)) func(handler http.Handler) http.Handler {
+func Log(log func(ctx context.Context, logmessage string), options ...LogOption) func(handler http.Handler) http.Handler {
+	c := newLogConfig(options)
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if c.isExcluded(req) {
+				next.ServeHTTP(rw, req)
+				return
+			}
 			start := time.Now()
-			log(req.Context(), logBegin(req))
+			req = c.addTraceContext(rw, req)
+			log(req.Context(), c.logBegin(req))
 			lrw := newLogResponseWriter(rw)
 			next.ServeHTTP(lrw, req)
-			log(req.Context(), logEnd(req, lrw, time.Since(start)))
+			elapsed := time.Since(start)
+			if c.slowLog != nil && elapsed > c.slowThreshold {
+				c.slowLog(req.Context(), c.logEnd(req, lrw, elapsed))
+				return
+			}
+			log(req.Context(), c.logEnd(req, lrw, elapsed))
 		})
 	}
 }
@@ -49,10 +137,17 @@ type logResponseWriter struct {
 	http.ResponseWriter
 	statusCode int
 	header     http.Header
+	bytes      int64 // number of bytes of the response body
 }
 
 func newLogResponseWriter(rw http.ResponseWriter) *logResponseWriter {
-	return &logResponseWriter{rw, http.StatusOK, nil}
+	return &logResponseWriter{ResponseWriter: rw, statusCode: http.StatusOK}
+}
+
+func (lrw *logResponseWriter) Write(b []byte) (int, error) {
+	n, err := lrw.ResponseWriter.Write(b)
+	lrw.bytes += int64(n)
+	return n, err
 }
 
 func (lrw *logResponseWriter) WriteHeader(code int) {
@@ -61,30 +156,65 @@ func (lrw *logResponseWriter) WriteHeader(code int) {
 	lrw.ResponseWriter.WriteHeader(code)
 }
 
-func logBegin(r *http.Request) string {
-	return fmt.Sprintf("[http@49610 method=\"%v\" url=\"%v\"] BEGIN request %v", r.Method, r.URL.Path, logHeader(r.Header))
+func (c *logConfig) logBegin(r *http.Request) string {
+	return fmt.Sprintf("[http@49610 method=\"%v\" url=\"%v\"%v] BEGIN request %v", r.Method, r.URL.Path, logRequestId(r)+c.logTenantId(r), c.logHeader(r.Header))
+}
+
+func (c *logConfig) logEnd(r *http.Request, lrw *logResponseWriter, t time.Duration) string {
+	return fmt.Sprintf("[http@49610 method=\"%v\" url=\"%v\" millis=\"%d\" status=\"%v\" bytes=\"%d\"%v] END request %v", r.Method, r.URL.Path, int64(t/time.Millisecond), lrw.statusCode, lrw.bytes, logRequestId(r)+c.logTenantId(r)+logTraceContext(r), c.logHeader(lrw.Header()))
+}
+
+// paramValueEscaper escapes the characters which must be escaped in a syslog PARAM-VALUE (cf. https://tools.ietf.org/html/rfc5424#section-6.3.3)
+var paramValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+func logRequestId(r *http.Request) string {
+	if id, ok := RequestIDFromCtx(r.Context()); ok {
+		return fmt.Sprintf(" requestId=\"%v\"", paramValueEscaper.Replace(id))
+	}
+	return ""
+}
+
+func (c *logConfig) tenantId(r *http.Request) string {
+	if c.tenantIdFromCtx == nil {
+		return ""
+	}
+	id, err := c.tenantIdFromCtx(r.Context())
+	if err != nil {
+		return ""
+	}
+	return id
 }
 
-func logEnd(r *http.Request, lrw *logResponseWriter, t time.Duration) string {
-	return fmt.Sprintf("[http@49610 method=\"%v\" url=\"%v\" millis=\"%d\" status=\"%v\"] END request %v", r.Method, r.URL.Path, int64(t/time.Millisecond), lrw.statusCode, logHeader(lrw.Header()))
+func (c *logConfig) logTenantId(r *http.Request) string {
+	if id := c.tenantId(r); id != "" {
+		return fmt.Sprintf(" tenantId=\"%v\"", paramValueEscaper.Replace(id))
+	}
+	return ""
 }
 
 var authSessionIdRegEx = regexp.MustCompile(`AuthSessionId=[^;\s]+`)
 var authorizationHeaderValueRegEx = regexp.MustCompile(`(\S*) (\S*)`)
 
-func logHeader(m map[string][]string) string {
+func (c *logConfig) logHeader(m map[string][]string) string {
 	var buf []byte
 	for key, value := range m {
 		buf = append(buf, key...)
 		buf = append(buf, ":"...)
 		for _, v := range value {
-			val := authSessionIdRegEx.ReplaceAll([]byte(fmt.Sprintf("%v", v)), []byte("AuthSessionId=***"))
-			if strings.ToLower(key) == "authorization" {
-				val = authorizationHeaderValueRegEx.ReplaceAll(val, []byte("$1 ***"))
-			}
-			buf = append(buf, val...)
+			buf = append(buf, c.redactHeaderValue(key, v)...)
 		}
 		buf = append(buf, ' ')
 	}
 	return string(buf)
 }
+
+func (c *logConfig) redactHeaderValue(key string, value string) string {
+	if c.sensitiveHeaders[strings.ToLower(key)] {
+		return "[REDACTED]"
+	}
+	val := authSessionIdRegEx.ReplaceAllString(value, "AuthSessionId=***")
+	if strings.ToLower(key) == "authorization" {
+		val = authorizationHeaderValueRegEx.ReplaceAllString(val, "$1 ***")
+	}
+	return val
+}
@@ -0,0 +1,108 @@
+package requestlog
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	traceparentHeader   = "traceparent"
+	traceresponseHeader = "traceresponse"
+)
+
+type traceContextKey int
+
+const (
+	traceIdKey traceContextKey = iota
+	spanIdKey
+)
+
+// WithTraceContext reads the trace-id of the W3C traceparent header (cf. https://www.w3.org/TR/trace-context/)
+// of the current request and stores it in the context. If the request doesn't have a valid traceparent header
+// a new trace-id is generated. A new span-id is generated for the server span and stored in the context as well.
+//
+// Trace-id and span-id are included in the logged end of the request and are added to the response
+// as traceresponse header.
+func WithTraceContext() LogOption {
+	return func(c *logConfig) {
+		c.traceContext = true
+	}
+}
+
+// TraceIdFromCtx reads the trace-id of the current request from the context.
+func TraceIdFromCtx(ctx context.Context) (string, bool) {
+	traceId, ok := ctx.Value(traceIdKey).(string)
+	return traceId, ok
+}
+
+// SpanIdFromCtx reads the span-id of the current request from the context.
+func SpanIdFromCtx(ctx context.Context) (string, bool) {
+	spanId, ok := ctx.Value(spanIdKey).(string)
+	return spanId, ok
+}
+
+// addTraceContext returns req with trace-id and span-id on its context if WithTraceContext is set
+func (c *logConfig) addTraceContext(rw http.ResponseWriter, req *http.Request) *http.Request {
+	if !c.traceContext {
+		return req
+	}
+	traceId, ok := traceIdOf(req.Header.Get(traceparentHeader))
+	if !ok {
+		var err error
+		if traceId, err = newHexId(16); err != nil {
+			return req
+		}
+	}
+	spanId, err := newHexId(8)
+	if err != nil {
+		return req
+	}
+	rw.Header().Set(traceresponseHeader, fmt.Sprintf("00-%v-%v-01", traceId, spanId))
+	ctx := context.WithValue(context.WithValue(req.Context(), traceIdKey, traceId), spanIdKey, spanId)
+	return req.WithContext(ctx)
+}
+
+// traceIdOf returns the trace-id of a traceparent header in the format version-traceid-parentid-traceflags.
+// As required by the W3C specification the version ff and trace-ids or parent-ids consisting only of zeros are invalid.
+func traceIdOf(traceparent string) (string, bool) {
+	parts := strings.Split(traceparent, "-")
+	if len(parts) != 4 || !isHex(parts[0], 1) || !isHex(parts[1], 16) || !isHex(parts[2], 8) || !isHex(parts[3], 1) {
+		return "", false
+	}
+	if strings.EqualFold(parts[0], "ff") || isZero(parts[1]) || isZero(parts[2]) {
+		return "", false
+	}
+	return strings.ToLower(parts[1]), true
+}
+
+func isHex(s string, byteLen int) bool {
+	b, err := hex.DecodeString(s)
+	return err == nil && len(b) == byteLen
+}
+
+func isZero(s string) bool {
+	return strings.Trim(s, "0") == ""
+}
+
+func newHexId(byteLen int) (string, error) {
+	id := make([]byte, byteLen)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+func logTraceContext(r *http.Request) string {
+	var s string
+	if traceId, ok := TraceIdFromCtx(r.Context()); ok {
+		s += fmt.Sprintf(" traceId=\"%v\"", traceId)
+	}
+	if spanId, ok := SpanIdFromCtx(r.Context()); ok {
+		s += fmt.Sprintf(" spanId=\"%v\"", spanId)
+	}
+	return s
+}
@@ -0,0 +1,130 @@
+package requestlog_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/d-velop/dvelop-sdk-go/requestlog"
+)
+
+const incomingTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestRequestWithTraceparentAndWithTraceContext_Log_StoresTraceIdAndNewSpanIdInContext(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/myresource", nil)
+	req.Header.Set("traceparent", incomingTraceparent)
+	var traceId, spanId string
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		traceId, _ = requestlog.TraceIdFromCtx(r.Context())
+		spanId, _ = requestlog.SpanIdFromCtx(r.Context())
+	})
+
+	requestlog.Log(func(ctx context.Context, logmessage string) {}, requestlog.WithTraceContext())(handler).ServeHTTP(httptest.NewRecorder(), req)
+
+	if traceId != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("got trace-id '%v', want '%v'", traceId, "4bf92f3577b34da6a3ce929d0e0e4736")
+	}
+	if !regexp.MustCompile(`^[0-9a-f]{16}$`).MatchString(spanId) || spanId == "00f067aa0ba902b7" {
+		t.Errorf("got span-id '%v', want a new span-id", spanId)
+	}
+}
+
+func TestRequestWithTraceparentAndWithTraceContext_Log_SetsTraceresponseHeader(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/myresource", nil)
+	req.Header.Set("traceparent", incomingTraceparent)
+	var spanId string
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		spanId, _ = requestlog.SpanIdFromCtx(r.Context())
+	})
+	rec := httptest.NewRecorder()
+
+	requestlog.Log(func(ctx context.Context, logmessage string) {}, requestlog.WithTraceContext())(handler).ServeHTTP(rec, req)
+
+	if got, want := rec.Header().Get("traceresponse"), "00-4bf92f3577b34da6a3ce929d0e0e4736-"+spanId+"-01"; got != want {
+		t.Errorf("got traceresponse header '%v', want '%v'", got, want)
+	}
+}
+
+func TestRequestWithTraceparentAndWithTraceContext_Log_LogsTraceIdAndSpanIdAtEnd(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/myresource", nil)
+	req.Header.Set("traceparent", incomingTraceparent)
+	loggedMessages := make([]string, 0)
+
+	requestlog.Log(func(ctx context.Context, logmessage string) {
+		loggedMessages = append(loggedMessages, logmessage)
+	}, requestlog.WithTraceContext())(&handlerMock{}).ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(loggedMessages) != 2 {
+		t.Fatalf("expected begin and end of request to be logged but got %v", loggedMessages)
+	}
+	if !regexp.MustCompile(`traceId="4bf92f3577b34da6a3ce929d0e0e4736" spanId="[0-9a-f]{16}"`).MatchString(loggedMessages[1]) {
+		t.Errorf("Logmessage '%v' should contain trace-id and span-id", loggedMessages[1])
+	}
+}
+
+func TestRequestWithoutTraceparentAndWithTraceContext_Log_GeneratesTraceId(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/myresource", nil)
+	var traceId string
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		traceId, _ = requestlog.TraceIdFromCtx(r.Context())
+	})
+
+	requestlog.Log(func(ctx context.Context, logmessage string) {}, requestlog.WithTraceContext())(handler).ServeHTTP(httptest.NewRecorder(), req)
+
+	if !regexp.MustCompile(`^[0-9a-f]{32}$`).MatchString(traceId) {
+		t.Errorf("got trace-id '%v', want a new trace-id", traceId)
+	}
+}
+
+func TestRequestWithTraceparentAndWithoutTraceContext_Log_IgnoresTraceparent(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/myresource", nil)
+	req.Header.Set("traceparent", incomingTraceparent)
+	var found bool
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_, found = requestlog.TraceIdFromCtx(r.Context())
+	})
+	rec := httptest.NewRecorder()
+	loggedMessages := make([]string, 0)
+
+	requestlog.Log(func(ctx context.Context, logmessage string) {
+		loggedMessages = append(loggedMessages, logmessage)
+	})(handler).ServeHTTP(rec, req)
+
+	if found {
+		t.Error("trace-id should not have been stored in context")
+	}
+	if rec.Header().Get("traceresponse") != "" {
+		t.Error("traceresponse header should not have been set")
+	}
+	for _, m := range loggedMessages {
+		if strings.Contains(m, "traceId") {
+			t.Errorf("Logmessage '%v' should not contain a trace-id", m)
+		}
+	}
+}
+
+func TestRequestWithInvalidTraceparentAndWithTraceContext_Log_GeneratesTraceId(t *testing.T) {
+	invalidTraceparents := []string{
+		"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	}
+	for _, traceparent := range invalidTraceparents {
+		req := httptest.NewRequest(http.MethodGet, "/myresource", nil)
+		req.Header.Set("traceparent", traceparent)
+		var traceId string
+		handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			traceId, _ = requestlog.TraceIdFromCtx(r.Context())
+		})
+
+		requestlog.Log(func(ctx context.Context, logmessage string) {}, requestlog.WithTraceContext())(handler).ServeHTTP(httptest.NewRecorder(), req)
+
+		if !regexp.MustCompile(`^[0-9a-f]{32}$`).MatchString(traceId) || traceId == "00000000000000000000000000000000" || strings.HasPrefix(traceId, "4bf92f3577b34da6") {
+			t.Errorf("traceparent '%v': got trace-id '%v', want a new trace-id", traceparent, traceId)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package tenant
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// SignatureAlgorithm is the algorithm which is used to sign the tenant headers.
+type SignatureAlgorithm int
+
+const (
+	// AlgorithmHMACSHA256 is the default algorithm. Requests signed with AlgorithmHMACSHA512 are accepted as well.
+	AlgorithmHMACSHA256 SignatureAlgorithm = iota
+	// AlgorithmHMACSHA512 accepts only requests which are signed with HMAC-SHA512.
+	AlgorithmHMACSHA512
+)
+
+const signatureAlgorithmHeader = "x-dv-sig-algorithm"
+
+func (a SignatureAlgorithm) String() string {
+	switch a {
+	case AlgorithmHMACSHA256:
+		return "hmac-sha256"
+	case AlgorithmHMACSHA512:
+		return "hmac-sha512"
+	default:
+		return fmt.Sprintf("SignatureAlgorithm(%d)", int(a))
+	}
+}
+
+func (a SignatureAlgorithm) hash() func() hash.Hash {
+	if a == AlgorithmHMACSHA512 {
+		return sha512.New
+	}
+	return sha256.New
+}
+
+// AddToCtxWithAlgorithm adds systemBaseUri and tenantId to request context like AddToCtx but
+// requires the tenant headers to be signed with at least the given algorithm.
+//
+// The algorithm of the signature is read from the x-dv-sig-algorithm header which has the value
+// hmac-sha256 or hmac-sha512. Requests without this header are signed with hmac-sha256. Requests with
+// another value are rejected with 403 - Forbidden.
+//
+// Unlike AddToCtx the systemBaseUri is validated like by AddToCtxWithLogger, so it must be an absolute https url.
+func AddToCtxWithAlgorithm(defaultSystemBaseUri string, signatureSecretKey []byte, algorithm SignatureAlgorithm) func(http.Handler) http.Handler {
+	logPrintf := func(ctx context.Context, logmessage string) {
+		log.Print(logmessage)
+	}
+	return AddToCtxWithLogger(defaultSystemBaseUri, signatureSecretKey, logPrintf, logPrintf, WithSignatureAlgorithm(algorithm))
+}
+
+// WithSignatureAlgorithm sets the algorithm which is required for the signature of the tenant headers.
+// The default is AlgorithmHMACSHA256.
+func WithSignatureAlgorithm(algorithm SignatureAlgorithm) Option {
+	return func(c *config) {
+		c.signatureAlgorithm = algorithm
+	}
+}
+
+// algorithmFromRequest returns the algorithm of the signature as specified by the x-dv-sig-algorithm header
+func algorithmFromRequest(req *http.Request) (SignatureAlgorithm, error) {
+	switch value := strings.ToLower(strings.TrimSpace(req.Header.Get(signatureAlgorithmHeader))); value {
+	case "", AlgorithmHMACSHA256.String():
+		return AlgorithmHMACSHA256, nil
+	case AlgorithmHMACSHA512.String():
+		return AlgorithmHMACSHA512, nil
+	default:
+		return 0, fmt.Errorf("unknown signature algorithm '%v'", value)
+	}
+}
+
+// algorithmOf returns the algorithm of the signature of req. An error is returned if the algorithm
+// is unknown or weaker than the required algorithm.
+func (c *config) algorithmOf(req *http.Request) (SignatureAlgorithm, error) {
+	if c.legacy {
+		return AlgorithmHMACSHA256, nil
+	}
+	algorithm, err := algorithmFromRequest(req)
+	if err != nil {
+		return 0, err
+	}
+	return algorithm, c.checkAlgorithm(algorithm)
+}
+
+// checkAlgorithm returns an error if the algorithm of the signature is weaker than the required algorithm
+func (c *config) checkAlgorithm(algorithm SignatureAlgorithm) error {
+	if algorithm < c.signatureAlgorithm {
+		return fmt.Errorf("signature algorithm '%v' is not allowed because '%v' is required", algorithm, c.signatureAlgorithm)
+	}
+	return nil
+}
+
+func signatureIsValid(message, signature, key []byte, algorithm SignatureAlgorithm) bool {
+	mac := hmac.New(algorithm.hash(), key)
+	mac.Write(message)
+	expectedMAC := mac.Sum(nil)
+	return hmac.Equal(signature, expectedMAC)
+}
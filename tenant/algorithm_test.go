@@ -0,0 +1,155 @@
+package tenant_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/d-velop/dvelop-sdk-go/tenant"
+)
+
+const signatureAlgorithmHeader = "x-dv-sig-algorithm"
+
+func base64SignatureSHA512(message string, sigKey []byte) string {
+	mac := hmac.New(sha512.New, sigKey)
+	mac.Write([]byte(message))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestSHA512SignatureAndSHA512Required_AddToCtxWithAlgorithm_UsesHeaders(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/myresource/sub", nil)
+	const systemBaseUriFromHeader = "https://sample.example.com"
+	const tenantIdFromHeader = "a12be5"
+	req.Header.Set(systemBaseUriHeader, systemBaseUriFromHeader)
+	req.Header.Set(tenantIdHeader, tenantIdFromHeader)
+	req.Header.Set(signatureAlgorithmHeader, "hmac-sha512")
+	req.Header.Set(signatureHeader, base64SignatureSHA512(systemBaseUriFromHeader+tenantIdFromHeader, signatureKey))
+	handlerSpy := handlerSpy{}
+	responseSpy := responseSpy{httptest.NewRecorder()}
+
+	tenant.AddToCtxWithAlgorithm(defaultSystemBaseUri, signatureKey, tenant.AlgorithmHMACSHA512)(&handlerSpy).ServeHTTP(responseSpy, req)
+
+	if err := responseSpy.assertStatusCodeIs(http.StatusOK); err != nil {
+		t.Error(err)
+	}
+	if err := handlerSpy.assertTenantIdIs(tenantIdFromHeader); err != nil {
+		t.Error(err)
+	}
+	if err := handlerSpy.assertBaseUriIs(systemBaseUriFromHeader); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSHA512SignatureAndSHA256Default_AddToCtxWithAlgorithm_UsesHeaders(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/myresource/sub", nil)
+	const tenantIdFromHeader = "a12be5"
+	req.Header.Set(tenantIdHeader, tenantIdFromHeader)
+	req.Header.Set(signatureAlgorithmHeader, "hmac-sha512")
+	req.Header.Set(signatureHeader, base64SignatureSHA512(tenantIdFromHeader, signatureKey))
+	handlerSpy := handlerSpy{}
+	responseSpy := responseSpy{httptest.NewRecorder()}
+
+	tenant.AddToCtxWithAlgorithm(defaultSystemBaseUri, signatureKey, tenant.AlgorithmHMACSHA256)(&handlerSpy).ServeHTTP(responseSpy, req)
+
+	if err := responseSpy.assertStatusCodeIs(http.StatusOK); err != nil {
+		t.Error(err)
+	}
+	if err := handlerSpy.assertTenantIdIs(tenantIdFromHeader); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSHA256SignatureAndSHA512Required_AddToCtxWithAlgorithm_Returns403(t *testing.T) {
+	testcases := map[string]string{
+		"WithoutAlgorithmHeader": "",
+		"WithAlgorithmHeader":    "hmac-sha256",
+	}
+	for name, algorithmHeader := range testcases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/myresource/sub", nil)
+			const tenantIdFromHeader = "a12be5"
+			req.Header.Set(tenantIdHeader, tenantIdFromHeader)
+			if algorithmHeader != "" {
+				req.Header.Set(signatureAlgorithmHeader, algorithmHeader)
+			}
+			req.Header.Set(signatureHeader, base64Signature(tenantIdFromHeader, signatureKey))
+			handlerSpy := handlerSpy{}
+			responseSpy := responseSpy{httptest.NewRecorder()}
+
+			tenant.AddToCtxWithAlgorithm(defaultSystemBaseUri, signatureKey, tenant.AlgorithmHMACSHA512)(&handlerSpy).ServeHTTP(responseSpy, req)
+
+			if err := responseSpy.assertStatusCodeIs(http.StatusForbidden); err != nil {
+				t.Error(err)
+			}
+			if handlerSpy.hasBeenCalled {
+				t.Error("handler should not have been called")
+			}
+		})
+	}
+}
+
+func TestSHA256SignatureWithSHA512AlgorithmHeader_AddToCtxWithAlgorithm_Returns403(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/myresource/sub", nil)
+	const tenantIdFromHeader = "a12be5"
+	req.Header.Set(tenantIdHeader, tenantIdFromHeader)
+	req.Header.Set(signatureAlgorithmHeader, "hmac-sha512")
+	req.Header.Set(signatureHeader, base64Signature(tenantIdFromHeader, signatureKey))
+	responseSpy := responseSpy{httptest.NewRecorder()}
+
+	tenant.AddToCtxWithAlgorithm(defaultSystemBaseUri, signatureKey, tenant.AlgorithmHMACSHA256)(&handlerSpy{}).ServeHTTP(responseSpy, req)
+
+	if err := responseSpy.assertStatusCodeIs(http.StatusForbidden); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUnknownAlgorithmHeader_AddToCtxWithAlgorithm_Returns403(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/myresource/sub", nil)
+	const tenantIdFromHeader = "a12be5"
+	req.Header.Set(tenantIdHeader, tenantIdFromHeader)
+	req.Header.Set(signatureAlgorithmHeader, "hmac-md5")
+	req.Header.Set(signatureHeader, base64Signature(tenantIdFromHeader, signatureKey))
+	responseSpy := responseSpy{httptest.NewRecorder()}
+
+	tenant.AddToCtxWithAlgorithm(defaultSystemBaseUri, signatureKey, tenant.AlgorithmHMACSHA256)(&handlerSpy{}).ServeHTTP(responseSpy, req)
+
+	if err := responseSpy.assertStatusCodeIs(http.StatusForbidden); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUnknownAlgorithmHeaderAndSHA256Signature_AddToCtx_UsesHeaders(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/myresource/sub", nil)
+	const tenantIdFromHeader = "a12be5"
+	req.Header.Set(tenantIdHeader, tenantIdFromHeader)
+	req.Header.Set(signatureAlgorithmHeader, "hmac-md5")
+	req.Header.Set(signatureHeader, base64Signature(tenantIdFromHeader, signatureKey))
+	handlerSpy := handlerSpy{}
+	responseSpy := responseSpy{httptest.NewRecorder()}
+
+	tenant.AddToCtx(defaultSystemBaseUri, signatureKey)(&handlerSpy).ServeHTTP(responseSpy, req)
+
+	if err := responseSpy.assertStatusCodeIs(http.StatusOK); err != nil {
+		t.Error(err)
+	}
+	if err := handlerSpy.assertTenantIdIs(tenantIdFromHeader); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHttpBaseUriHeader_AddToCtxWithAlgorithm_Returns400(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/myresource/sub", nil)
+	const systemBaseUriFromHeader = "http://sample.example.com"
+	req.Header.Set(systemBaseUriHeader, systemBaseUriFromHeader)
+	req.Header.Set(signatureHeader, base64Signature(systemBaseUriFromHeader, signatureKey))
+	responseSpy := responseSpy{httptest.NewRecorder()}
+
+	tenant.AddToCtxWithAlgorithm(defaultSystemBaseUri, signatureKey, tenant.AlgorithmHMACSHA256)(&handlerSpy{}).ServeHTTP(responseSpy, req)
+
+	if err := responseSpy.assertStatusCodeIs(http.StatusBadRequest); err != nil {
+		t.Error(err)
+	}
+}
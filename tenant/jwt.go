@@ -0,0 +1,67 @@
+package tenant
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+var bearerTokenRegex = regexp.MustCompile("^(?i)bearer (.*)$") // cf. https://regex101.com/
+
+// ExtractFromJWT adds the tenantId from the claim claimName of a JWT bearer token to the request context.
+// If there is no SystemBaseUri on the context the given defaultSystemBaseUri is added as well.
+//
+// The signature of the JWT is NOT verified. So this middleware MUST be combined with a middleware which
+// authenticates the bearer token like idp.Authenticate.
+//
+// If there is no JWT bearer token or the claim is missing the request context is left unchanged. In order to
+// fall back to the tenant headers wrap this middleware with AddToCtx. The claim is only used if the request has
+// no x-dv-tenant-id header, because the signed tenant header takes precedence over the unverified claim.
+//
+// Example:
+//	tenant.AddToCtx(defaultSystemBaseUri, signatureSecretKey)(tenant.ExtractFromJWT("tid", defaultSystemBaseUri)(handler))
+func ExtractFromJWT(claimName string, defaultSystemBaseUri string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			ctx := req.Context()
+			if req.Header.Get(tenantIdHeader) != "" {
+				next.ServeHTTP(rw, req)
+				return
+			}
+			tenantId := claimFromBearerToken(req, claimName)
+			if tenantId != "" {
+				ctx = context.WithValue(ctx, tenantIdCtxKey, tenantId)
+				if _, err := SystemBaseUriFromCtx(ctx); err != nil && defaultSystemBaseUri != "" {
+					ctx = context.WithValue(ctx, systemBaseUriCtxKey, defaultSystemBaseUri)
+				}
+			}
+			next.ServeHTTP(rw, req.WithContext(ctx))
+		})
+	}
+}
+
+// claimFromBearerToken returns the string value of the claim claimName of the unverified JWT bearer token
+// or "" if there is no such token or claim
+func claimFromBearerToken(req *http.Request, claimName string) string {
+	matches := bearerTokenRegex.FindStringSubmatch(req.Header.Get("Authorization"))
+	if matches == nil {
+		return ""
+	}
+	parts := strings.Split(matches[1], ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return ""
+	}
+	var claims map[string]interface{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return ""
+	}
+	claim, _ := claims[claimName].(string)
+	return claim
+}
@@ -0,0 +1,82 @@
+package tenant
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Option configures the tenant middleware created by AddToCtxWithLogger.
+type Option func(*config)
+
+type config struct {
+	signatureCacheSize int
+	signatureCacheTTL  time.Duration
+	signatureCache     *signatureCache
+	allowHTTP          bool
+	signatureAlgorithm SignatureAlgorithm
+	legacy             bool
+}
+
+const defaultSignatureCacheTTL = time.Second
+
+func newConfig(options []Option) *config {
+	c := &config{signatureCacheTTL: defaultSignatureCacheTTL}
+	for _, o := range options {
+		o(c)
+	}
+	if c.signatureCacheSize > 0 {
+		c.signatureCache = newSignatureCache(c.signatureCacheSize, c.signatureCacheTTL)
+	}
+	return c
+}
+
+// WithSignatureCacheSize enables caching of signature validation results for at most size
+// different combinations of the tenant headers. The cache is disabled by default.
+func WithSignatureCacheSize(size int) Option {
+	return func(c *config) {
+		c.signatureCacheSize = size
+	}
+}
+
+// WithSignatureCacheTTL sets the duration for which a signature validation result is cached.
+// The default is 1 second. The cache must be enabled with WithSignatureCacheSize.
+func WithSignatureCacheTTL(ttl time.Duration) Option {
+	return func(c *config) {
+		c.signatureCacheTTL = ttl
+	}
+}
+
+// AllowHTTP accepts systemBaseUris with the scheme http in addition to https.
+// This should only be used for local development.
+func AllowHTTP() Option {
+	return func(c *config) {
+		c.allowHTTP = true
+	}
+}
+
+// legacy keeps the behaviour AddToCtx had before the systemBaseUri and the signature algorithm
+// have been validated: Any systemBaseUri is accepted and the x-dv-sig-algorithm header is ignored.
+func legacy() Option {
+	return func(c *config) {
+		c.legacy = true
+	}
+}
+
+// validateSystemBaseUri returns an error if systemBaseUri is not empty and no absolute url with an allowed scheme.
+func (c *config) validateSystemBaseUri(systemBaseUri string) error {
+	if systemBaseUri == "" || c.legacy {
+		return nil
+	}
+	u, err := url.Parse(systemBaseUri)
+	if err != nil {
+		return fmt.Errorf("'%v' is no valid url: %v", systemBaseUri, err)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("'%v' is no absolute url", systemBaseUri)
+	}
+	if u.Scheme != "https" && !(c.allowHTTP && u.Scheme == "http") {
+		return fmt.Errorf("scheme of '%v' is not allowed", systemBaseUri)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package tenant
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"net/http"
+)
+
+type roundTripper struct {
+	next               http.RoundTripper
+	signatureSecretKey []byte
+}
+
+// NewRoundTripper returns a http.RoundTripper which forwards the systemBaseUri and the tenantId
+// of the request context to the called app. The values are sent as signed tenant headers which
+// can be read by AddToCtx.
+//
+// Example:
+//
+//	client := &http.Client{Transport: tenant.NewRoundTripper(http.DefaultTransport, secretKey)}
+//	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://other.example.com/app/resource", nil)
+//	resp, err := client.Do(req)
+func NewRoundTripper(next http.RoundTripper, signatureSecretKey []byte) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &roundTripper{next: next, signatureSecretKey: signatureSecretKey}
+}
+
+func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// a RoundTripper must not modify the request, so the headers are set on a copy
+	outReq := req.WithContext(req.Context())
+	outReq.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		outReq.Header[k] = v
+	}
+	StampOutboundRequest(outReq, rt.signatureSecretKey)
+	return rt.next.RoundTrip(outReq)
+}
+
+// StampOutboundRequest sets the systemBaseUri and the tenantId of the request context as tenant headers
+// of req and signs them with signatureSecretKey using HMAC-SHA256.
+// The headers are left unchanged if neither systemBaseUri nor tenantId are present on the request context.
+func StampOutboundRequest(req *http.Request, signatureSecretKey []byte) {
+	ctx := req.Context()
+	systemBaseUri, _ := SystemBaseUriFromCtx(ctx)
+	tenantId, _ := IdFromCtx(ctx)
+	if systemBaseUri == "" && tenantId == "" {
+		return
+	}
+	mac := hmac.New(sha256.New, signatureSecretKey)
+	mac.Write([]byte(systemBaseUri + tenantId))
+	req.Header.Set(systemBaseUriHeader, systemBaseUri)
+	req.Header.Set(tenantIdHeader, tenantId)
+	req.Header.Set(signatureHeader, base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+}
+
+// NewOutboundRequest returns a new request with the given method, url and body whose tenant headers are set
+// and signed by StampOutboundRequest. The Content-Type of the request is application/json.
+//
+// Example:
+//
+//	req, err := tenant.NewOutboundRequest(ctx, http.MethodPost, "https://other.example.com/app/webhook", bytes.NewReader(event), secretKey)
+//	if err != nil {
+//		// error handling
+//	}
+//	resp, err := http.DefaultClient.Do(req)
+func NewOutboundRequest(ctx context.Context, method, url string, body io.Reader, signatureSecretKey []byte) (*http.Request, error) {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	StampOutboundRequest(req, signatureSecretKey)
+	return req, nil
+}
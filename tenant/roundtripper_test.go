@@ -0,0 +1,131 @@
+package tenant_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/d-velop/dvelop-sdk-go/tenant"
+)
+
+func TestTenantOnContext_RoundTripper_SetsSignedTenantHeaders(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		got = req.Header
+	}))
+	defer server.Close()
+	ctx := tenant.SetId(tenant.SetSystemBaseUri(context.Background(), "https://sample.example.com"), "a12be5")
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	client := &http.Client{Transport: tenant.NewRoundTripper(http.DefaultTransport, signatureKey)}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	if sbu := got.Get(systemBaseUriHeader); sbu != "https://sample.example.com" {
+		t.Errorf("got %v header '%v', want '%v'", systemBaseUriHeader, sbu, "https://sample.example.com")
+	}
+	if tId := got.Get(tenantIdHeader); tId != "a12be5" {
+		t.Errorf("got %v header '%v', want '%v'", tenantIdHeader, tId, "a12be5")
+	}
+	if sig, want := got.Get(signatureHeader), base64Signature("https://sample.example.com"+"a12be5", signatureKey); sig != want {
+		t.Errorf("got %v header '%v', want '%v'", signatureHeader, sig, want)
+	}
+	if req.Header.Get(tenantIdHeader) != "" {
+		t.Error("original request should not have been modified")
+	}
+}
+
+func TestNoTenantOnContext_RoundTripper_SetsNoTenantHeaders(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		got = req.Header
+	}))
+	defer server.Close()
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	client := &http.Client{Transport: tenant.NewRoundTripper(http.DefaultTransport, signatureKey)}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	for _, h := range []string{systemBaseUriHeader, tenantIdHeader, signatureHeader} {
+		if v := got.Get(h); v != "" {
+			t.Errorf("got %v header '%v', want no header", h, v)
+		}
+	}
+}
+
+func TestStampedRequest_AddToCtx_AddsTenantToContext(t *testing.T) {
+	ctx := tenant.SetId(tenant.SetSystemBaseUri(context.Background(), "https://sample.example.com"), "a12be5")
+	outReq := httptest.NewRequest(http.MethodGet, "/myresource", nil).WithContext(ctx)
+	tenant.StampOutboundRequest(outReq, signatureKey)
+	inReq := httptest.NewRequest(http.MethodGet, "/myresource", nil)
+	inReq.Header = outReq.Header
+	handlerSpy := handlerSpy{}
+
+	tenant.AddToCtx("", signatureKey)(&handlerSpy).ServeHTTP(httptest.NewRecorder(), inReq)
+
+	if handlerSpy.systemBaseUri != "https://sample.example.com" || handlerSpy.tenantId != "a12be5" {
+		t.Errorf("got systemBaseUri '%v' and tenantId '%v', want '%v' and '%v'", handlerSpy.systemBaseUri, handlerSpy.tenantId, "https://sample.example.com", "a12be5")
+	}
+}
+
+func TestTenantOnContext_NewOutboundRequest_ReturnsRequestWithSignedTenantHeadersAndJSONContentType(t *testing.T) {
+	ctx := tenant.SetId(tenant.SetSystemBaseUri(context.Background(), "https://sample.example.com"), "a12be5")
+
+	req, err := tenant.NewOutboundRequest(ctx, http.MethodPost, "https://other.example.com/app/webhook", strings.NewReader(`{"type":"created"}`), signatureKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Method != http.MethodPost || req.URL.String() != "https://other.example.com/app/webhook" {
+		t.Errorf("got request '%v %v', want '%v %v'", req.Method, req.URL, http.MethodPost, "https://other.example.com/app/webhook")
+	}
+	if ct := req.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("got Content-Type '%v', want '%v'", ct, "application/json")
+	}
+	if sbu := req.Header.Get(systemBaseUriHeader); sbu != "https://sample.example.com" {
+		t.Errorf("got %v header '%v', want '%v'", systemBaseUriHeader, sbu, "https://sample.example.com")
+	}
+	if tId := req.Header.Get(tenantIdHeader); tId != "a12be5" {
+		t.Errorf("got %v header '%v', want '%v'", tenantIdHeader, tId, "a12be5")
+	}
+	if sig, want := req.Header.Get(signatureHeader), base64Signature("https://sample.example.com"+"a12be5", signatureKey); sig != want {
+		t.Errorf("got %v header '%v', want '%v'", signatureHeader, sig, want)
+	}
+	if req.Context() != ctx {
+		t.Error("request should have the given context")
+	}
+}
+
+func TestOutboundRequest_AddToCtx_AddsTenantToContext(t *testing.T) {
+	ctx := tenant.SetId(tenant.SetSystemBaseUri(context.Background(), "https://sample.example.com"), "a12be5")
+	outReq, err := tenant.NewOutboundRequest(ctx, http.MethodPost, "https://other.example.com/app/webhook", nil, signatureKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inReq := httptest.NewRequest(http.MethodPost, "/app/webhook", nil)
+	inReq.Header = outReq.Header
+	handlerSpy := handlerSpy{}
+
+	tenant.AddToCtx("", signatureKey)(&handlerSpy).ServeHTTP(httptest.NewRecorder(), inReq)
+
+	if handlerSpy.systemBaseUri != "https://sample.example.com" || handlerSpy.tenantId != "a12be5" {
+		t.Errorf("got systemBaseUri '%v' and tenantId '%v', want '%v' and '%v'", handlerSpy.systemBaseUri, handlerSpy.tenantId, "https://sample.example.com", "a12be5")
+	}
+}
+
+func TestInvalidURL_NewOutboundRequest_ReturnsError(t *testing.T) {
+	_, err := tenant.NewOutboundRequest(context.Background(), http.MethodPost, "://invalid", nil, signatureKey)
+
+	if err == nil {
+		t.Error("expected error for invalid url")
+	}
+}
@@ -0,0 +1,48 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ServiceURL returns the absolute url of appPath for the systemBaseUri on the context.
+//
+// appPath is appended to the path of the systemBaseUri. Redundant slashes between
+// the systemBaseUri and appPath are removed, e.g. the systemBaseUri "https://acme.d-velop.cloud/"
+// and appPath "/myapp/resource" yield "https://acme.d-velop.cloud/myapp/resource".
+func ServiceURL(ctx context.Context, appPath string) (string, error) {
+	systemBaseUri, err := SystemBaseUriFromCtx(ctx)
+	if err != nil {
+		return "", err
+	}
+	base, err := url.Parse(systemBaseUri)
+	if err != nil {
+		return "", fmt.Errorf("systemBaseUri '%v' is no valid url because: %v", systemBaseUri, err)
+	}
+	if base.Scheme == "" || base.Host == "" {
+		return "", fmt.Errorf("systemBaseUri '%v' is no absolute url", systemBaseUri)
+	}
+	// leading slashes are trimmed before parsing because "//myapp" would be parsed as host
+	ref, err := url.Parse(strings.TrimLeft(appPath, "/"))
+	if err != nil {
+		return "", fmt.Errorf("appPath '%v' is no valid url path because: %v", appPath, err)
+	}
+	base.Path = strings.TrimRight(base.Path, "/") + "/" + ref.Path
+	base.RawPath = ""
+	base.RawQuery = ref.RawQuery
+	base.Fragment = ref.Fragment
+	return base.String(), nil
+}
+
+// MustServiceURL is like ServiceURL but panics if the url can't be constructed.
+//
+// MustServiceURL is meant to be used in tests only. Use ServiceURL in production code.
+func MustServiceURL(ctx context.Context, appPath string) string {
+	u, err := ServiceURL(ctx, appPath)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
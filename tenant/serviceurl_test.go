@@ -0,0 +1,67 @@
+package tenant_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/d-velop/dvelop-sdk-go/tenant"
+)
+
+func TestSystemBaseUriOnContext_ServiceURL_ReturnsAbsoluteUrl(t *testing.T) {
+	testCases := []struct {
+		systemBaseUri string
+		appPath       string
+		expected      string
+	}{
+		{"https://acme.d-velop.cloud", "/myapp/resource", "https://acme.d-velop.cloud/myapp/resource"},
+		{"https://acme.d-velop.cloud/", "/myapp/resource", "https://acme.d-velop.cloud/myapp/resource"},
+		{"https://acme.d-velop.cloud//", "//myapp/resource", "https://acme.d-velop.cloud/myapp/resource"},
+		{"https://acme.d-velop.cloud", "myapp/resource", "https://acme.d-velop.cloud/myapp/resource"},
+		{"https://acme.d-velop.cloud/base/", "/myapp/resource?query=1", "https://acme.d-velop.cloud/base/myapp/resource?query=1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.systemBaseUri+" "+tc.appPath, func(t *testing.T) {
+			ctx := tenant.SetSystemBaseUri(context.Background(), tc.systemBaseUri)
+
+			u, err := tenant.ServiceURL(ctx, tc.appPath)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if u != tc.expected {
+				t.Errorf("got wrong url: got %v want %v", u, tc.expected)
+			}
+		})
+	}
+}
+
+func TestNoSystemBaseUriOnContext_ServiceURL_ReturnsError(t *testing.T) {
+	_, err := tenant.ServiceURL(context.Background(), "/myapp/resource")
+
+	if err == nil {
+		t.Error("expected error because there is no systemBaseUri on context")
+	}
+}
+
+func TestMalformedSystemBaseUriOnContext_ServiceURL_ReturnsError(t *testing.T) {
+	for _, systemBaseUri := range []string{"://acme.d-velop.cloud", "acme.d-velop.cloud"} {
+		ctx := tenant.SetSystemBaseUri(context.Background(), systemBaseUri)
+
+		_, err := tenant.ServiceURL(ctx, "/myapp/resource")
+
+		if err == nil {
+			t.Errorf("expected error because systemBaseUri '%v' is malformed", systemBaseUri)
+		}
+	}
+}
+
+func TestNoSystemBaseUriOnContext_MustServiceURL_Panics(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected panic because there is no systemBaseUri on context")
+		}
+	}()
+
+	tenant.MustServiceURL(context.Background(), "/myapp/resource")
+}
@@ -0,0 +1,74 @@
+package tenant
+
+import (
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+// signatureCache caches the results of signature validations for a short time
+// to avoid the HMAC computation for repeated requests with identical tenant headers.
+type signatureCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[[sha256.Size]byte]signatureCacheEntry
+}
+
+type signatureCacheEntry struct {
+	valid   bool
+	expires time.Time
+}
+
+func newSignatureCache(size int, ttl time.Duration) *signatureCache {
+	return &signatureCache{
+		size:    size,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[[sha256.Size]byte]signatureCacheEntry, size),
+	}
+}
+
+// isValid returns the cached validation result for data or calls validate if there is no valid cache entry.
+// A nil cache always calls validate.
+func (c *signatureCache) isValid(data string, validate func() bool) bool {
+	if c == nil {
+		return validate()
+	}
+	key := sha256.Sum256([]byte(data))
+	now := c.now()
+
+	c.mu.Lock()
+	e, found := c.entries[key]
+	c.mu.Unlock()
+	if found && now.Before(e.expires) {
+		return e.valid
+	}
+
+	valid := validate()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= c.size {
+		c.evict(now)
+	}
+	c.entries[key] = signatureCacheEntry{valid: valid, expires: now.Add(c.ttl)}
+	return valid
+}
+
+// evict removes all expired entries. If the cache is still full an arbitrary entry is removed.
+func (c *signatureCache) evict(now time.Time) {
+	for k, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	if len(c.entries) < c.size {
+		return
+	}
+	for k := range c.entries {
+		delete(c.entries, k)
+		return
+	}
+}
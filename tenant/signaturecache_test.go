@@ -0,0 +1,68 @@
+package tenant
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIdenticalData_isValid_CallsValidateOnlyOnce(t *testing.T) {
+	c := newSignatureCache(10, time.Minute)
+	calls := 0
+	validate := func() bool {
+		calls++
+		return true
+	}
+
+	first := c.isValid("https://sample.example.comabc", validate)
+	second := c.isValid("https://sample.example.comabc", validate)
+
+	if !first || !second {
+		t.Errorf("expected cached result true but got %v and %v", first, second)
+	}
+	if calls != 1 {
+		t.Errorf("expected validate to be called once but was called %v times", calls)
+	}
+}
+
+func TestExpiredEntry_isValid_CallsValidateAgain(t *testing.T) {
+	c := newSignatureCache(10, time.Second)
+	now := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	calls := 0
+	validate := func() bool {
+		calls++
+		return true
+	}
+
+	c.isValid("https://sample.example.comabc", validate)
+	now = now.Add(2 * time.Second)
+	c.isValid("https://sample.example.comabc", validate)
+
+	if calls != 2 {
+		t.Errorf("expected validate to be called twice but was called %v times", calls)
+	}
+}
+
+func TestFullCache_isValid_DoesNotGrowBeyondSize(t *testing.T) {
+	c := newSignatureCache(2, time.Minute)
+
+	for _, d := range []string{"a", "b", "c", "d"} {
+		c.isValid(d, func() bool { return true })
+	}
+
+	if len(c.entries) > 2 {
+		t.Errorf("expected at most 2 entries but got %v", len(c.entries))
+	}
+}
+
+func TestNilCache_isValid_CallsValidate(t *testing.T) {
+	var c *signatureCache
+	calls := 0
+
+	c.isValid("a", func() bool { calls++; return false })
+	c.isValid("a", func() bool { calls++; return false })
+
+	if calls != 2 {
+		t.Errorf("expected validate to be called twice but was called %v times", calls)
+	}
+}
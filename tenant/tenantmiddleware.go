@@ -33,10 +33,9 @@ package tenant
 
 import (
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
 	"encoding/base64"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"strings"
@@ -50,18 +49,41 @@ const (
 	initiatorSystemBaseUriCtxKey = contextKey("sourceSystemBaseUri")
 	systemBaseUriHeader          = "x-dv-baseuri"
 	tenantIdHeader               = "x-dv-tenant-id"
+	signatureHeader              = "x-dv-sig-1"
 	forwardedHeader              = "forwarded"
 	xForwardedHostHeader         = "x-forwarded-host"
+	xForwardedProtoHeader        = "x-forwarded-proto"
 	commaDelimiter               = ","
 	colonDelimiter               = ";"
 	forwardedHostPattern         = "host="
+	forwardedProtoPattern        = "proto="
 	uriPrefix                    = "https://"
 )
 
 // Adds systemBaseUri and tenantId to request context.
 // If the headers are not present the given defaultSystemBaseUri and tenant "0" are used.
 // The signatureSecretKey is specific for each App and is provided by the registration process for d.velop cloud.
+//
+// Errors are logged with the standard logger. Use AddToCtxWithLogger to provide custom log functions and options.
+// For backward compatibility AddToCtx doesn't validate the systemBaseUri and ignores the x-dv-sig-algorithm header,
+// so the tenant headers must be signed with HMAC-SHA256. Use AddToCtxWithLogger or AddToCtxWithAlgorithm
+// to validate them.
 func AddToCtx(defaultSystemBaseUri string, signatureSecretKey []byte) func(http.Handler) http.Handler {
+	logPrintf := func(ctx context.Context, logmessage string) {
+		log.Print(logmessage)
+	}
+	return AddToCtxWithLogger(defaultSystemBaseUri, signatureSecretKey, logPrintf, logPrintf, legacy())
+}
+
+// AddToCtxWithLogger adds systemBaseUri and tenantId to request context like AddToCtx.
+//
+// logError is used to log errors which are caused by a misconfiguration of the App and logInfo is used to log
+// requests which are rejected because of invalid tenant headers.
+//
+// The systemBaseUri must be an absolute https url. Requests with an invalid x-dv-baseuri header are rejected
+// with 400 - Bad Request. If the defaultSystemBaseUri is invalid requests are rejected with 500 - Internal Server Error.
+func AddToCtxWithLogger(defaultSystemBaseUri string, signatureSecretKey []byte, logError, logInfo func(ctx context.Context, logmessage string), options ...Option) func(http.Handler) http.Handler {
+	c := newConfig(options)
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 			ctx := req.Context()
@@ -71,19 +93,33 @@ func AddToCtx(defaultSystemBaseUri string, signatureSecretKey []byte) func(http.
 
 			if systemBaseUri != "" || tenantId != "" {
 				if signatureSecretKey == nil {
-					log.Printf("error validating signature for headers '%v' and '%v' because secret signature key has not been configured", systemBaseUriHeader, tenantIdHeader)
+					logError(ctx, fmt.Sprintf("error validating signature for headers '%v' and '%v' because secret signature key has not been configured", systemBaseUriHeader, tenantIdHeader))
 					http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 					return
 				}
-				base64Signature := req.Header.Get("x-dv-sig-1")
+				base64Signature := req.Header.Get(signatureHeader)
+				if base64Signature == "" {
+					logInfo(ctx, fmt.Sprintf("error signature header '%v' is missing although header '%v' or '%v' is present", signatureHeader, systemBaseUriHeader, tenantIdHeader))
+					http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+					return
+				}
 				signature, err := base64.StdEncoding.DecodeString(base64Signature)
 				if err != nil {
-					log.Printf("error decoding signature '%v' as base 64 data because: %v", base64Signature, err)
+					logInfo(ctx, fmt.Sprintf("error decoding signature '%v' as base 64 data because: %v", base64Signature, err))
+					http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+					return
+				}
+				algorithm, err := c.algorithmOf(req)
+				if err != nil {
+					logInfo(ctx, fmt.Sprintf("error header '%v' is invalid because: %v", signatureAlgorithmHeader, err))
 					http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 					return
 				}
-				if !signatureIsValid([]byte(systemBaseUri+tenantId), []byte(signature), signatureSecretKey) {
-					log.Printf("error signature '%v' is not valid for SystemBaseUri '%v' and TenantId '%v'", signature, systemBaseUri, tenantId)
+				valid := c.signatureCache.isValid(algorithm.String()+systemBaseUri+tenantId+base64Signature, func() bool {
+					return signatureIsValid([]byte(systemBaseUri+tenantId), signature, signatureSecretKey, algorithm)
+				})
+				if !valid {
+					logInfo(ctx, fmt.Sprintf("error signature '%v' is not valid for SystemBaseUri '%v' and TenantId '%v'", signature, systemBaseUri, tenantId))
 					http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 					return
 				}
@@ -101,17 +137,31 @@ func AddToCtx(defaultSystemBaseUri string, signatureSecretKey []byte) func(http.
 			}
 
 			if systemBaseUri == "" {
+				if err := c.validateSystemBaseUri(defaultSystemBaseUri); err != nil {
+					logError(ctx, fmt.Sprintf("error default SystemBaseUri is misconfigured because: %v", err))
+					http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+					return
+				}
 				systemBaseUri = defaultSystemBaseUri
+			} else if err := c.validateSystemBaseUri(systemBaseUri); err != nil {
+				logInfo(ctx, fmt.Sprintf("error header '%v' is invalid because: %v", systemBaseUriHeader, err))
+				http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+				return
 			}
 			if systemBaseUri != "" {
 				ctx = context.WithValue(ctx, systemBaseUriCtxKey, systemBaseUri)
 			}
 
-			initiatorSystemBaseUri := getInitiatorSystemBaseUri(req)
+			initiatorSystemBaseUri, forwardedProto := getInitiatorSystemBaseUri(req)
 			if initiatorSystemBaseUri == "" {
 				initiatorSystemBaseUri = defaultSystemBaseUri
 			}
 			if initiatorSystemBaseUri != "" {
+				if forwardedProto != "" && !strings.EqualFold(forwardedProto, "https") {
+					logInfo(ctx, fmt.Sprintf("warning initiator SystemBaseUri '%v' has been forwarded with the protocol '%v' instead of https", initiatorSystemBaseUri, forwardedProto))
+				} else if !strings.HasPrefix(initiatorSystemBaseUri, uriPrefix) {
+					logInfo(ctx, fmt.Sprintf("warning initiator SystemBaseUri '%v' doesn't use the scheme https", initiatorSystemBaseUri))
+				}
 				ctx = context.WithValue(ctx, initiatorSystemBaseUriCtxKey, initiatorSystemBaseUri)
 			}
 			next.ServeHTTP(rw, req.WithContext(ctx))
@@ -119,41 +169,34 @@ func AddToCtx(defaultSystemBaseUri string, signatureSecretKey []byte) func(http.
 	}
 }
 
-func signatureIsValid(message, signature, key []byte) bool {
-	mac := hmac.New(sha256.New, key)
-	mac.Write(message)
-	expectedMAC := mac.Sum(nil)
-	return hmac.Equal(signature, expectedMAC)
-}
-
 // returns the initial host which initiates current request
 // it is essential in hybrid systems
-func getInitiatorSystemBaseUri(req *http.Request) string {
-	var initiatorSystemBaseUri string
+//
+// If the host has been forwarded the protocol which has been forwarded along with it is returned as well.
+// The uri of a forwarded host always uses the scheme https, so only the forwarded protocol reveals
+// whether the initial request used https.
+func getInitiatorSystemBaseUri(req *http.Request) (initiatorSystemBaseUri string, forwardedProto string) {
 	forwardedHeaderValue := req.Header.Get(forwardedHeader)
 	xForwardedHostHeaderValue := req.Header.Get(xForwardedHostHeader)
 	systemBaseUri := req.Header.Get(systemBaseUriHeader)
 
-	initiatorSystemBaseUri = getForwardedHeaderFirstHostValueAsUri(forwardedHeaderValue)
-	if initiatorSystemBaseUri == "" {
-		host := getFirstValueOfDelimitedList(xForwardedHostHeaderValue, commaDelimiter)
-		if host != "" {
-			initiatorSystemBaseUri = uriPrefix + host
-		} else {
-			initiatorSystemBaseUri = systemBaseUri
-		}
+	if host := getForwardedHeaderFirstValue(forwardedHeaderValue, forwardedHostPattern); host != "" {
+		return uriPrefix + host, getForwardedHeaderFirstValue(forwardedHeaderValue, forwardedProtoPattern)
+	}
+	if host := getFirstValueOfDelimitedList(xForwardedHostHeaderValue, commaDelimiter); host != "" {
+		return uriPrefix + host, strings.TrimSpace(getFirstValueOfDelimitedList(req.Header.Get(xForwardedProtoHeader), commaDelimiter))
 	}
-	return initiatorSystemBaseUri
+	return systemBaseUri, ""
 }
 
-func getForwardedHeaderFirstHostValueAsUri(headerValue string) string {
+func getForwardedHeaderFirstValue(headerValue string, pattern string) string {
 	if headerValue != "" {
 		for _, value := range strings.Split(headerValue, colonDelimiter) {
-			if strings.HasPrefix(value, forwardedHostPattern) {
-				hostValue := strings.TrimPrefix(value, forwardedHostPattern)
-				host := getFirstValueOfDelimitedList(hostValue, commaDelimiter)
-				if host != "" {
-					return uriPrefix + host
+			value = strings.TrimSpace(value)
+			if strings.HasPrefix(value, pattern) {
+				directiveValue := strings.TrimPrefix(value, pattern)
+				if v := getFirstValueOfDelimitedList(directiveValue, commaDelimiter); v != "" {
+					return v
 				}
 			}
 		}
@@ -8,7 +8,9 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/d-velop/dvelop-sdk-go/tenant"
 )
@@ -480,6 +482,65 @@ func TestHeadersWithoutSignature_Returns403(t *testing.T) {
 	}
 }
 
+func TestSingleHeaderWithoutSignature_AddToCtxWithLogger_ReturnsStatus403(t *testing.T) {
+	testcases := map[string]string{
+		"OnlyBaseUriHeader":  systemBaseUriHeader,
+		"OnlyTenantIdHeader": tenantIdHeader,
+	}
+	for name, header := range testcases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/myresource/sub", nil)
+			req.Header.Set(header, "https://sample.example.com")
+			var infoMessages []string
+			handlerSpy := handlerSpy{}
+			responseSpy := responseSpy{httptest.NewRecorder()}
+
+			tenant.AddToCtxWithLogger(defaultSystemBaseUri, signatureKey, nullLog, func(ctx context.Context, logmessage string) {
+				infoMessages = append(infoMessages, logmessage)
+			})(&handlerSpy).ServeHTTP(responseSpy, req)
+
+			if err := responseSpy.assertStatusCodeIs(http.StatusForbidden); err != nil {
+				t.Error(err)
+			}
+			if handlerSpy.hasBeenCalled {
+				t.Error("inner handler should not have been called")
+			}
+			if len(infoMessages) != 1 {
+				t.Errorf("expected one info message but got %v", infoMessages)
+			}
+		})
+	}
+}
+
+func TestSingleHeaderWithValidSignature_AddToCtxWithLogger_CallsHandler(t *testing.T) {
+	testcases := map[string]struct {
+		header        string
+		value         string
+		wantSystemUri string
+		wantTenantId  string
+	}{
+		"OnlyBaseUriHeader":  {header: systemBaseUriHeader, value: "https://sample.example.com", wantSystemUri: "https://sample.example.com", wantTenantId: "0"},
+		"OnlyTenantIdHeader": {header: tenantIdHeader, value: "a12be5", wantSystemUri: defaultSystemBaseUri, wantTenantId: "a12be5"},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/myresource/sub", nil)
+			req.Header.Set(tc.header, tc.value)
+			req.Header.Set(signatureHeader, base64Signature(tc.value, signatureKey))
+			handlerSpy := handlerSpy{}
+
+			tenant.AddToCtxWithLogger(defaultSystemBaseUri, signatureKey, nullLog, nullLog)(&handlerSpy).ServeHTTP(httptest.NewRecorder(), req)
+
+			if !handlerSpy.hasBeenCalled {
+				t.Fatal("inner handler should have been called")
+			}
+			if handlerSpy.systemBaseUri != tc.wantSystemUri || handlerSpy.tenantId != tc.wantTenantId {
+				t.Errorf("got systemBaseUri '%v' and tenantId '%v', want '%v' and '%v'", handlerSpy.systemBaseUri, handlerSpy.tenantId, tc.wantSystemUri, tc.wantTenantId)
+			}
+		})
+	}
+}
+
 func TestHeadersAndNoSignatureSecretKey_Returns500(t *testing.T) {
 	req, err := http.NewRequest("GET", "/myresource/sub", nil)
 	if err != nil {
@@ -625,3 +686,235 @@ func (spy *responseSpy) assertStatusCodeIs(expectedStatusCode int) error {
 	}
 	return nil
 }
+
+func TestSignatureCacheAndForgedSignatureForCachedHeaders_AddToCtxWithLogger_ReturnsStatus403(t *testing.T) {
+	const systemBaseUriFromHeader = "https://sample.example.com"
+	const tenantIdFromHeader = "a12be5"
+	middleware := tenant.AddToCtxWithLogger("", signatureKey, nullLog, nullLog, tenant.WithSignatureCacheSize(10), tenant.WithSignatureCacheTTL(time.Minute))
+
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest("GET", "/myresource/sub", nil)
+		req.Header.Set(systemBaseUriHeader, systemBaseUriFromHeader)
+		req.Header.Set(tenantIdHeader, tenantIdFromHeader)
+		req.Header.Set(signatureHeader, base64Signature(systemBaseUriFromHeader+tenantIdFromHeader, signatureKey))
+		responseSpy := responseSpy{httptest.NewRecorder()}
+		middleware(&handlerSpy{}).ServeHTTP(responseSpy, req)
+		if err := responseSpy.assertStatusCodeIs(http.StatusOK); err != nil {
+			t.Error(err)
+		}
+	}
+
+	req, _ := http.NewRequest("GET", "/myresource/sub", nil)
+	req.Header.Set(systemBaseUriHeader, systemBaseUriFromHeader)
+	req.Header.Set(tenantIdHeader, tenantIdFromHeader)
+	req.Header.Set(signatureHeader, base64Signature(systemBaseUriFromHeader+tenantIdFromHeader, []byte("forged")))
+	handlerSpy := handlerSpy{}
+	responseSpy := responseSpy{httptest.NewRecorder()}
+	middleware(&handlerSpy).ServeHTTP(responseSpy, req)
+
+	if err := responseSpy.assertStatusCodeIs(http.StatusForbidden); err != nil {
+		t.Error(err)
+	}
+	if handlerSpy.hasBeenCalled {
+		t.Error("inner handler should not have been called")
+	}
+}
+
+func TestInvalidSignature_AddToCtxWithLogger_LogsWithLogInfo(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/myresource/sub", nil)
+	req.Header.Set(systemBaseUriHeader, "https://sample.example.com")
+	req.Header.Set(signatureHeader, base64Signature("https://other.example.com", signatureKey))
+	var infoMessages, errorMessages []string
+
+	tenant.AddToCtxWithLogger("", signatureKey, func(ctx context.Context, logmessage string) {
+		errorMessages = append(errorMessages, logmessage)
+	}, func(ctx context.Context, logmessage string) {
+		infoMessages = append(infoMessages, logmessage)
+	})(&handlerSpy{}).ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(infoMessages) != 1 || len(errorMessages) != 0 {
+		t.Errorf("expected one info message and no error message but got info %v and error %v", infoMessages, errorMessages)
+	}
+}
+
+func nullLog(ctx context.Context, logmessage string) {}
+
+func TestInvalidBaseUriHeader_AddToCtxWithLogger_ReturnsStatus400(t *testing.T) {
+	for _, systemBaseUriFromHeader := range []string{"javascript:alert(1)", "http://sample.example.com", "https://", "sample.example.com", "ftp://sample.example.com", "https://%zz"} {
+		t.Run(systemBaseUriFromHeader, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/myresource/sub", nil)
+			req.Header.Set(systemBaseUriHeader, systemBaseUriFromHeader)
+			req.Header.Set(signatureHeader, base64Signature(systemBaseUriFromHeader, signatureKey))
+			handlerSpy := handlerSpy{}
+			responseSpy := responseSpy{httptest.NewRecorder()}
+
+			tenant.AddToCtxWithLogger(defaultSystemBaseUri, signatureKey, nullLog, nullLog)(&handlerSpy).ServeHTTP(responseSpy, req)
+
+			if err := responseSpy.assertStatusCodeIs(http.StatusBadRequest); err != nil {
+				t.Error(err)
+			}
+			if handlerSpy.hasBeenCalled {
+				t.Error("inner handler should not have been called")
+			}
+		})
+	}
+}
+
+func TestNonAbsoluteBaseUriHeader_AddToCtx_UsesHeader(t *testing.T) {
+	for _, systemBaseUriFromHeader := range []string{"http://sample.example.com", "sample.example.com"} {
+		t.Run(systemBaseUriFromHeader, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/myresource/sub", nil)
+			req.Header.Set(systemBaseUriHeader, systemBaseUriFromHeader)
+			req.Header.Set(signatureHeader, base64Signature(systemBaseUriFromHeader, signatureKey))
+			handlerSpy := handlerSpy{}
+			responseSpy := responseSpy{httptest.NewRecorder()}
+
+			tenant.AddToCtx(defaultSystemBaseUri, signatureKey)(&handlerSpy).ServeHTTP(responseSpy, req)
+
+			if err := responseSpy.assertStatusCodeIs(http.StatusOK); err != nil {
+				t.Error(err)
+			}
+			if err := handlerSpy.assertBaseUriIs(systemBaseUriFromHeader); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestInvalidDefaultBaseUri_AddToCtxWithLogger_ReturnsStatus500(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/myresource/sub", nil)
+	handlerSpy := handlerSpy{}
+	responseSpy := responseSpy{httptest.NewRecorder()}
+
+	tenant.AddToCtxWithLogger("http://default.example.com", signatureKey, nullLog, nullLog)(&handlerSpy).ServeHTTP(responseSpy, req)
+
+	if err := responseSpy.assertStatusCodeIs(http.StatusInternalServerError); err != nil {
+		t.Error(err)
+	}
+	if handlerSpy.hasBeenCalled {
+		t.Error("inner handler should not have been called")
+	}
+}
+
+func TestHttpBaseUriHeaderAndAllowHTTP_AddToCtxWithLogger_UsesHeader(t *testing.T) {
+	const systemBaseUriFromHeader = "http://sample.example.com"
+	req, _ := http.NewRequest("GET", "/myresource/sub", nil)
+	req.Header.Set(systemBaseUriHeader, systemBaseUriFromHeader)
+	req.Header.Set(signatureHeader, base64Signature(systemBaseUriFromHeader, signatureKey))
+	handlerSpy := handlerSpy{}
+	responseSpy := responseSpy{httptest.NewRecorder()}
+
+	tenant.AddToCtxWithLogger(defaultSystemBaseUri, signatureKey, nullLog, nullLog, tenant.AllowHTTP())(&handlerSpy).ServeHTTP(responseSpy, req)
+
+	if err := responseSpy.assertStatusCodeIs(http.StatusOK); err != nil {
+		t.Error(err)
+	}
+	if err := handlerSpy.assertBaseUriIs(systemBaseUriFromHeader); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestForwardedHeaders_AddToCtxWithLogger_AddsInitiatorSystemBaseUriToCtx(t *testing.T) {
+	testCases := []struct {
+		name                   string
+		forwarded              string
+		xForwardedHost         string
+		initiatorSystemBaseUri string
+	}{
+		{"single host directive", "host=forwarded.example.com", "", "https://forwarded.example.com"},
+		{"multiple directives", "for=192.0.2.60;proto=https;host=forwarded.example.com;by=203.0.113.43", "", "https://forwarded.example.com"},
+		{"multiple directives with whitespace", "for=192.0.2.60; host=forwarded.example.com", "", "https://forwarded.example.com"},
+		{"multiple hops", "for=192.0.2.60;host=forwarded.example.com, for=198.51.100.17;host=proxy.example.com", "", "https://forwarded.example.com"},
+		{"no host directive", "for=192.0.2.60;proto=https", "", defaultSystemBaseUri},
+		{"x-forwarded-host fallback", "", "xforwarded.example.com", "https://xforwarded.example.com"},
+		{"x-forwarded-host fallback if forwarded has no host", "for=192.0.2.60", "xforwarded.example.com", "https://xforwarded.example.com"},
+		{"forwarded wins over x-forwarded-host", "host=forwarded.example.com", "xforwarded.example.com", "https://forwarded.example.com"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/myresource/sub", nil)
+			if tc.forwarded != "" {
+				req.Header.Set(forwardedHeader, tc.forwarded)
+			}
+			if tc.xForwardedHost != "" {
+				req.Header.Set(xForwardedHostHeader, tc.xForwardedHost)
+			}
+			handlerSpy := handlerSpy{}
+			responseSpy := responseSpy{httptest.NewRecorder()}
+
+			tenant.AddToCtxWithLogger(defaultSystemBaseUri, signatureKey, nullLog, nullLog)(&handlerSpy).ServeHTTP(responseSpy, req)
+
+			if err := responseSpy.assertStatusCodeIs(http.StatusOK); err != nil {
+				t.Error(err)
+			}
+			if err := handlerSpy.assertInitiatorSystemBaseUriIs(tc.initiatorSystemBaseUri); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestHttpInitiatorSystemBaseUri_AddToCtxWithLogger_LogsWarning(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/myresource/sub", nil)
+	handlerSpy := handlerSpy{}
+	responseSpy := responseSpy{httptest.NewRecorder()}
+	var loggedMessages []string
+	logInfo := func(ctx context.Context, logmessage string) {
+		loggedMessages = append(loggedMessages, logmessage)
+	}
+
+	tenant.AddToCtxWithLogger("http://default.example.com", signatureKey, nullLog, logInfo, tenant.AllowHTTP())(&handlerSpy).ServeHTTP(responseSpy, req)
+
+	if err := responseSpy.assertStatusCodeIs(http.StatusOK); err != nil {
+		t.Error(err)
+	}
+	if err := handlerSpy.assertInitiatorSystemBaseUriIs("http://default.example.com"); err != nil {
+		t.Error(err)
+	}
+	if len(loggedMessages) != 1 || !strings.Contains(loggedMessages[0], "http://default.example.com") {
+		t.Errorf("expected a warning about the initiator SystemBaseUri but got %v", loggedMessages)
+	}
+}
+
+func TestInitiatorSystemBaseUriForwardedWithHttp_AddToCtxWithLogger_LogsWarning(t *testing.T) {
+	testCases := []struct {
+		name    string
+		headers map[string]string
+	}{
+		{"forwarded", map[string]string{forwardedHeader: "for=192.0.2.60;proto=http;host=forwarded.example.com"}},
+		{"x-forwarded-host", map[string]string{xForwardedHostHeader: "xforwarded.example.com", "x-forwarded-proto": "http"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/myresource/sub", nil)
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			var loggedMessages []string
+			logInfo := func(ctx context.Context, logmessage string) {
+				loggedMessages = append(loggedMessages, logmessage)
+			}
+
+			tenant.AddToCtxWithLogger(defaultSystemBaseUri, signatureKey, nullLog, logInfo)(&handlerSpy{}).ServeHTTP(responseSpy{httptest.NewRecorder()}, req)
+
+			if len(loggedMessages) != 1 || !strings.Contains(loggedMessages[0], "'http'") {
+				t.Errorf("expected a warning about the forwarded protocol but got %v", loggedMessages)
+			}
+		})
+	}
+}
+
+func TestHttpsInitiatorSystemBaseUri_AddToCtxWithLogger_LogsNothing(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/myresource/sub", nil)
+	req.Header.Set(forwardedHeader, "proto=https;host=forwarded.example.com")
+	var loggedMessages []string
+	logInfo := func(ctx context.Context, logmessage string) {
+		loggedMessages = append(loggedMessages, logmessage)
+	}
+
+	tenant.AddToCtxWithLogger(defaultSystemBaseUri, signatureKey, nullLog, logInfo)(&handlerSpy{}).ServeHTTP(responseSpy{httptest.NewRecorder()}, req)
+
+	if len(loggedMessages) != 0 {
+		t.Errorf("expected no log messages but got %v", loggedMessages)
+	}
+}